@@ -94,6 +94,9 @@ func (t *TemplateCreator) CopyTemplate() (*v1.Template, error) {
 	}
 
 	updatedTemplate := t.UpdateTemplateMetadata(template)
+	if err := t.cliOptions.Options.Apply(updatedTemplate); err != nil {
+		return nil, err
+	}
 
 	log.Logger().Debug("Updated template metadata", zap.Any("ObjectMeta", updatedTemplate.ObjectMeta))
 	existingTemplate, err := t.templateProvider.Get(t.cliOptions.GetTargetTemplateNamespace(), t.cliOptions.GetTargetTemplateName())
@@ -5,6 +5,7 @@ import (
 
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/env"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/output"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/ownerref"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zerrors"
 	"go.uber.org/zap/zapcore"
 )
@@ -24,6 +25,7 @@ type CLIOptions struct {
 	AllowReplace            string            `arg:"--allow-replace,env:ALLOW_REPLACE" placeholder:"false" help:"Allow replacing already existing template (same combination name/namespace). Allowed values true/false"`
 	Output                  output.OutputType `arg:"-o" placeholder:"FORMAT" help:"Output format. One of: yaml|json"`
 	Debug                   bool              `arg:"--debug" help:"Sets DEBUG log level"`
+	ownerref.Options
 }
 
 func (c *CLIOptions) GetDebugLevel() zapcore.Level {
@@ -66,6 +68,10 @@ func (c *CLIOptions) Init() error {
 
 	c.setValues()
 
+	if err := c.Options.Init(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/copy-template/pkg/utils/parse"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/ownerref"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"go.uber.org/zap/zapcore"
@@ -33,6 +34,14 @@ var _ = Describe("CLIOptions", func() {
 					TargetTemplateNamespace: testStringTargetNamespace,
 					Output:                  "non-existing",
 				}),
+			Entry("invalid ttl", "could not parse ttl",
+				&parse.CLIOptions{
+					SourceTemplateName:      testStringSourceName,
+					SourceTemplateNamespace: testStringSourceNamespace,
+					TargetTemplateName:      testStringTargetName,
+					TargetTemplateNamespace: testStringTargetNamespace,
+					Options:                 ownerref.Options{TTL: "1 day"},
+				}),
 		)
 	})
 	Context("correct cli options", func() {
@@ -1,12 +1,15 @@
 package main
 
 import (
+	"encoding/json"
 	"net/http"
 
 	goarg "github.com/alexflint/go-arg"
 	. "github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/constants"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/service"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/utils/parse"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/vmcreator"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/vmi"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/exit"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/log"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/output"
@@ -35,30 +38,103 @@ func main() {
 		exit.ExitOrDieFromError(GenericExitCode, err)
 	}
 
-	vm, err := vmCreator.CreateVM()
+	vms, err := vmCreator.CreateVMs()
 	if err != nil {
-		exit.ExitOrDieFromError(CreateVMErrorExitCode, err,
+		exit.ExitOrDieFromError(CreateVMErrorExitCode, vmCreator.Rollback(err),
 			zerrors.IsStatusError(err, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity),
 		)
 	}
 
-	if cliOptions.GetStartVMFlag() &&
-		(vm.Spec.RunStrategy == nil || *vm.Spec.RunStrategy != kubevirtv1.RunStrategyAlways) &&
-		(vm.Spec.Running == nil || !*vm.Spec.Running) {
-		if err := vmCreator.StartVM(vm.Namespace, vm.Name); err != nil {
-			exit.ExitFromError(StartVMErrorExitCode, err)
+	var names []string
+	for _, vm := range vms {
+		if !cliOptions.IsDryRun() && cliOptions.GetStartVMFlag() &&
+			(vm.Spec.RunStrategy == nil || *vm.Spec.RunStrategy != kubevirtv1.RunStrategyAlways) &&
+			(vm.Spec.Running == nil || !*vm.Spec.Running) {
+			if err := vmCreator.StartVM(vm.Namespace, vm.Name); err != nil {
+				exit.ExitFromError(StartVMErrorExitCode, vmCreator.Rollback(err))
+			}
 		}
+		names = append(names, vm.Name)
 	}
+	vm := vms[0]
 
+	objects, err := json.Marshal(vmCreator.GetObjectResults(vms))
+	if err != nil {
+		exit.ExitOrDieFromError(WriteResultsExitCode, vmCreator.Rollback(err))
+	}
+
+	namesResult, err := json.Marshal(names)
+	if err != nil {
+		exit.ExitOrDieFromError(WriteResultsExitCode, vmCreator.Rollback(err))
+	}
+
+	// name, namespace, service and VMI results are of the first VM, vms result contains the name and the IP address of every VM
 	results := map[string]string{
 		NameResultName:      vm.Name,
 		NamespaceResultName: vm.Namespace,
+		NamesResultName:     string(namesResult),
+		ObjectsResultName:   string(objects),
 	}
 
+	if createdService := vmCreator.GetService(vm.Name); createdService != nil {
+		servicePorts, err := json.Marshal(service.GetPortResults(createdService))
+		if err != nil {
+			exit.ExitOrDieFromError(WriteResultsExitCode, vmCreator.Rollback(err))
+		}
+		results[ServiceNameResultName] = createdService.Name
+		results[ServicePortsResultName] = string(servicePorts)
+	}
+
+	if cliOptions.GetWaitForDataVolumesFlag() {
+		log.Logger().Debug("waiting for data volumes to succeed", zap.Strings("vms", names))
+		phases, err := vmCreator.WaitForDataVolumes(vms)
+		if err != nil {
+			exit.ExitOrDieFromError(WaitForDVsErrorExitCode, vmCreator.Rollback(err))
+		}
+
+		dataVolumes, err := json.Marshal(phases)
+		if err != nil {
+			exit.ExitOrDieFromError(WriteResultsExitCode, vmCreator.Rollback(err))
+		}
+		results[DataVolumesResultName] = string(dataVolumes)
+	}
+
+	var readyVMIs []*kubevirtv1.VirtualMachineInstance
+	if cliOptions.GetWaitForReadyFlag() {
+		log.Logger().Debug("waiting for VMIs to be ready", zap.Strings("names", names), zap.String("namespace", vm.Namespace))
+		readyVMIs, err = vmCreator.WaitForVMIsReady(vms)
+		if err != nil {
+			exit.ExitOrDieFromError(WaitForVMIErrorExitCode, vmCreator.Rollback(err))
+		}
+
+		readyVMI := readyVMIs[0]
+		guestOSInfo, err := vmi.GetGuestOSInfo(readyVMI)
+		if err != nil {
+			exit.ExitOrDieFromError(WaitForVMIErrorExitCode, vmCreator.Rollback(err))
+		}
+
+		results[VMIIPResultName] = vmi.GetIPAddress(readyVMI)
+		results[NodeNameResultName] = readyVMI.Status.NodeName
+		results[GuestOSInfoResultName] = guestOSInfo
+	}
+
+	vmResults, err := json.Marshal(vmCreator.GetVMResults(vms, readyVMIs))
+	if err != nil {
+		exit.ExitOrDieFromError(WriteResultsExitCode, vmCreator.Rollback(err))
+	}
+	results[VMsResultName] = string(vmResults)
+
 	log.Logger().Debug("recording results", zap.Reflect("results", results))
+	if err := vmcreator.ValidateResultsSize(results); err != nil {
+		exit.ExitOrDieFromError(WriteResultsExitCode, vmCreator.Rollback(err))
+	}
 	if err := res.RecordResults(results); err != nil {
-		exit.ExitOrDieFromError(WriteResultsExitCode, err)
+		exit.ExitOrDieFromError(WriteResultsExitCode, vmCreator.Rollback(err))
 	}
 
-	output.PrettyPrint(vm, cliOptions.Output)
+	if len(vms) == 1 {
+		output.PrettyPrint(vm, cliOptions.GetOutput())
+	} else {
+		output.PrettyPrint(vms, cliOptions.GetOutput())
+	}
 }
@@ -0,0 +1,107 @@
+package bundle
+
+import (
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/constants"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zerrors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+// creation order of supported kinds, objects the VM depends on are created before the VM
+var creationOrder = map[schema.GroupKind]int{
+	{Kind: constants.SecretKind}:                               0,
+	{Kind: constants.ConfigMapKind}:                            1,
+	{Group: "cdi.kubevirt.io", Kind: constants.DataVolumeKind}: 2,
+	kubevirtv1.VirtualMachineGroupVersionKind.GroupKind():      3,
+	{Kind: constants.ServiceKind}:                              4,
+}
+
+// Bundle is a VM together with the objects it needs
+type Bundle struct {
+	VM *kubevirtv1.VirtualMachine
+	// Dependencies should be created before the VM
+	Dependencies []*unstructured.Unstructured
+	// Dependents should be created after the VM
+	Dependents []*unstructured.Unstructured
+}
+
+// Parse reads a multi-document YAML or JSON manifest with exactly one VirtualMachine
+// and sorts the remaining objects in the order they should be created in
+func Parse(manifest string) (*Bundle, error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(manifest), 4096)
+
+	var objects []*unstructured.Unstructured
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, zerrors.NewSoftError("could not read VM manifest: %v", err.Error())
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		objects = append(objects, obj)
+	}
+
+	bundle := &Bundle{}
+	vmOrder := creationOrder[kubevirtv1.VirtualMachineGroupVersionKind.GroupKind()]
+
+	for _, obj := range objects {
+		groupKind := obj.GroupVersionKind().GroupKind()
+		order, supported := creationOrder[groupKind]
+		if !supported {
+			return nil, zerrors.NewSoftError("unsupported object %v %v in VM manifest: only Secret, ConfigMap, DataVolume, VirtualMachine and Service objects are supported",
+				groupKind.String(), obj.GetName())
+		}
+		if _, supported := resources[obj.GroupVersionKind()]; !supported && order != vmOrder {
+			return nil, zerrors.NewSoftError("unsupported apiVersion %v of %v %v in VM manifest", obj.GetAPIVersion(), obj.GetKind(), obj.GetName())
+		}
+
+		switch {
+		case order == vmOrder:
+			if bundle.VM != nil {
+				return nil, zerrors.NewSoftError("VM manifest should contain only one VirtualMachine")
+			}
+			vm := &kubevirtv1.VirtualMachine{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, vm); err != nil {
+				return nil, zerrors.NewSoftError("could not read VM manifest: %v", err.Error())
+			}
+			bundle.VM = vm
+		case order < vmOrder:
+			bundle.Dependencies = append(bundle.Dependencies, obj)
+		default:
+			bundle.Dependents = append(bundle.Dependents, obj)
+		}
+	}
+
+	if bundle.VM == nil {
+		return nil, zerrors.NewSoftError("VM manifest should contain a VirtualMachine")
+	}
+
+	sortByCreationOrder(bundle.Dependencies)
+	sortByCreationOrder(bundle.Dependents)
+
+	return bundle, nil
+}
+
+func sortByCreationOrder(objects []*unstructured.Unstructured) {
+	sort.SliceStable(objects, func(i, j int) bool {
+		return creationOrder[objects[i].GroupVersionKind().GroupKind()] < creationOrder[objects[j].GroupVersionKind().GroupKind()]
+	})
+}
+
+// ObjectReference identifies an object created by the task
+type ObjectReference struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
@@ -0,0 +1,108 @@
+package bundle_test
+
+import (
+	"strings"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/bundle"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/sharedtest/testobjects"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	secretManifest = `apiVersion: v1
+kind: Secret
+metadata:
+  name: cloud-init
+stringData:
+  userdata: "#cloud-config"`
+	configMapManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: value`
+	serviceManifest = `apiVersion: v1
+kind: Service
+metadata:
+  name: ssh
+spec:
+  ports:
+    - port: 22`
+	dataVolumeManifest = `apiVersion: cdi.kubevirt.io/v1beta1
+kind: DataVolume
+metadata:
+  name: disk
+spec:
+  source:
+    blank: {}
+  storage:
+    resources:
+      requests:
+        storage: 1Gi`
+	podManifest = `apiVersion: v1
+kind: Pod
+metadata:
+  name: pod`
+)
+
+func documents(manifests ...string) string {
+	return strings.Join(manifests, "\n---\n")
+}
+
+func names(objects []*unstructured.Unstructured) []string {
+	var result []string
+	for _, obj := range objects {
+		result = append(result, obj.GetKind()+"/"+obj.GetName())
+	}
+	return result
+}
+
+var _ = Describe("Bundle", func() {
+	var vm *testobjects.TestVM
+	var vmManifest string
+
+	BeforeEach(func() {
+		vm = testobjects.NewTestVM()
+		vmManifest = strings.TrimSpace(vm.ToString())
+	})
+
+	It("parses a single VM", func() {
+		vmBundle, err := bundle.Parse(vmManifest)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(vmBundle.VM.Name).To(Equal(vm.Build().Name))
+		Expect(vmBundle.Dependencies).To(BeEmpty())
+		Expect(vmBundle.Dependents).To(BeEmpty())
+	})
+
+	It("sorts objects in creation order", func() {
+		vmBundle, err := bundle.Parse(documents(serviceManifest, vmManifest, dataVolumeManifest, configMapManifest, "", secretManifest))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(vmBundle.VM).ToNot(BeNil())
+		Expect(names(vmBundle.Dependencies)).To(Equal([]string{"Secret/cloud-init", "ConfigMap/config", "DataVolume/disk"}))
+		Expect(names(vmBundle.Dependents)).To(Equal([]string{"Service/ssh"}))
+	})
+
+	DescribeTable("fails on invalid bundles", func(manifest func(vmManifest string) string, expectedErrMessage string) {
+		_, err := bundle.Parse(manifest(vmManifest))
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(expectedErrMessage))
+	},
+		Entry("no VM", func(string) string {
+			return documents(secretManifest, serviceManifest)
+		}, "VM manifest should contain a VirtualMachine"),
+		Entry("multiple VMs", func(vmManifest string) string {
+			return documents(vmManifest, vmManifest)
+		}, "VM manifest should contain only one VirtualMachine"),
+		Entry("unsupported kind", func(vmManifest string) string {
+			return documents(vmManifest, podManifest)
+		}, "unsupported object Pod pod in VM manifest"),
+		Entry("unsupported version", func(vmManifest string) string {
+			return documents(vmManifest, strings.Replace(dataVolumeManifest, "v1beta1", "v1alpha1", 1))
+		}, "unsupported apiVersion cdi.kubevirt.io/v1alpha1 of DataVolume disk in VM manifest"),
+		Entry("invalid yaml", func(vmManifest string) string {
+			return documents(vmManifest, "kind: [")
+		}, "could not read VM manifest"),
+	)
+})
@@ -0,0 +1,71 @@
+package bundle
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	cdiv1beta1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+)
+
+// resources of the objects which can be created together with the VM
+var resources = map[schema.GroupVersionKind]string{
+	corev1.SchemeGroupVersion.WithKind(constants.SecretKind):         "secrets",
+	corev1.SchemeGroupVersion.WithKind(constants.ConfigMapKind):      "configmaps",
+	cdiv1beta1.SchemeGroupVersion.WithKind(constants.DataVolumeKind): "datavolumes",
+	corev1.SchemeGroupVersion.WithKind(constants.ServiceKind):        "services",
+}
+
+type objectProvider struct {
+	client dynamic.Interface
+}
+
+type ObjectProvider interface {
+	Create(obj *unstructured.Unstructured) (*unstructured.Unstructured, error)
+	DryRunCreate(obj *unstructured.Unstructured) (*unstructured.Unstructured, error)
+	Delete(obj *unstructured.Unstructured) error
+}
+
+func NewObjectProvider(client dynamic.Interface) ObjectProvider {
+	return &objectProvider{
+		client: client,
+	}
+}
+
+func (o *objectProvider) Create(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	resource, err := o.resource(obj)
+	if err != nil {
+		return nil, err
+	}
+	return resource.Create(context.TODO(), obj, metav1.CreateOptions{})
+}
+
+func (o *objectProvider) DryRunCreate(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	resource, err := o.resource(obj)
+	if err != nil {
+		return nil, err
+	}
+	return resource.Create(context.TODO(), obj, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}})
+}
+
+func (o *objectProvider) Delete(obj *unstructured.Unstructured) error {
+	resource, err := o.resource(obj)
+	if err != nil {
+		return err
+	}
+	return resource.Delete(context.TODO(), obj.GetName(), metav1.DeleteOptions{})
+}
+
+func (o *objectProvider) resource(obj *unstructured.Unstructured) (dynamic.ResourceInterface, error) {
+	gvk := obj.GroupVersionKind()
+	resource, supported := resources[gvk]
+	if !supported {
+		return nil, fmt.Errorf("unsupported object %v", gvk.String())
+	}
+	return o.client.Resource(gvk.GroupVersion().WithResource(resource)).Namespace(obj.GetNamespace()), nil
+}
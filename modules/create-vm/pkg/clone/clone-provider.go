@@ -0,0 +1,37 @@
+package clone
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clonev1alpha1 "kubevirt.io/api/clone/v1alpha1"
+	kubevirtcliv1 "kubevirt.io/client-go/kubecli"
+)
+
+type cloneProvider struct {
+	client kubevirtcliv1.KubevirtClient
+}
+
+type CloneProvider interface {
+	Create(clone *clonev1alpha1.VirtualMachineClone) (*clonev1alpha1.VirtualMachineClone, error)
+	Get(namespace, name string) (*clonev1alpha1.VirtualMachineClone, error)
+	Delete(namespace, name string) error
+}
+
+func NewCloneProvider(client kubevirtcliv1.KubevirtClient) CloneProvider {
+	return &cloneProvider{
+		client: client,
+	}
+}
+
+func (c *cloneProvider) Create(clone *clonev1alpha1.VirtualMachineClone) (*clonev1alpha1.VirtualMachineClone, error) {
+	return c.client.VirtualMachineClone(clone.Namespace).Create(context.TODO(), clone, metav1.CreateOptions{})
+}
+
+func (c *cloneProvider) Get(namespace, name string) (*clonev1alpha1.VirtualMachineClone, error) {
+	return c.client.VirtualMachineClone(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func (c *cloneProvider) Delete(namespace, name string) error {
+	return c.client.VirtualMachineClone(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+}
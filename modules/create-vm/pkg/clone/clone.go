@@ -0,0 +1,109 @@
+package clone
+
+import (
+	"time"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/constants"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/log"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zerrors"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clonev1alpha1 "kubevirt.io/api/clone/v1alpha1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+// NewClone builds a clone of the source VM. KubeVirt generates a name of the new VM if the target name is empty.
+// Labels and annotations of the source VM are copied only if they match the filters.
+func NewClone(namespace, sourceName, targetName string, labelFilters, annotationFilters []string, newMacAddresses map[string]string) *clonev1alpha1.VirtualMachineClone {
+	apiGroup := kubevirtv1.GroupVersion.Group
+
+	clone := &clonev1alpha1.VirtualMachineClone{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: clonev1alpha1.SchemeGroupVersion.String(),
+			Kind:       constants.VirtualMachineCloneKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: sourceName + "-clone-",
+			Namespace:    namespace,
+		},
+		Spec: clonev1alpha1.VirtualMachineCloneSpec{
+			Source: &corev1.TypedLocalObjectReference{
+				APIGroup: &apiGroup,
+				Kind:     constants.VirtualMachineKind,
+				Name:     sourceName,
+			},
+			LabelFilters:      labelFilters,
+			AnnotationFilters: annotationFilters,
+			NewMacAddresses:   newMacAddresses,
+		},
+	}
+
+	if targetName != "" {
+		clone.Spec.Target = &corev1.TypedLocalObjectReference{
+			APIGroup: &apiGroup,
+			Kind:     constants.VirtualMachineKind,
+			Name:     targetName,
+		}
+	}
+
+	return clone
+}
+
+// GetTargetName returns the name of the new VM or an empty string if it is not known yet
+func GetTargetName(clone *clonev1alpha1.VirtualMachineClone) string {
+	if clone.Status.TargetName != nil {
+		return *clone.Status.TargetName
+	}
+	if clone.Spec.Target != nil {
+		return clone.Spec.Target.Name
+	}
+	return ""
+}
+
+// WaitForSuccess waits until the clone succeeds and returns it. Timeout of 0 or less waits indefinitely.
+func WaitForSuccess(provider CloneProvider, namespace, name string, timeout time.Duration) (*clonev1alpha1.VirtualMachineClone, error) {
+	var succeededClone *clonev1alpha1.VirtualMachineClone
+	logFields := []zap.Field{zap.String("name", name), zap.String("namespace", namespace)}
+
+	conditionFn := func() (bool, error) {
+		clone, err := provider.Get(namespace, name)
+		if err != nil {
+			return false, err
+		}
+
+		switch clone.Status.Phase {
+		case clonev1alpha1.Succeeded:
+			succeededClone = clone
+			return true, nil
+		case clonev1alpha1.Failed:
+			return false, zerrors.NewSoftError("clone %v/%v is in %v phase: %v", namespace, name, clone.Status.Phase, getReadyReason(clone))
+		default:
+			log.Logger().Debug("waiting for a clone to succeed", append(logFields, zap.String("phase", string(clone.Status.Phase)))...)
+			return false, nil
+		}
+	}
+
+	var err error
+	if timeout <= 0 {
+		err = wait.PollImmediateInfinite(constants.PollVMIInterval, conditionFn)
+	} else {
+		err = wait.PollImmediate(constants.PollVMIInterval, timeout, conditionFn)
+	}
+
+	if err == wait.ErrWaitTimeout {
+		return nil, zerrors.NewSoftError("timed out waiting for clone %v/%v to succeed", namespace, name)
+	}
+
+	return succeededClone, err
+}
+
+func getReadyReason(clone *clonev1alpha1.VirtualMachineClone) string {
+	for _, condition := range clone.Status.Conditions {
+		if condition.Type == clonev1alpha1.ConditionReady && condition.Reason != "" {
+			return condition.Reason
+		}
+	}
+	return "no reason"
+}
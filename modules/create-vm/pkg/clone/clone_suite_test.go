@@ -0,0 +1,17 @@
+package clone_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/utilstest"
+)
+
+func TestClone(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Clone Suite")
+}
+
+var _ = BeforeSuite(utilstest.SetupTestSuite)
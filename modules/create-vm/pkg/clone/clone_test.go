@@ -0,0 +1,96 @@
+package clone_test
+
+import (
+	"time"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/clone"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+	clonev1alpha1 "kubevirt.io/api/clone/v1alpha1"
+)
+
+type fakeCloneProvider struct {
+	clone *clonev1alpha1.VirtualMachineClone
+}
+
+func (f *fakeCloneProvider) Create(clone *clonev1alpha1.VirtualMachineClone) (*clonev1alpha1.VirtualMachineClone, error) {
+	return clone, nil
+}
+
+func (f *fakeCloneProvider) Get(_, _ string) (*clonev1alpha1.VirtualMachineClone, error) {
+	return f.clone, nil
+}
+
+func (f *fakeCloneProvider) Delete(_, _ string) error {
+	return nil
+}
+
+func newClone(phase clonev1alpha1.VirtualMachineClonePhase, targetName *string, conditions ...clonev1alpha1.Condition) *clonev1alpha1.VirtualMachineClone {
+	return &clonev1alpha1.VirtualMachineClone{
+		Status: clonev1alpha1.VirtualMachineCloneStatus{
+			Phase:      phase,
+			TargetName: targetName,
+			Conditions: conditions,
+		},
+	}
+}
+
+var _ = Describe("Clone", func() {
+	It("builds a clone of the VM", func() {
+		vmClone := clone.NewClone("default", "golden-vm", "my-vm", []string{"*"}, []string{"!example.com/*"}, map[string]string{"default": "02:00:00:00:00:01"})
+		Expect(vmClone.Kind).To(Equal("VirtualMachineClone"))
+		Expect(vmClone.APIVersion).To(Equal("clone.kubevirt.io/v1alpha1"))
+		Expect(vmClone.GenerateName).To(Equal("golden-vm-clone-"))
+		Expect(vmClone.Namespace).To(Equal("default"))
+		Expect(vmClone.Spec).To(Equal(clonev1alpha1.VirtualMachineCloneSpec{
+			Source: &corev1.TypedLocalObjectReference{
+				APIGroup: pointer.String("kubevirt.io"),
+				Kind:     "VirtualMachine",
+				Name:     "golden-vm",
+			},
+			Target: &corev1.TypedLocalObjectReference{
+				APIGroup: pointer.String("kubevirt.io"),
+				Kind:     "VirtualMachine",
+				Name:     "my-vm",
+			},
+			LabelFilters:      []string{"*"},
+			AnnotationFilters: []string{"!example.com/*"},
+			NewMacAddresses:   map[string]string{"default": "02:00:00:00:00:01"},
+		}))
+	})
+
+	It("leaves the target name to KubeVirt", func() {
+		vmClone := clone.NewClone("default", "golden-vm", "", nil, nil, nil)
+		Expect(vmClone.Spec.Target).To(BeNil())
+		Expect(clone.GetTargetName(vmClone)).To(BeEmpty())
+	})
+
+	DescribeTable("GetTargetName", func(vmClone *clonev1alpha1.VirtualMachineClone, expectedName string) {
+		Expect(clone.GetTargetName(vmClone)).To(Equal(expectedName))
+	},
+		Entry("from status", newClone(clonev1alpha1.Succeeded, pointer.String("vm-clone-x7k2p")), "vm-clone-x7k2p"),
+		Entry("from spec", clone.NewClone("default", "golden-vm", "my-vm", nil, nil, nil), "my-vm"),
+	)
+
+	It("returns a succeeded clone", func() {
+		succeededClone := newClone(clonev1alpha1.Succeeded, pointer.String("my-vm"))
+		result, err := clone.WaitForSuccess(&fakeCloneProvider{clone: succeededClone}, "default", "golden-vm-clone", 0)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(result).To(Equal(succeededClone))
+	})
+
+	It("fails when the clone failed", func() {
+		failedClone := newClone(clonev1alpha1.Failed, nil, clonev1alpha1.Condition{Type: clonev1alpha1.ConditionReady, Status: corev1.ConditionFalse, Reason: "source VM not found"})
+		_, err := clone.WaitForSuccess(&fakeCloneProvider{clone: failedClone}, "default", "golden-vm-clone", time.Minute)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(Equal("clone default/golden-vm-clone is in Failed phase: source VM not found"))
+	})
+
+	It("times out when the clone does not succeed", func() {
+		_, err := clone.WaitForSuccess(&fakeCloneProvider{clone: newClone(clonev1alpha1.RestoreInProgress, nil)}, "default", "golden-vm-clone", time.Millisecond)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(Equal("timed out waiting for clone default/golden-vm-clone to succeed"))
+	})
+})
@@ -1,5 +1,7 @@
 package constants
 
+import "time"
+
 // Exit codes
 const (
 	GenericExitCode           = 1
@@ -9,18 +11,73 @@ const (
 	OwnVolumesErrorExitCode   = 5
 	WriteResultsExitCode      = 6
 	StartVMErrorExitCode      = 7
+	WaitForVMIErrorExitCode   = 8
+	WaitForDVsErrorExitCode   = 9
 )
 
 // Result names
 const (
-	NameResultName      = "name"
-	NamespaceResultName = "namespace"
+	NameResultName         = "name"
+	NamespaceResultName    = "namespace"
+	VMIIPResultName        = "vmiIP"
+	NodeNameResultName     = "nodeName"
+	GuestOSInfoResultName  = "guestOSInfo"
+	ObjectsResultName      = "objects"
+	NamesResultName        = "names"
+	VMsResultName          = "vms"
+	ServiceNameResultName  = "serviceName"
+	ServicePortsResultName = "servicePorts"
+	DataVolumesResultName  = "dataVolumes"
 )
 
+const (
+	PollVMIInterval    = 3 * time.Second
+	DefaultWaitTimeout = 3600 * time.Second
+)
+
+const PollDataVolumeInterval = 10 * time.Second
+
+const DefaultParallelism = 5
+
+// MaxResultsSize is the budget of all results. Tekton stores results in the termination message of the step,
+// which is limited to 4096 bytes together with the keys and the JSON encoding of the results.
+const MaxResultsSize = 3072
+
 type CreationMode string
 
 const (
-	TemplateCreationMode   CreationMode = "TemplateCreationMode"
-	VMManifestCreationMode CreationMode = "VMManifestCreationMode"
-	VirtctlCreatingMode    CreationMode = "VirtctlCreatingMode"
+	TemplateCreationMode     CreationMode = "TemplateCreationMode"
+	VMManifestCreationMode   CreationMode = "VMManifestCreationMode"
+	VirtctlCreatingMode      CreationMode = "VirtctlCreatingMode"
+	InstancetypeCreationMode CreationMode = "InstancetypeCreationMode"
+	SnapshotCreationMode     CreationMode = "SnapshotCreationMode"
+	CloneCreationMode        CreationMode = "CloneCreationMode"
+)
+
+type DryRunStrategy string
+
+const (
+	DryRunNone   DryRunStrategy = ""
+	DryRunClient DryRunStrategy = "client"
+	DryRunServer DryRunStrategy = "server"
+)
+
+// Instancetype and preference kinds
+const (
+	VirtualMachineInstancetypeKind        = "VirtualMachineInstancetype"
+	VirtualMachineClusterInstancetypeKind = "VirtualMachineClusterInstancetype"
+	VirtualMachinePreferenceKind          = "VirtualMachinePreference"
+	VirtualMachineClusterPreferenceKind   = "VirtualMachineClusterPreference"
+)
+
+const (
+	VirtualMachineKind        = "VirtualMachine"
+	VirtualMachineRestoreKind = "VirtualMachineRestore"
+	VirtualMachineCloneKind   = "VirtualMachineClone"
+	DataVolumeKind            = "DataVolume"
+	DataSourceKind            = "DataSource"
+	SecretKind                = "Secret"
+	ConfigMapKind             = "ConfigMap"
+	ServiceKind               = "Service"
+	RootDiskName              = "rootdisk"
 )
@@ -10,6 +10,15 @@ const (
 	// TemplateFlavorLabel is a label that specifies the flavor of the template
 	TemplateFlavorLabel = "flavor.template.kubevirt.io"
 
+	// TemplateArchitectureLabel is a label that specifies the architecture of the template
+	TemplateArchitectureLabel = "template.kubevirt.io/architecture"
+
+	// TemplateVersionLabel is a label that specifies the version of the template
+	TemplateVersionLabel = "template.kubevirt.io/version"
+
+	// TemplateDeprecatedAnnotation is an annotation that marks deprecated templates
+	TemplateDeprecatedAnnotation = "template.kubevirt.io/deprecated"
+
 	// TemplateNameOsAnnotation is an annotation that specifies human readable os name
 	TemplateNameOsAnnotation = "name.os.template.kubevirt.io"
 
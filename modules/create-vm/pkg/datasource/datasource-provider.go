@@ -0,0 +1,37 @@
+package datasource
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtcliv1 "kubevirt.io/client-go/kubecli"
+	cdiv1beta1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+)
+
+type dataSourceProvider struct {
+	client kubevirtcliv1.KubevirtClient
+}
+
+type DataSourceProvider interface {
+	Get(namespace, name string) (*cdiv1beta1.DataSource, error)
+}
+
+func NewDataSourceProvider(client kubevirtcliv1.KubevirtClient) DataSourceProvider {
+	return &dataSourceProvider{
+		client: client,
+	}
+}
+
+func (d *dataSourceProvider) Get(namespace, name string) (*cdiv1beta1.DataSource, error) {
+	return d.client.CdiClient().CdiV1beta1().DataSources(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func IsReady(dataSource *cdiv1beta1.DataSource) bool {
+	for _, condition := range dataSource.Status.Conditions {
+		if condition.Type == cdiv1beta1.DataSourceReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package datavolume
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtcliv1 "kubevirt.io/client-go/kubecli"
+	cdiv1beta1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+)
+
+type dataVolumeProvider struct {
+	client kubevirtcliv1.KubevirtClient
+}
+
+type DataVolumeProvider interface {
+	Get(namespace, name string) (*cdiv1beta1.DataVolume, error)
+	Delete(namespace, name string) error
+}
+
+func NewDataVolumeProvider(client kubevirtcliv1.KubevirtClient) DataVolumeProvider {
+	return &dataVolumeProvider{
+		client: client,
+	}
+}
+
+func (d *dataVolumeProvider) Get(namespace, name string) (*cdiv1beta1.DataVolume, error) {
+	return d.client.CdiClient().CdiV1beta1().DataVolumes(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func (d *dataVolumeProvider) Delete(namespace, name string) error {
+	return d.client.CdiClient().CdiV1beta1().DataVolumes(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+}
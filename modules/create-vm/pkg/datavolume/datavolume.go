@@ -0,0 +1,117 @@
+package datavolume
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/constants"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/dvstatus"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/log"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zerrors"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	cdiv1beta1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+)
+
+// WaitForSuccess waits until all data volumes are imported or cloned and logs their progress.
+// It fails fast when a data volume failed or its importer keeps restarting with an error.
+// Last observed phases of the data volumes are returned also on failure. Timeout of 0 or less waits for the default timeout.
+func WaitForSuccess(provider DataVolumeProvider, namespace string, names []string, timeout time.Duration) (map[string]cdiv1beta1.DataVolumePhase, error) {
+	phases := make(map[string]cdiv1beta1.DataVolumePhase, len(names))
+	for _, name := range names {
+		phases[name] = cdiv1beta1.PhaseUnset
+	}
+	// data volumes are succeeded only when they are also bound, the Succeeded phase is not enough
+	succeeded := make(map[string]bool, len(names))
+
+	conditionFn := func() (bool, error) {
+		done := true
+		for _, name := range names {
+			if succeeded[name] {
+				continue
+			}
+			logFields := []zap.Field{zap.String("name", name), zap.String("namespace", namespace)}
+
+			dv, err := provider.Get(namespace, name)
+			if err != nil {
+				if errors.IsNotFound(err) {
+					log.Logger().Debug("waiting for a data volume to be created", logFields...)
+					done = false
+					continue
+				}
+				return false, err
+			}
+			phases[name] = dv.Status.Phase
+
+			if IsSucceeded(dv) {
+				succeeded[name] = true
+				log.Logger().Info("data volume succeeded", logFields...)
+				continue
+			}
+			done = false
+
+			if dvstatus.HasFailedToImport(dv) {
+				return false, zerrors.NewSoftError("import of data volume %v/%v failed: %v", namespace, name, getRunningMessage(dv))
+			}
+
+			if dv.Status.Phase == cdiv1beta1.Failed {
+				return false, zerrors.NewSoftError("data volume %v/%v is in %v phase: %v", namespace, name, dv.Status.Phase, getRunningMessage(dv))
+			}
+
+			log.Logger().Info("waiting for a data volume to succeed", append(logFields,
+				zap.String("phase", string(dv.Status.Phase)), zap.String("progress", string(dv.Status.Progress)))...)
+		}
+		return done, nil
+	}
+
+	if timeout <= 0 {
+		timeout = constants.DefaultWaitTimeout
+	}
+
+	err := wait.PollImmediate(constants.PollDataVolumeInterval, timeout, conditionFn)
+
+	if err == wait.ErrWaitTimeout {
+		return phases, zerrors.NewSoftError("timed out waiting for data volumes in %v namespace to succeed: %v", namespace, formatPhases(names, phases))
+	}
+
+	return phases, err
+}
+
+// IsSucceeded returns true if the data volume succeeded and is bound
+func IsSucceeded(dv *cdiv1beta1.DataVolume) bool {
+	conditions := getConditionMap(dv)
+	return dv.Status.Phase == cdiv1beta1.Succeeded &&
+		conditions[cdiv1beta1.DataVolumeBound].Status == corev1.ConditionTrue
+}
+
+func getRunningMessage(dv *cdiv1beta1.DataVolume) string {
+	if message := getConditionMap(dv)[cdiv1beta1.DataVolumeRunning].Message; message != "" {
+		return message
+	}
+	return "no message"
+}
+
+func getConditionMap(dv *cdiv1beta1.DataVolume) map[cdiv1beta1.DataVolumeConditionType]cdiv1beta1.DataVolumeCondition {
+	result := map[cdiv1beta1.DataVolumeConditionType]cdiv1beta1.DataVolumeCondition{}
+	for _, cond := range dv.Status.Conditions {
+		result[cond.Type] = cond
+	}
+	return result
+}
+
+func formatPhases(names []string, phases map[string]cdiv1beta1.DataVolumePhase) string {
+	var result string
+	for i, name := range names {
+		if i > 0 {
+			result += ", "
+		}
+		phase := phases[name]
+		if phase == cdiv1beta1.PhaseUnset {
+			phase = "Unknown"
+		}
+		result += fmt.Sprintf("%v is %v", name, phase)
+	}
+	return result
+}
@@ -0,0 +1,107 @@
+package datavolume_test
+
+import (
+	"time"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/datavolume"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	cdiv1beta1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+)
+
+type fakeDataVolumeProvider struct {
+	dataVolumes map[string]*cdiv1beta1.DataVolume
+	// updates replace the data volumes one by one after each get
+	updates map[string][]*cdiv1beta1.DataVolume
+	gets    map[string]int
+}
+
+func (f *fakeDataVolumeProvider) Get(_, name string) (*cdiv1beta1.DataVolume, error) {
+	if f.gets == nil {
+		f.gets = map[string]int{}
+	}
+	f.gets[name]++
+	if dv, ok := f.dataVolumes[name]; ok {
+		if updates := f.updates[name]; len(updates) > 0 {
+			f.dataVolumes[name], f.updates[name] = updates[0], updates[1:]
+		}
+		return dv, nil
+	}
+	return nil, errors.NewNotFound(schema.GroupResource{Group: "cdi.kubevirt.io", Resource: "datavolumes"}, name)
+}
+
+func (f *fakeDataVolumeProvider) Delete(_, _ string) error {
+	return nil
+}
+
+func newDataVolume(phase cdiv1beta1.DataVolumePhase, restartCount int32, bound, running corev1.ConditionStatus, runningReason string) *cdiv1beta1.DataVolume {
+	return &cdiv1beta1.DataVolume{
+		Status: cdiv1beta1.DataVolumeStatus{
+			Phase:        phase,
+			Progress:     "42.00%",
+			RestartCount: restartCount,
+			Conditions: []cdiv1beta1.DataVolumeCondition{
+				{Type: cdiv1beta1.DataVolumeBound, Status: bound},
+				{Type: cdiv1beta1.DataVolumeRunning, Status: running, Reason: runningReason, Message: "Unable to connect to http data source"},
+			},
+		},
+	}
+}
+
+var (
+	succeededDV  = newDataVolume(cdiv1beta1.Succeeded, 0, corev1.ConditionTrue, corev1.ConditionFalse, "Completed")
+	importingDV  = newDataVolume(cdiv1beta1.ImportInProgress, 1, corev1.ConditionTrue, corev1.ConditionTrue, "Pod is running")
+	restartingDV = newDataVolume(cdiv1beta1.ImportInProgress, 4, corev1.ConditionTrue, corev1.ConditionFalse, "Error")
+	failedDV     = newDataVolume(cdiv1beta1.Failed, 0, corev1.ConditionTrue, corev1.ConditionFalse, "Error")
+)
+
+var _ = Describe("DataVolume", func() {
+	It("returns phases of succeeded data volumes", func() {
+		provider := &fakeDataVolumeProvider{dataVolumes: map[string]*cdiv1beta1.DataVolume{"rootdisk": succeededDV, "datadisk": succeededDV}}
+		phases, err := datavolume.WaitForSuccess(provider, "default", []string{"rootdisk", "datadisk"}, 0)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(phases).To(Equal(map[string]cdiv1beta1.DataVolumePhase{"rootdisk": cdiv1beta1.Succeeded, "datadisk": cdiv1beta1.Succeeded}))
+	})
+
+	It("waits until a succeeded data volume is bound", func() {
+		notBoundDV := newDataVolume(cdiv1beta1.Succeeded, 0, corev1.ConditionFalse, corev1.ConditionFalse, "Completed")
+		provider := &fakeDataVolumeProvider{
+			dataVolumes: map[string]*cdiv1beta1.DataVolume{"rootdisk": notBoundDV},
+			updates:     map[string][]*cdiv1beta1.DataVolume{"rootdisk": {succeededDV}},
+		}
+		phases, err := datavolume.WaitForSuccess(provider, "default", []string{"rootdisk"}, time.Minute)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(phases).To(Equal(map[string]cdiv1beta1.DataVolumePhase{"rootdisk": cdiv1beta1.Succeeded}))
+		Expect(provider.gets["rootdisk"]).To(Equal(2))
+	})
+
+	It("times out when a data volume does not succeed", func() {
+		provider := &fakeDataVolumeProvider{dataVolumes: map[string]*cdiv1beta1.DataVolume{"rootdisk": succeededDV, "datadisk": importingDV}}
+		phases, err := datavolume.WaitForSuccess(provider, "default", []string{"rootdisk", "datadisk", "missing"}, time.Millisecond)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(Equal("timed out waiting for data volumes in default namespace to succeed: rootdisk is Succeeded, datadisk is ImportInProgress, missing is Unknown"))
+		Expect(phases).To(HaveKeyWithValue("datadisk", cdiv1beta1.ImportInProgress))
+	})
+
+	DescribeTable("fails fast", func(dv *cdiv1beta1.DataVolume, expectedErrMessage string) {
+		provider := &fakeDataVolumeProvider{dataVolumes: map[string]*cdiv1beta1.DataVolume{"rootdisk": dv}}
+		phases, err := datavolume.WaitForSuccess(provider, "default", []string{"rootdisk"}, time.Minute)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(Equal(expectedErrMessage))
+		Expect(phases).To(HaveKeyWithValue("rootdisk", dv.Status.Phase))
+	},
+		Entry("importer keeps restarting", restartingDV, "import of data volume default/rootdisk failed: Unable to connect to http data source"),
+		Entry("failed phase", failedDV, "data volume default/rootdisk is in Failed phase: Unable to connect to http data source"),
+	)
+
+	DescribeTable("IsSucceeded", func(dv *cdiv1beta1.DataVolume, expected bool) {
+		Expect(datavolume.IsSucceeded(dv)).To(Equal(expected))
+	},
+		Entry("succeeded", succeededDV, true),
+		Entry("not bound", newDataVolume(cdiv1beta1.Succeeded, 0, corev1.ConditionFalse, corev1.ConditionFalse, "Completed"), false),
+		Entry("importing", importingDV, false),
+	)
+})
@@ -0,0 +1,34 @@
+package datavolume
+
+import (
+	"context"
+
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtcliv1 "kubevirt.io/client-go/kubecli"
+)
+
+type sourceProvider struct {
+	client kubevirtcliv1.KubevirtClient
+}
+
+// SourceProvider retrieves sources of data volumes to clone from
+type SourceProvider interface {
+	GetPVC(namespace, name string) (*corev1.PersistentVolumeClaim, error)
+	GetSnapshot(namespace, name string) (*snapshotv1.VolumeSnapshot, error)
+}
+
+func NewSourceProvider(client kubevirtcliv1.KubevirtClient) SourceProvider {
+	return &sourceProvider{
+		client: client,
+	}
+}
+
+func (s *sourceProvider) GetPVC(namespace, name string) (*corev1.PersistentVolumeClaim, error) {
+	return s.client.CoreV1().PersistentVolumeClaims(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func (s *sourceProvider) GetSnapshot(namespace, name string) (*snapshotv1.VolumeSnapshot, error) {
+	return s.client.KubernetesSnapshotClient().SnapshotV1().VolumeSnapshots(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
@@ -0,0 +1,130 @@
+package datavolume
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	cdiv1beta1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+)
+
+// TemplateOverrides rewrite the boot source and storage of a data volume template of a VM.
+// Only one of SourceRef, PVC and Snapshot should be set. Zero values are ignored.
+type TemplateOverrides struct {
+	Name         string
+	SourceRef    *cdiv1beta1.DataVolumeSourceRef
+	PVC          *cdiv1beta1.DataVolumeSourcePVC
+	Snapshot     *cdiv1beta1.DataVolumeSourceSnapshot
+	StorageClass string
+	AccessModes  []corev1.PersistentVolumeAccessMode
+	VolumeMode   *corev1.PersistentVolumeMode
+	Size         *resource.Quantity
+}
+
+// FindTemplate returns a data volume template of the VM by name or the only data volume template of the VM if the name is empty
+func FindTemplate(vm *kubevirtv1.VirtualMachine, name string) (*kubevirtv1.DataVolumeTemplateSpec, error) {
+	if name == "" {
+		if len(vm.Spec.DataVolumeTemplates) != 1 {
+			return nil, fmt.Errorf("VM %v has %v data volume templates, name of the data volume template to override should be specified", vm.Name, len(vm.Spec.DataVolumeTemplates))
+		}
+		return &vm.Spec.DataVolumeTemplates[0], nil
+	}
+
+	for i := range vm.Spec.DataVolumeTemplates {
+		if vm.Spec.DataVolumeTemplates[i].Name == name {
+			return &vm.Spec.DataVolumeTemplates[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("VM %v does not have %v data volume template", vm.Name, name)
+}
+
+// Apply rewrites the data volume template. Namespace of the source ref is kept if not overridden,
+// PVC and snapshot sources without a namespace are cloned from the default namespace.
+func (o *TemplateOverrides) Apply(dataVolumeTemplate *kubevirtv1.DataVolumeTemplateSpec, defaultNamespace string) {
+	spec := &dataVolumeTemplate.Spec
+
+	switch {
+	case o.SourceRef != nil:
+		sourceRef := o.SourceRef.DeepCopy()
+		if sourceRef.Namespace == nil && spec.SourceRef != nil {
+			sourceRef.Namespace = spec.SourceRef.Namespace
+		}
+		spec.Source = nil
+		spec.SourceRef = sourceRef
+	case o.PVC != nil:
+		spec.SourceRef = nil
+		pvc := o.PVC.DeepCopy()
+		if pvc.Namespace == "" {
+			pvc.Namespace = defaultNamespace
+		}
+		spec.Source = &cdiv1beta1.DataVolumeSource{PVC: pvc}
+	case o.Snapshot != nil:
+		spec.SourceRef = nil
+		snapshot := o.Snapshot.DeepCopy()
+		if snapshot.Namespace == "" {
+			snapshot.Namespace = defaultNamespace
+		}
+		spec.Source = &cdiv1beta1.DataVolumeSource{Snapshot: snapshot}
+	}
+
+	if o.StorageClass == "" && len(o.AccessModes) == 0 && o.VolumeMode == nil && o.Size == nil {
+		return
+	}
+
+	if spec.PVC != nil {
+		if o.StorageClass != "" {
+			spec.PVC.StorageClassName = &o.StorageClass
+		}
+		if len(o.AccessModes) > 0 {
+			spec.PVC.AccessModes = o.AccessModes
+		}
+		if o.VolumeMode != nil {
+			spec.PVC.VolumeMode = o.VolumeMode
+		}
+		if o.Size != nil {
+			spec.PVC.Resources.Requests = setStorageRequest(spec.PVC.Resources.Requests, *o.Size)
+		}
+		return
+	}
+
+	if spec.Storage == nil {
+		spec.Storage = &cdiv1beta1.StorageSpec{}
+	}
+	if o.StorageClass != "" {
+		spec.Storage.StorageClassName = &o.StorageClass
+	}
+	if len(o.AccessModes) > 0 {
+		spec.Storage.AccessModes = o.AccessModes
+	}
+	if o.VolumeMode != nil {
+		spec.Storage.VolumeMode = o.VolumeMode
+	}
+	if o.Size != nil {
+		spec.Storage.Resources.Requests = setStorageRequest(spec.Storage.Resources.Requests, *o.Size)
+	}
+}
+
+// GetRequestedSize returns the storage request of the data volume template or nil
+func GetRequestedSize(dataVolumeTemplate *kubevirtv1.DataVolumeTemplateSpec) *resource.Quantity {
+	var requests corev1.ResourceList
+	if pvc := dataVolumeTemplate.Spec.PVC; pvc != nil {
+		requests = pvc.Resources.Requests
+	} else if storage := dataVolumeTemplate.Spec.Storage; storage != nil {
+		requests = storage.Resources.Requests
+	}
+
+	if size, ok := requests[corev1.ResourceStorage]; ok {
+		return &size
+	}
+	return nil
+}
+
+func setStorageRequest(requests corev1.ResourceList, size resource.Quantity) corev1.ResourceList {
+	if requests == nil {
+		requests = corev1.ResourceList{}
+	}
+	requests[corev1.ResourceStorage] = size
+	return requests
+}
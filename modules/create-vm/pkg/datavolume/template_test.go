@@ -0,0 +1,164 @@
+package datavolume_test
+
+import (
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/datavolume"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	cdiv1beta1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+)
+
+const testNamespace = "vms"
+
+var (
+	sourceSize    = resource.MustParse("30Gi")
+	requestedSize = resource.MustParse("50Gi")
+	blockMode     = corev1.PersistentVolumeBlock
+)
+
+func newDataVolumeTemplate(name string, spec cdiv1beta1.DataVolumeSpec) kubevirtv1.DataVolumeTemplateSpec {
+	return kubevirtv1.DataVolumeTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       spec,
+	}
+}
+
+func newDataSourceSpec(namespace *string) cdiv1beta1.DataVolumeSpec {
+	return cdiv1beta1.DataVolumeSpec{
+		SourceRef: &cdiv1beta1.DataVolumeSourceRef{
+			Kind:      "DataSource",
+			Name:      "fedora",
+			Namespace: namespace,
+		},
+		Storage: &cdiv1beta1.StorageSpec{
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: sourceSize},
+			},
+		},
+	}
+}
+
+var _ = Describe("Template", func() {
+	DescribeTable("FindTemplate returns the data volume template", func(name, expectedName string, dataVolumeTemplates ...kubevirtv1.DataVolumeTemplateSpec) {
+		vm := &kubevirtv1.VirtualMachine{Spec: kubevirtv1.VirtualMachineSpec{DataVolumeTemplates: dataVolumeTemplates}}
+
+		dataVolumeTemplate, err := datavolume.FindTemplate(vm, name)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(dataVolumeTemplate.Name).To(Equal(expectedName))
+		Expect(dataVolumeTemplate).To(BeIdenticalTo(&vm.Spec.DataVolumeTemplates[len(dataVolumeTemplates)-1]))
+	},
+		Entry("the only one", "", "rootdisk", newDataVolumeTemplate("rootdisk", cdiv1beta1.DataVolumeSpec{})),
+		Entry("by name", "datadisk", "datadisk", newDataVolumeTemplate("rootdisk", cdiv1beta1.DataVolumeSpec{}), newDataVolumeTemplate("datadisk", cdiv1beta1.DataVolumeSpec{})),
+	)
+
+	DescribeTable("FindTemplate fails", func(name, expectedErrMessage string, dataVolumeTemplates ...kubevirtv1.DataVolumeTemplateSpec) {
+		vm := &kubevirtv1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-vm"},
+			Spec:       kubevirtv1.VirtualMachineSpec{DataVolumeTemplates: dataVolumeTemplates},
+		}
+
+		_, err := datavolume.FindTemplate(vm, name)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(Equal(expectedErrMessage))
+	},
+		Entry("no data volume templates", "", "VM my-vm has 0 data volume templates, name of the data volume template to override should be specified"),
+		Entry("ambiguous", "", "VM my-vm has 2 data volume templates, name of the data volume template to override should be specified",
+			newDataVolumeTemplate("rootdisk", cdiv1beta1.DataVolumeSpec{}), newDataVolumeTemplate("datadisk", cdiv1beta1.DataVolumeSpec{})),
+		Entry("missing", "datadisk", "VM my-vm does not have datadisk data volume template", newDataVolumeTemplate("rootdisk", cdiv1beta1.DataVolumeSpec{})),
+	)
+
+	DescribeTable("Apply rewrites the data volume template", func(overrides *datavolume.TemplateOverrides, spec, expectedSpec cdiv1beta1.DataVolumeSpec) {
+		dataVolumeTemplate := newDataVolumeTemplate("rootdisk", spec)
+		overrides.Apply(&dataVolumeTemplate, testNamespace)
+		Expect(dataVolumeTemplate.Spec).To(Equal(expectedSpec))
+	},
+		Entry("keeps the template without overrides", &datavolume.TemplateOverrides{}, newDataSourceSpec(pointer.String("os-images")), newDataSourceSpec(pointer.String("os-images"))),
+		Entry("keeps namespace of the source ref", &datavolume.TemplateOverrides{
+			SourceRef: &cdiv1beta1.DataVolumeSourceRef{Kind: "DataSource", Name: "rhel9"},
+		}, newDataSourceSpec(pointer.String("os-images")), func() cdiv1beta1.DataVolumeSpec {
+			spec := newDataSourceSpec(pointer.String("os-images"))
+			spec.SourceRef.Name = "rhel9"
+			return spec
+		}()),
+		Entry("replaces namespace of the source ref", &datavolume.TemplateOverrides{
+			SourceRef: &cdiv1beta1.DataVolumeSourceRef{Kind: "DataSource", Name: "rhel9", Namespace: pointer.String("golden-images")},
+		}, newDataSourceSpec(pointer.String("os-images")), func() cdiv1beta1.DataVolumeSpec {
+			spec := newDataSourceSpec(pointer.String("golden-images"))
+			spec.SourceRef.Name = "rhel9"
+			return spec
+		}()),
+		Entry("replaces the source with a source ref", &datavolume.TemplateOverrides{
+			SourceRef: &cdiv1beta1.DataVolumeSourceRef{Kind: "DataSource", Name: "fedora"},
+		}, cdiv1beta1.DataVolumeSpec{
+			Source: &cdiv1beta1.DataVolumeSource{Blank: &cdiv1beta1.DataVolumeBlankImage{}},
+		}, cdiv1beta1.DataVolumeSpec{
+			SourceRef: &cdiv1beta1.DataVolumeSourceRef{Kind: "DataSource", Name: "fedora"},
+		}),
+		Entry("clones a PVC from the default namespace", &datavolume.TemplateOverrides{
+			PVC: &cdiv1beta1.DataVolumeSourcePVC{Name: "fedora-pvc"},
+		}, newDataSourceSpec(nil), cdiv1beta1.DataVolumeSpec{
+			Source: &cdiv1beta1.DataVolumeSource{PVC: &cdiv1beta1.DataVolumeSourcePVC{Namespace: testNamespace, Name: "fedora-pvc"}},
+			Storage: &cdiv1beta1.StorageSpec{
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: sourceSize}},
+			},
+		}),
+		Entry("clones a snapshot", &datavolume.TemplateOverrides{
+			Snapshot: &cdiv1beta1.DataVolumeSourceSnapshot{Namespace: "os-images", Name: "fedora-snapshot"},
+		}, newDataSourceSpec(nil), cdiv1beta1.DataVolumeSpec{
+			Source: &cdiv1beta1.DataVolumeSource{Snapshot: &cdiv1beta1.DataVolumeSourceSnapshot{Namespace: "os-images", Name: "fedora-snapshot"}},
+			Storage: &cdiv1beta1.StorageSpec{
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: sourceSize}},
+			},
+		}),
+		Entry("overrides storage", &datavolume.TemplateOverrides{
+			StorageClass: "fast",
+			AccessModes:  []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+			VolumeMode:   &blockMode,
+			Size:         &requestedSize,
+		}, newDataSourceSpec(nil), cdiv1beta1.DataVolumeSpec{
+			SourceRef: &cdiv1beta1.DataVolumeSourceRef{Kind: "DataSource", Name: "fedora"},
+			Storage: &cdiv1beta1.StorageSpec{
+				StorageClassName: pointer.String("fast"),
+				AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+				VolumeMode:       &blockMode,
+				Resources:        corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: requestedSize}},
+			},
+		}),
+		Entry("overrides pvc", &datavolume.TemplateOverrides{
+			StorageClass: "fast",
+			Size:         &requestedSize,
+		}, cdiv1beta1.DataVolumeSpec{
+			PVC: &corev1.PersistentVolumeClaimSpec{AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}},
+		}, cdiv1beta1.DataVolumeSpec{
+			PVC: &corev1.PersistentVolumeClaimSpec{
+				StorageClassName: pointer.String("fast"),
+				AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				Resources:        corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: requestedSize}},
+			},
+		}),
+		Entry("adds storage", &datavolume.TemplateOverrides{
+			Size: &requestedSize,
+		}, cdiv1beta1.DataVolumeSpec{}, cdiv1beta1.DataVolumeSpec{
+			Storage: &cdiv1beta1.StorageSpec{
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: requestedSize}},
+			},
+		}),
+	)
+
+	DescribeTable("GetRequestedSize returns the storage request", func(spec cdiv1beta1.DataVolumeSpec, expectedSize *resource.Quantity) {
+		dataVolumeTemplate := newDataVolumeTemplate("rootdisk", spec)
+		Expect(datavolume.GetRequestedSize(&dataVolumeTemplate)).To(Equal(expectedSize))
+	},
+		Entry("storage", newDataSourceSpec(nil), &sourceSize),
+		Entry("pvc", cdiv1beta1.DataVolumeSpec{
+			PVC: &corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: requestedSize}},
+			},
+		}, &requestedSize),
+		Entry("none", cdiv1beta1.DataVolumeSpec{Storage: &cdiv1beta1.StorageSpec{}}, nil),
+	)
+})
@@ -0,0 +1,61 @@
+package instancetype
+
+import (
+	"context"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/constants"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zerrors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtcliv1 "kubevirt.io/client-go/kubecli"
+)
+
+type instancetypeProvider struct {
+	client kubevirtcliv1.KubevirtClient
+}
+
+type InstancetypeProvider interface {
+	GetInstancetype(namespace, name, kind string) (metav1.Object, error)
+	GetPreference(namespace, name, kind string) (metav1.Object, error)
+}
+
+func NewInstancetypeProvider(client kubevirtcliv1.KubevirtClient) InstancetypeProvider {
+	return &instancetypeProvider{
+		client: client,
+	}
+}
+
+func (i *instancetypeProvider) GetInstancetype(namespace, name, kind string) (metav1.Object, error) {
+	switch kind {
+	case constants.VirtualMachineInstancetypeKind:
+		instancetype, err := i.client.VirtualMachineInstancetype(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return instancetype, nil
+	case constants.VirtualMachineClusterInstancetypeKind:
+		instancetype, err := i.client.VirtualMachineClusterInstancetype().Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return instancetype, nil
+	}
+	return nil, zerrors.NewMissingRequiredError("unknown instancetype kind: %v", kind)
+}
+
+func (i *instancetypeProvider) GetPreference(namespace, name, kind string) (metav1.Object, error) {
+	switch kind {
+	case constants.VirtualMachinePreferenceKind:
+		preference, err := i.client.VirtualMachinePreference(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return preference, nil
+	case constants.VirtualMachineClusterPreferenceKind:
+		preference, err := i.client.VirtualMachineClusterPreference().Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return preference, nil
+	}
+	return nil, zerrors.NewMissingRequiredError("unknown preference kind: %v", kind)
+}
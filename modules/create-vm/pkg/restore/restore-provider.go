@@ -0,0 +1,42 @@
+package restore
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	snapshotv1alpha1 "kubevirt.io/api/snapshot/v1alpha1"
+	kubevirtcliv1 "kubevirt.io/client-go/kubecli"
+)
+
+type restoreProvider struct {
+	client kubevirtcliv1.KubevirtClient
+}
+
+type RestoreProvider interface {
+	GetSnapshot(namespace, name string) (*snapshotv1alpha1.VirtualMachineSnapshot, error)
+	Create(restore *snapshotv1alpha1.VirtualMachineRestore) (*snapshotv1alpha1.VirtualMachineRestore, error)
+	Get(namespace, name string) (*snapshotv1alpha1.VirtualMachineRestore, error)
+	Delete(namespace, name string) error
+}
+
+func NewRestoreProvider(client kubevirtcliv1.KubevirtClient) RestoreProvider {
+	return &restoreProvider{
+		client: client,
+	}
+}
+
+func (r *restoreProvider) GetSnapshot(namespace, name string) (*snapshotv1alpha1.VirtualMachineSnapshot, error) {
+	return r.client.VirtualMachineSnapshot(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func (r *restoreProvider) Create(restore *snapshotv1alpha1.VirtualMachineRestore) (*snapshotv1alpha1.VirtualMachineRestore, error) {
+	return r.client.VirtualMachineRestore(restore.Namespace).Create(context.TODO(), restore, metav1.CreateOptions{})
+}
+
+func (r *restoreProvider) Get(namespace, name string) (*snapshotv1alpha1.VirtualMachineRestore, error) {
+	return r.client.VirtualMachineRestore(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func (r *restoreProvider) Delete(namespace, name string) error {
+	return r.client.VirtualMachineRestore(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+}
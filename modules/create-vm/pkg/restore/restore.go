@@ -0,0 +1,83 @@
+package restore
+
+import (
+	"time"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/constants"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/log"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zerrors"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	snapshotv1alpha1 "kubevirt.io/api/snapshot/v1alpha1"
+)
+
+// NewRestore builds a restore of the VM snapshot into a new VM with the name.
+// KubeVirt creates the VM because the target does not exist yet.
+func NewRestore(namespace, snapshotName, vmName string) *snapshotv1alpha1.VirtualMachineRestore {
+	apiGroup := kubevirtv1.GroupVersion.Group
+
+	return &snapshotv1alpha1.VirtualMachineRestore{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: snapshotv1alpha1.SchemeGroupVersion.String(),
+			Kind:       constants.VirtualMachineRestoreKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: vmName + "-restore-",
+			Namespace:    namespace,
+		},
+		Spec: snapshotv1alpha1.VirtualMachineRestoreSpec{
+			Target: corev1.TypedLocalObjectReference{
+				APIGroup: &apiGroup,
+				Kind:     constants.VirtualMachineKind,
+				Name:     vmName,
+			},
+			VirtualMachineSnapshotName: snapshotName,
+		},
+	}
+}
+
+// IsSnapshotReady returns true if the VM snapshot can be restored
+func IsSnapshotReady(snapshot *snapshotv1alpha1.VirtualMachineSnapshot) bool {
+	return snapshot.Status != nil && snapshot.Status.ReadyToUse != nil && *snapshot.Status.ReadyToUse
+}
+
+// WaitForSuccess waits until the restore completes. Timeout of 0 or less waits indefinitely.
+func WaitForSuccess(provider RestoreProvider, namespace, name string, timeout time.Duration) error {
+	logFields := []zap.Field{zap.String("name", name), zap.String("namespace", namespace)}
+
+	conditionFn := func() (bool, error) {
+		restore, err := provider.Get(namespace, name)
+		if err != nil {
+			return false, err
+		}
+
+		if status := restore.Status; status != nil {
+			if status.Complete != nil && *status.Complete {
+				return true, nil
+			}
+			for _, condition := range status.Conditions {
+				if condition.Type == snapshotv1alpha1.ConditionFailure && condition.Status == corev1.ConditionTrue {
+					return false, zerrors.NewSoftError("restore %v/%v failed: %v", namespace, name, condition.Reason)
+				}
+			}
+		}
+
+		log.Logger().Debug("waiting for a restore to complete", logFields...)
+		return false, nil
+	}
+
+	var err error
+	if timeout <= 0 {
+		err = wait.PollImmediateInfinite(constants.PollVMIInterval, conditionFn)
+	} else {
+		err = wait.PollImmediate(constants.PollVMIInterval, timeout, conditionFn)
+	}
+
+	if err == wait.ErrWaitTimeout {
+		return zerrors.NewSoftError("timed out waiting for restore %v/%v to complete", namespace, name)
+	}
+	return err
+}
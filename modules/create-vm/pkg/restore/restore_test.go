@@ -0,0 +1,89 @@
+package restore_test
+
+import (
+	"time"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/restore"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+	snapshotv1alpha1 "kubevirt.io/api/snapshot/v1alpha1"
+)
+
+type fakeRestoreProvider struct {
+	restore *snapshotv1alpha1.VirtualMachineRestore
+}
+
+func (f *fakeRestoreProvider) GetSnapshot(_, _ string) (*snapshotv1alpha1.VirtualMachineSnapshot, error) {
+	return nil, nil
+}
+
+func (f *fakeRestoreProvider) Create(restore *snapshotv1alpha1.VirtualMachineRestore) (*snapshotv1alpha1.VirtualMachineRestore, error) {
+	return restore, nil
+}
+
+func (f *fakeRestoreProvider) Get(_, _ string) (*snapshotv1alpha1.VirtualMachineRestore, error) {
+	return f.restore, nil
+}
+
+func (f *fakeRestoreProvider) Delete(_, _ string) error {
+	return nil
+}
+
+func newRestore(complete *bool, conditions ...snapshotv1alpha1.Condition) *snapshotv1alpha1.VirtualMachineRestore {
+	return &snapshotv1alpha1.VirtualMachineRestore{
+		Status: &snapshotv1alpha1.VirtualMachineRestoreStatus{
+			Complete:   complete,
+			Conditions: conditions,
+		},
+	}
+}
+
+var _ = Describe("Restore", func() {
+	It("builds a restore into a new VM", func() {
+		vmRestore := restore.NewRestore("default", "golden-snapshot", "my-vm")
+		Expect(vmRestore.Kind).To(Equal("VirtualMachineRestore"))
+		Expect(vmRestore.APIVersion).To(Equal("snapshot.kubevirt.io/v1alpha1"))
+		Expect(vmRestore.GenerateName).To(Equal("my-vm-restore-"))
+		Expect(vmRestore.Namespace).To(Equal("default"))
+		Expect(vmRestore.Spec.VirtualMachineSnapshotName).To(Equal("golden-snapshot"))
+		Expect(vmRestore.Spec.Target).To(Equal(corev1.TypedLocalObjectReference{
+			APIGroup: pointer.String("kubevirt.io"),
+			Kind:     "VirtualMachine",
+			Name:     "my-vm",
+		}))
+	})
+
+	DescribeTable("IsSnapshotReady", func(status *snapshotv1alpha1.VirtualMachineSnapshotStatus, expected bool) {
+		Expect(restore.IsSnapshotReady(&snapshotv1alpha1.VirtualMachineSnapshot{Status: status})).To(Equal(expected))
+	},
+		Entry("ready", &snapshotv1alpha1.VirtualMachineSnapshotStatus{ReadyToUse: pointer.Bool(true)}, true),
+		Entry("not ready", &snapshotv1alpha1.VirtualMachineSnapshotStatus{ReadyToUse: pointer.Bool(false)}, false),
+		Entry("no ready to use", &snapshotv1alpha1.VirtualMachineSnapshotStatus{}, false),
+		Entry("no status", nil, false),
+	)
+
+	It("waits for a completed restore", func() {
+		Expect(restore.WaitForSuccess(&fakeRestoreProvider{restore: newRestore(pointer.Bool(true))}, "default", "my-vm-restore", 0)).To(Succeed())
+	})
+
+	It("fails when the restore failed", func() {
+		provider := &fakeRestoreProvider{restore: newRestore(pointer.Bool(false),
+			snapshotv1alpha1.Condition{Type: snapshotv1alpha1.ConditionFailure, Status: corev1.ConditionTrue, Reason: "snapshot content not found"})}
+
+		err := restore.WaitForSuccess(provider, "default", "my-vm-restore", time.Minute)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(Equal("restore default/my-vm-restore failed: snapshot content not found"))
+	})
+
+	DescribeTable("times out when the restore does not complete", func(vmRestore *snapshotv1alpha1.VirtualMachineRestore) {
+		err := restore.WaitForSuccess(&fakeRestoreProvider{restore: vmRestore}, "default", "my-vm-restore", time.Millisecond)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(Equal("timed out waiting for restore default/my-vm-restore to complete"))
+	},
+		Entry("no status", &snapshotv1alpha1.VirtualMachineRestore{}),
+		Entry("in progress", newRestore(pointer.Bool(false),
+			snapshotv1alpha1.Condition{Type: snapshotv1alpha1.ConditionProgressing, Status: corev1.ConditionTrue})),
+	)
+})
@@ -0,0 +1,17 @@
+package rollback_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/utilstest"
+)
+
+func TestRollback(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Rollback Suite")
+}
+
+var _ = BeforeSuite(utilstest.SetupTestSuite)
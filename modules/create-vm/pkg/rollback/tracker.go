@@ -0,0 +1,82 @@
+package rollback
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/log"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zerrors"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+const causeErrorName = "cause"
+
+type DeleteFunc func() error
+
+type trackedObject struct {
+	kind      string
+	namespace string
+	name      string
+	delete    DeleteFunc
+}
+
+func (t trackedObject) String() string {
+	return fmt.Sprintf("%v %v/%v", t.kind, t.namespace, t.name)
+}
+
+// Tracker remembers objects created by the task, so they can be deleted when a later step fails.
+// Objects can be tracked concurrently.
+type Tracker struct {
+	enabled bool
+	lock    sync.Mutex
+	objects []trackedObject
+}
+
+func NewTracker(enabled bool) *Tracker {
+	return &Tracker{
+		enabled: enabled,
+	}
+}
+
+func (t *Tracker) Track(kind, namespace, name string, deleteFn DeleteFunc) {
+	if !t.enabled {
+		return
+	}
+	log.Logger().Debug("tracking created object", zap.String("kind", kind), zap.String("namespace", namespace), zap.String("name", name))
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.objects = append(t.objects, trackedObject{
+		kind:      kind,
+		namespace: namespace,
+		name:      name,
+		delete:    deleteFn,
+	})
+}
+
+// Rollback deletes all tracked objects in reverse order of their creation.
+// Returns the cause unchanged when there is nothing to roll back, otherwise the cause together with any cleanup errors.
+func (t *Tracker) Rollback(cause error) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if !t.enabled || len(t.objects) == 0 {
+		return cause
+	}
+
+	var rollbackErrors zerrors.MultiError
+	if cause != nil {
+		rollbackErrors.Add(causeErrorName, cause)
+	}
+
+	for i := len(t.objects) - 1; i >= 0; i-- {
+		object := t.objects[i]
+		log.Logger().Debug("rolling back created object", zap.String("object", object.String()))
+		if err := object.delete(); err != nil && !errors.IsNotFound(err) {
+			rollbackErrors.Add(object.String(), zerrors.NewSoftError("rollback: could not delete %v: %v", object.String(), err.Error()))
+		}
+	}
+	t.objects = nil
+
+	return rollbackErrors.AsOptional()
+}
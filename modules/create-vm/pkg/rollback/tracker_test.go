@@ -0,0 +1,83 @@
+package rollback_test
+
+import (
+	"errors"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zerrors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/rollback"
+)
+
+var _ = Describe("Tracker", func() {
+	var (
+		deleted []string
+		cause   error
+	)
+
+	deleteFn := func(name string, err error) rollback.DeleteFunc {
+		return func() error {
+			deleted = append(deleted, name)
+			return err
+		}
+	}
+
+	BeforeEach(func() {
+		deleted = nil
+		cause = zerrors.NewSoftError("start failed")
+	})
+
+	It("does nothing when disabled", func() {
+		tracker := rollback.NewTracker(false)
+		tracker.Track("VirtualMachine", "default", "vm", deleteFn("vm", nil))
+
+		Expect(tracker.Rollback(cause)).To(Equal(cause))
+		Expect(deleted).To(BeEmpty())
+	})
+
+	It("returns cause when nothing was created", func() {
+		Expect(rollback.NewTracker(true).Rollback(cause)).To(Equal(cause))
+	})
+
+	It("deletes objects in reverse order", func() {
+		tracker := rollback.NewTracker(true)
+		tracker.Track("VirtualMachine", "default", "vm", deleteFn("vm", nil))
+		tracker.Track("DataVolume", "default", "dv", deleteFn("dv", nil))
+
+		err := tracker.Rollback(cause)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(Equal("start failed\n"))
+		Expect(deleted).To(Equal([]string{"dv", "vm"}))
+	})
+
+	It("ignores already deleted objects", func() {
+		tracker := rollback.NewTracker(true)
+		tracker.Track("DataVolume", "default", "dv", deleteFn("dv", k8serrors.NewNotFound(schema.GroupResource{Resource: "datavolumes"}, "dv")))
+
+		Expect(tracker.Rollback(cause).Error()).To(Equal("start failed\n"))
+	})
+
+	It("reports cleanup errors together with the cause", func() {
+		tracker := rollback.NewTracker(true)
+		tracker.Track("VirtualMachine", "default", "vm", deleteFn("vm", errors.New("forbidden")))
+		tracker.Track("DataVolume", "default", "dv", deleteFn("dv", nil))
+
+		err := tracker.Rollback(cause)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(Equal("start failed\nrollback: could not delete VirtualMachine default/vm: forbidden\n"))
+		Expect(zerrors.IsErrorSoft(err)).To(BeTrue())
+		Expect(deleted).To(Equal([]string{"dv", "vm"}))
+	})
+
+	It("rolls back only once", func() {
+		tracker := rollback.NewTracker(true)
+		tracker.Track("VirtualMachine", "default", "vm", deleteFn("vm", nil))
+
+		tracker.Rollback(cause)
+		Expect(tracker.Rollback(cause)).To(Equal(cause))
+		Expect(deleted).To(Equal([]string{"vm"}))
+	})
+})
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtcliv1 "kubevirt.io/client-go/kubecli"
+)
+
+type serviceProvider struct {
+	client kubevirtcliv1.KubevirtClient
+}
+
+type ServiceProvider interface {
+	Create(service *corev1.Service) (*corev1.Service, error)
+	DryRunCreate(service *corev1.Service) (*corev1.Service, error)
+	Delete(namespace, name string) error
+}
+
+func NewServiceProvider(client kubevirtcliv1.KubevirtClient) ServiceProvider {
+	return &serviceProvider{
+		client: client,
+	}
+}
+
+func (s *serviceProvider) Create(service *corev1.Service) (*corev1.Service, error) {
+	return s.client.CoreV1().Services(service.Namespace).Create(context.Background(), service, metav1.CreateOptions{})
+}
+
+func (s *serviceProvider) DryRunCreate(service *corev1.Service) (*corev1.Service, error) {
+	return s.client.CoreV1().Services(service.Namespace).Create(context.Background(), service, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}})
+}
+
+func (s *serviceProvider) Delete(namespace, name string) error {
+	return s.client.CoreV1().Services(namespace).Delete(context.Background(), name, metav1.DeleteOptions{})
+}
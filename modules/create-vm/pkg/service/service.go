@@ -0,0 +1,147 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/constants"
+	lab "github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/constants/labels"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+const portNameSep = ":"
+const protocolSep = "/"
+const namePrefix = "vm-"
+
+// PortResult describes a port assigned to the service
+type PortResult struct {
+	Name     string          `json:"name"`
+	Port     int32           `json:"port"`
+	Protocol corev1.Protocol `json:"protocol"`
+	NodePort int32           `json:"nodePort,omitempty"`
+}
+
+// IsServiceType returns true if the type is one of ClusterIP, NodePort or LoadBalancer
+func IsServiceType(serviceType corev1.ServiceType) bool {
+	switch serviceType {
+	case corev1.ServiceTypeClusterIP, corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer:
+		return true
+	}
+	return false
+}
+
+// ParsePorts parses ports in a [NAME:]PORT[/PROTOCOL] format, eg. ssh:22/TCP.
+// The protocol defaults to TCP and the name to the lowercase protocol and the port (eg. tcp-22).
+func ParsePorts(ports []string) ([]corev1.ServicePort, error) {
+	var result []corev1.ServicePort
+	names := map[string]bool{}
+
+	for _, port := range ports {
+		port = strings.TrimSpace(port)
+		if port == "" {
+			continue
+		}
+
+		var name string
+		portAndProtocol := port
+		if nameAndPort := strings.SplitN(port, portNameSep, 2); len(nameAndPort) == 2 {
+			name, portAndProtocol = nameAndPort[0], nameAndPort[1]
+		}
+
+		protocol := corev1.ProtocolTCP
+		if split := strings.SplitN(portAndProtocol, protocolSep, 2); len(split) == 2 {
+			portAndProtocol, protocol = split[0], corev1.Protocol(strings.ToUpper(split[1]))
+		}
+
+		if protocol != corev1.ProtocolTCP && protocol != corev1.ProtocolUDP && protocol != corev1.ProtocolSCTP {
+			return nil, fmt.Errorf("%v: %v is not a valid protocol, only %v|%v|%v is allowed", port, protocol, corev1.ProtocolTCP, corev1.ProtocolUDP, corev1.ProtocolSCTP)
+		}
+
+		portNumber, err := strconv.ParseInt(portAndProtocol, 10, 32)
+		if err != nil || validation.IsValidPortNum(int(portNumber)) != nil {
+			return nil, fmt.Errorf("%v: %v is not a valid port number", port, portAndProtocol)
+		}
+
+		if name == "" {
+			name = strings.ToLower(string(protocol)) + "-" + portAndProtocol
+		} else if errs := validation.IsValidPortName(name); len(errs) > 0 {
+			return nil, fmt.Errorf("%v: %v is not a valid port name: %v", port, name, strings.Join(errs, ", "))
+		}
+
+		if names[name] {
+			return nil, fmt.Errorf("%v: port name %v is used more than once", port, name)
+		}
+		names[name] = true
+
+		result = append(result, corev1.ServicePort{
+			Name:       name,
+			Protocol:   protocol,
+			Port:       int32(portNumber),
+			TargetPort: intstr.FromInt(int(portNumber)),
+		})
+	}
+
+	return result, nil
+}
+
+// GetName derives a valid service name (DNS-1035 label) from the name of the VM.
+// Dots are replaced by dashes, the name is prefixed when it does not start with a letter and truncated to 63 characters.
+func GetName(vmName string) string {
+	name := strings.ReplaceAll(strings.ToLower(vmName), ".", "-")
+	if name == "" || name[0] < 'a' || name[0] > 'z' {
+		name = namePrefix + name
+	}
+	if len(name) > validation.DNS1035LabelMaxLength {
+		name = name[:validation.DNS1035LabelMaxLength]
+	}
+	return strings.TrimRight(name, "-")
+}
+
+// NewService builds a service named after the VM which selects the VMI by the vm.kubevirt.io/name label.
+// The service is owned by the VM if the VM has been persisted.
+func NewService(vm *kubevirtv1.VirtualMachine, serviceType corev1.ServiceType, ports []corev1.ServicePort) *corev1.Service {
+	service := &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       constants.ServiceKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetName(vm.Name),
+			Namespace: vm.Namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			Type:  serviceType,
+			Ports: ports,
+			Selector: map[string]string{
+				lab.VMNameLabel: vm.Name,
+			},
+		},
+	}
+
+	if vm.UID != "" {
+		service.OwnerReferences = []metav1.OwnerReference{
+			*metav1.NewControllerRef(vm, kubevirtv1.VirtualMachineGroupVersionKind),
+		}
+	}
+
+	return service
+}
+
+// GetPortResults returns ports of the service including node ports assigned by the server
+func GetPortResults(service *corev1.Service) []PortResult {
+	result := make([]PortResult, 0, len(service.Spec.Ports))
+	for _, port := range service.Spec.Ports {
+		result = append(result, PortResult{
+			Name:     port.Name,
+			Port:     port.Port,
+			Protocol: port.Protocol,
+			NodePort: port.NodePort,
+		})
+	}
+	return result
+}
@@ -0,0 +1,108 @@
+package service_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/service"
+)
+
+var _ = Describe("Service", func() {
+	DescribeTable("Parses ports", func(ports []string, expectedPorts []corev1.ServicePort) {
+		result, err := service.ParsePorts(ports)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(result).To(Equal(expectedPorts))
+	},
+		Entry("no ports", []string{}, nil),
+		Entry("port only", []string{"22"}, []corev1.ServicePort{
+			{Name: "tcp-22", Protocol: corev1.ProtocolTCP, Port: 22, TargetPort: intstr.FromInt(22)},
+		}),
+		Entry("named ports with protocols", []string{"ssh:22/TCP", " dns:53/udp ", "rdp:3389"}, []corev1.ServicePort{
+			{Name: "ssh", Protocol: corev1.ProtocolTCP, Port: 22, TargetPort: intstr.FromInt(22)},
+			{Name: "dns", Protocol: corev1.ProtocolUDP, Port: 53, TargetPort: intstr.FromInt(53)},
+			{Name: "rdp", Protocol: corev1.ProtocolTCP, Port: 3389, TargetPort: intstr.FromInt(3389)},
+		}),
+		Entry("same port with different protocols", []string{"53/TCP", "53/UDP"}, []corev1.ServicePort{
+			{Name: "tcp-53", Protocol: corev1.ProtocolTCP, Port: 53, TargetPort: intstr.FromInt(53)},
+			{Name: "udp-53", Protocol: corev1.ProtocolUDP, Port: 53, TargetPort: intstr.FromInt(53)},
+		}),
+	)
+
+	DescribeTable("Rejects invalid ports", func(ports []string, expectedErrMessage string) {
+		_, err := service.ParsePorts(ports)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(expectedErrMessage))
+	},
+		Entry("invalid number", []string{"ssh:twenty"}, "twenty is not a valid port number"),
+		Entry("port out of range", []string{"70000"}, "70000 is not a valid port number"),
+		Entry("invalid protocol", []string{"22/ICMP"}, "ICMP is not a valid protocol"),
+		Entry("invalid name", []string{"SSH_PORT:22"}, "SSH_PORT is not a valid port name"),
+		Entry("duplicate name", []string{"ssh:22", "ssh:2222"}, "port name ssh is used more than once"),
+	)
+
+	It("creates a service selecting the VMI", func() {
+		vm := &kubevirtv1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-vm", Namespace: "default", UID: "1234"},
+		}
+		ports := []corev1.ServicePort{{Name: "ssh", Protocol: corev1.ProtocolTCP, Port: 22}}
+
+		result := service.NewService(vm, corev1.ServiceTypeNodePort, ports)
+
+		Expect(result.Name).To(Equal("my-vm"))
+		Expect(result.Namespace).To(Equal("default"))
+		Expect(result.Spec.Type).To(Equal(corev1.ServiceTypeNodePort))
+		Expect(result.Spec.Ports).To(Equal(ports))
+		Expect(result.Spec.Selector).To(Equal(map[string]string{"vm.kubevirt.io/name": "my-vm"}))
+		Expect(result.OwnerReferences).To(HaveLen(1))
+		Expect(result.OwnerReferences[0].Kind).To(Equal("VirtualMachine"))
+		Expect(result.OwnerReferences[0].Name).To(Equal("my-vm"))
+		Expect(string(result.OwnerReferences[0].UID)).To(Equal("1234"))
+	})
+
+	DescribeTable("derives a valid service name from the VM name", func(vmName, expectedName string) {
+		name := service.GetName(vmName)
+		Expect(name).To(Equal(expectedName))
+		Expect(validation.IsDNS1035Label(name)).To(BeEmpty())
+	},
+		Entry("valid name", "my-vm", "my-vm"),
+		Entry("name with dots", "my.vm.example", "my-vm-example"),
+		Entry("name starting with a digit", "0-vm", "vm-0-vm"),
+		Entry("long name", strings.Repeat("a", 60)+".vm", strings.Repeat("a", 60)+"-vm"),
+		Entry("too long name", strings.Repeat("a", 70), strings.Repeat("a", 63)),
+		Entry("too long name truncated at a dot", strings.Repeat("a", 62)+".vm", strings.Repeat("a", 62)),
+	)
+
+	It("names the service after the VM", func() {
+		vm := &kubevirtv1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: "1.example", Namespace: "default"},
+		}
+
+		result := service.NewService(vm, corev1.ServiceTypeClusterIP, nil)
+
+		Expect(result.Name).To(Equal("vm-1-example"))
+		Expect(result.Spec.Selector).To(Equal(map[string]string{"vm.kubevirt.io/name": "1.example"}))
+	})
+
+	It("does not set an owner of a service for a VM which was not persisted", func() {
+		vm := &kubevirtv1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-vm", Namespace: "default"},
+		}
+		Expect(service.NewService(vm, corev1.ServiceTypeClusterIP, nil).OwnerReferences).To(BeEmpty())
+	})
+
+	It("returns assigned ports", func() {
+		svc := &corev1.Service{Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{
+			{Name: "ssh", Protocol: corev1.ProtocolTCP, Port: 22, NodePort: 30022},
+		}}}
+		Expect(service.GetPortResults(svc)).To(Equal([]service.PortResult{
+			{Name: "ssh", Protocol: corev1.ProtocolTCP, Port: 22, NodePort: 30022},
+		}))
+	})
+})
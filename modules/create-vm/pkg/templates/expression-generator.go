@@ -0,0 +1,109 @@
+package templates
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	alphabet        = "abcdefghijklmnopqrstuvwxyz"
+	upperAlphabet   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	numerals        = "0123456789"
+	symbols         = "~!@#$%^&*()-_+={}[]\\|<,>.?/\"';:`"
+	rangeCharacters = alphabet + upperAlphabet + numerals
+
+	maxGeneratedLength = 255
+)
+
+var (
+	// matches generators like [a-zA-Z0-9]{8} or [\w]{16}
+	generatorExp = regexp.MustCompile(`\[([a-zA-Z0-9\-\\]+)\]\{(\w+)\}`)
+)
+
+// GenerateValue generates a value from an expression in the same format as the OpenShift expression generator.
+// Literal characters are kept as is and each [range]{length} generator is replaced with random characters from the range.
+// Supported ranges are a-z style ranges, single characters, \w (word), \d (digits), \a (letters) and \A (symbols).
+func GenerateValue(expression string) (string, error) {
+	var generateErr error
+
+	value := generatorExp.ReplaceAllStringFunc(expression, func(generator string) string {
+		if generateErr != nil {
+			return generator
+		}
+
+		match := generatorExp.FindStringSubmatch(generator)
+		characters, err := rangeToCharacters(match[1])
+		if err != nil {
+			generateErr = err
+			return generator
+		}
+
+		length, err := strconv.Atoi(match[2])
+		if err != nil || length <= 0 || length > maxGeneratedLength {
+			generateErr = fmt.Errorf("invalid length %v in %v, must be within [1-%v] characters", match[2], generator, maxGeneratedLength)
+			return generator
+		}
+
+		result := make([]byte, length)
+		for i := range result {
+			result[i] = characters[rand.Intn(len(characters))]
+		}
+		return string(result)
+	})
+
+	if generateErr != nil {
+		return "", generateErr
+	}
+
+	return value, nil
+}
+
+func rangeToCharacters(rangeExpression string) (string, error) {
+	var characters strings.Builder
+
+	for i := 0; i < len(rangeExpression); i++ {
+		current := rangeExpression[i]
+
+		if current == '\\' {
+			if i+1 >= len(rangeExpression) {
+				return "", fmt.Errorf("invalid range %v: trailing backslash", rangeExpression)
+			}
+			i++
+			switch rangeExpression[i] {
+			case 'w':
+				characters.WriteString(alphabet + upperAlphabet + numerals + "_")
+			case 'd':
+				characters.WriteString(numerals)
+			case 'a':
+				characters.WriteString(alphabet + upperAlphabet)
+			case 'A':
+				characters.WriteString(symbols)
+			default:
+				return "", fmt.Errorf("invalid range %v: unknown class \\%c", rangeExpression, rangeExpression[i])
+			}
+			continue
+		}
+
+		if i+2 < len(rangeExpression) && rangeExpression[i+1] == '-' {
+			from := strings.IndexByte(rangeCharacters, current)
+			to := strings.IndexByte(rangeCharacters, rangeExpression[i+2])
+			if from < 0 || to < 0 || from > to {
+				return "", fmt.Errorf("invalid range %v: %c-%c", rangeExpression, current, rangeExpression[i+2])
+			}
+			characters.WriteString(rangeCharacters[from : to+1])
+			i += 2
+			continue
+		}
+
+		characters.WriteByte(current)
+	}
+
+	if characters.Len() == 0 {
+		return "", fmt.Errorf("invalid range %v: no characters to generate from", rangeExpression)
+	}
+
+	return characters.String(), nil
+}
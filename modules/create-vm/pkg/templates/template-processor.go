@@ -0,0 +1,192 @@
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zerrors"
+	templatev1 "github.com/openshift/api/template/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	generateExpression = "expression"
+)
+
+var (
+	// ${PARAM} and ${{PARAM}} are substituted anywhere in a string value
+	stringParameterExp = regexp.MustCompile(`\$\{\{([a-zA-Z0-9_]+)\}\}|\$\{([a-zA-Z0-9_]+?)\}`)
+	// ${{PARAM}} is not quoted when it is the whole string value
+	nonStringParameterExp = regexp.MustCompile(`^\$\{\{([a-zA-Z0-9_]+)\}\}$`)
+)
+
+// ProcessTemplate processes the template the same way as the processedtemplates API of OpenShift does,
+// but on the client side. It returns a copy of the template with resolved parameter values and objects.
+func ProcessTemplate(template *templatev1.Template, paramValues map[string]string) (*templatev1.Template, error) {
+	processedTemplate := template.DeepCopy()
+
+	if err := resolveParameters(processedTemplate, paramValues); err != nil {
+		return nil, err
+	}
+
+	params := make(map[string]string, len(processedTemplate.Parameters))
+	for _, param := range processedTemplate.Parameters {
+		params[param.Name] = param.Value
+	}
+
+	objectLabels := make(map[string]string, len(processedTemplate.ObjectLabels))
+	for key, value := range processedTemplate.ObjectLabels {
+		objectLabels[substituteString(key, params)] = substituteString(value, params)
+	}
+
+	for i, object := range processedTemplate.Objects {
+		processedObject, err := processObject(object, params, objectLabels)
+		if err != nil {
+			return nil, zerrors.NewMissingRequiredError("could not process object %v of template %v: %v", i, template.Name, err.Error())
+		}
+		processedTemplate.Objects[i] = processedObject
+	}
+
+	return processedTemplate, nil
+}
+
+func resolveParameters(template *templatev1.Template, paramValues map[string]string) error {
+	knownParams := make(map[string]bool, len(template.Parameters))
+	for _, param := range template.Parameters {
+		knownParams[param.Name] = true
+	}
+
+	var unknownParams []string
+	for name := range paramValues {
+		if !knownParams[name] {
+			unknownParams = append(unknownParams, name)
+		}
+	}
+	if len(unknownParams) > 0 {
+		sort.Strings(unknownParams)
+		return zerrors.NewMissingRequiredError("unknown template params: %v", unknownParams)
+	}
+
+	var paramsError zerrors.MultiError
+	for i, param := range template.Parameters {
+		if additionalValue := paramValues[param.Name]; additionalValue != "" {
+			template.Parameters[i].Value = additionalValue
+		} else if param.Value == "" && param.Generate != "" {
+			if param.Generate != generateExpression {
+				paramsError.Add(param.Name, zerrors.NewMissingRequiredError("param %v has unsupported generator %v", param.Name, param.Generate))
+				continue
+			}
+			generatedValue, err := GenerateValue(param.From)
+			if err != nil {
+				paramsError.Add(param.Name, zerrors.NewMissingRequiredError("could not generate value of param %v: %v", param.Name, err.Error()))
+				continue
+			}
+			template.Parameters[i].Value = generatedValue
+		}
+
+		if template.Parameters[i].Value == "" && param.Required {
+			paramsError.Add(param.Name, zerrors.NewMissingRequiredError("required param %v is missing a value", param.Name))
+		}
+	}
+
+	if !paramsError.IsEmpty() {
+		return paramsError.ShortPrint("template params could not be resolved:").AsOptional()
+	}
+
+	return nil
+}
+
+func processObject(object runtime.RawExtension, params map[string]string, objectLabels map[string]string) (runtime.RawExtension, error) {
+	var content interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(object.Raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&content); err != nil {
+		return runtime.RawExtension{}, err
+	}
+
+	content = substituteValue(content, params)
+
+	if len(objectLabels) > 0 {
+		if err := addObjectLabels(content, objectLabels); err != nil {
+			return runtime.RawExtension{}, err
+		}
+	}
+
+	raw, err := json.Marshal(content)
+	if err != nil {
+		return runtime.RawExtension{}, err
+	}
+
+	return runtime.RawExtension{Raw: raw}, nil
+}
+
+func substituteValue(value interface{}, params map[string]string) interface{} {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(typedValue))
+		for key, val := range typedValue {
+			result[substituteString(key, params)] = substituteValue(val, params)
+		}
+		return result
+	case []interface{}:
+		for i, val := range typedValue {
+			typedValue[i] = substituteValue(val, params)
+		}
+		return typedValue
+	case string:
+		if match := nonStringParameterExp.FindStringSubmatch(typedValue); match != nil {
+			if paramValue, ok := params[match[1]]; ok {
+				var nonStringValue interface{}
+				decoder := json.NewDecoder(bytes.NewReader([]byte(paramValue)))
+				decoder.UseNumber()
+				if err := decoder.Decode(&nonStringValue); err == nil && !decoder.More() {
+					return nonStringValue
+				}
+				return paramValue
+			}
+		}
+		return substituteString(typedValue, params)
+	}
+	return value
+}
+
+func substituteString(value string, params map[string]string) string {
+	return stringParameterExp.ReplaceAllStringFunc(value, func(match string) string {
+		submatches := stringParameterExp.FindStringSubmatch(match)
+		// a value embedded in a string can only be used as a string
+		name := submatches[1] + submatches[2]
+		if paramValue, ok := params[name]; ok {
+			return paramValue
+		}
+		return match
+	})
+}
+
+func addObjectLabels(content interface{}, objectLabels map[string]string) error {
+	object, ok := content.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("object is not a map")
+	}
+
+	metadata, ok := object["metadata"].(map[string]interface{})
+	if !ok {
+		metadata = map[string]interface{}{}
+		object["metadata"] = metadata
+	}
+
+	labels, ok := metadata["labels"].(map[string]interface{})
+	if !ok {
+		labels = map[string]interface{}{}
+		metadata["labels"] = labels
+	}
+
+	for key, value := range objectLabels {
+		labels[key] = value
+	}
+
+	return nil
+}
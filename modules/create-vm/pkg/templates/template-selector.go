@@ -0,0 +1,39 @@
+package templates
+
+import (
+	"sort"
+
+	lab "github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/constants/labels"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/log"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zconstants"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zerrors"
+	templatev1 "github.com/openshift/api/template/v1"
+	"go.uber.org/zap"
+)
+
+// SelectTemplate picks the newest template by the version label. Deprecated templates are skipped.
+// Templates with the same version are ordered by name to keep the selection stable.
+func SelectTemplate(templates []templatev1.Template) (*templatev1.Template, error) {
+	var candidates []templatev1.Template
+	for _, template := range templates {
+		if template.Annotations[lab.TemplateDeprecatedAnnotation] == zconstants.True {
+			log.Logger().Debug("skipping deprecated template", zap.String("name", template.Name))
+			continue
+		}
+		candidates = append(candidates, template)
+	}
+
+	if len(candidates) == 0 {
+		return nil, zerrors.NewSoftError("no matching template found")
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		versionI, versionJ := candidates[i].Labels[lab.TemplateVersionLabel], candidates[j].Labels[lab.TemplateVersionLabel]
+		if versionI != versionJ {
+			return textIDs{versionI, versionJ}.Less(0, 1)
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+
+	return &candidates[len(candidates)-1], nil
+}
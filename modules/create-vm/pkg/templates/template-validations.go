@@ -0,0 +1,225 @@
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/log"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zerrors"
+	templatev1 "github.com/openshift/api/template/v1"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+const (
+	jsonPathPrefix = "jsonpath::"
+
+	integerRule = "integer"
+	enumRule    = "enum"
+	regexRule   = "regex"
+)
+
+// validation is a single rule of the validations annotation of common templates.
+// Paths are evaluated against the VMI template of the VM (.spec.template).
+type validation struct {
+	Name        string      `json:"name"`
+	Path        string      `json:"path"`
+	Rule        string      `json:"rule"`
+	Message     string      `json:"message,omitempty"`
+	Valid       string      `json:"valid,omitempty"`
+	Min         interface{} `json:"min,omitempty"`
+	Max         interface{} `json:"max,omitempty"`
+	Values      []string    `json:"values,omitempty"`
+	Regex       string      `json:"regex,omitempty"`
+	JustWarning bool        `json:"justWarning,omitempty"`
+}
+
+func getValidations(template *templatev1.Template) ([]validation, error) {
+	validationsJSON := template.GetAnnotations()[validationsAnnotation]
+	if strings.TrimSpace(validationsJSON) == "" {
+		return nil, nil
+	}
+
+	var validations []validation
+	if err := json.Unmarshal([]byte(validationsJSON), &validations); err != nil {
+		return nil, zerrors.NewMissingRequiredError("could not parse %v annotation of template %v: %v", validationsAnnotation, template.Name, err.Error())
+	}
+
+	return validations, nil
+}
+
+// ValidateVM evaluates validations of the template against the VM and returns all violations.
+// Violations of rules marked as justWarning are only logged.
+func ValidateVM(template *templatev1.Template, vm *kubevirtv1.VirtualMachine) error {
+	validations, err := getValidations(template)
+	if err != nil || len(validations) == 0 {
+		return err
+	}
+
+	var vmiTemplate map[string]interface{}
+	if vm.Spec.Template != nil {
+		if vmiTemplate, err = runtime.DefaultUnstructuredConverter.ToUnstructured(vm.Spec.Template); err != nil {
+			return err
+		}
+	}
+
+	var validationErrors zerrors.MultiError
+	for i, rule := range validations {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("validation-%v", i)
+		}
+
+		if err := rule.validate(vmiTemplate); err != nil {
+			if rule.JustWarning {
+				log.Logger().Warn("VM does not satisfy template validation", zap.String("name", name), zap.Error(err))
+				continue
+			}
+			validationErrors.Add(name, zerrors.NewSoftError("%v: %v", name, err.Error()))
+		}
+	}
+
+	return validationErrors.AsOptional()
+}
+
+func (v *validation) validate(vmiTemplate map[string]interface{}) error {
+	if v.Valid != "" {
+		validValues, err := evaluatePath(v.Valid, vmiTemplate)
+		if err != nil {
+			return err
+		}
+		if len(validValues) == 0 {
+			// rule is not applicable
+			return nil
+		}
+	}
+
+	values, err := evaluatePath(v.Path, vmiTemplate)
+	if err != nil {
+		return err
+	}
+
+	for _, value := range values {
+		if err := v.validateValue(value); err != nil {
+			if v.Message != "" {
+				return fmt.Errorf("%v (%v)", v.Message, err.Error())
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (v *validation) validateValue(value interface{}) error {
+	switch v.Rule {
+	case integerRule:
+		intValue, err := toInt64(value)
+		if err != nil {
+			return fmt.Errorf("value %v of %v is not an integer", value, v.Path)
+		}
+		if v.Min != nil {
+			min, err := toInt64(v.Min)
+			if err != nil {
+				return fmt.Errorf("invalid min %v: %v", v.Min, err.Error())
+			}
+			if intValue < min {
+				return fmt.Errorf("value %v of %v is lower than minimal value %v", value, v.Path, v.Min)
+			}
+		}
+		if v.Max != nil {
+			max, err := toInt64(v.Max)
+			if err != nil {
+				return fmt.Errorf("invalid max %v: %v", v.Max, err.Error())
+			}
+			if intValue > max {
+				return fmt.Errorf("value %v of %v is greater than maximal value %v", value, v.Path, v.Max)
+			}
+		}
+	case enumRule:
+		stringValue := fmt.Sprint(value)
+		for _, allowedValue := range v.Values {
+			if stringValue == allowedValue {
+				return nil
+			}
+		}
+		return fmt.Errorf("value %v of %v is not one of %v", value, v.Path, v.Values)
+	case regexRule:
+		exp, err := regexp.Compile(v.Regex)
+		if err != nil {
+			return fmt.Errorf("invalid regex %v: %v", v.Regex, err.Error())
+		}
+		if !exp.MatchString(fmt.Sprint(value)) {
+			return fmt.Errorf("value %v of %v does not match %v", value, v.Path, v.Regex)
+		}
+	default:
+		return fmt.Errorf("unsupported rule %v", v.Rule)
+	}
+
+	return nil
+}
+
+func evaluatePath(path string, object map[string]interface{}) ([]interface{}, error) {
+	if !strings.HasPrefix(path, jsonPathPrefix) {
+		return nil, fmt.Errorf("unsupported path %v, only %v paths are supported", path, jsonPathPrefix)
+	}
+
+	parser := jsonpath.New(path).AllowMissingKeys(true)
+	if err := parser.Parse("{" + strings.TrimPrefix(path, jsonPathPrefix) + "}"); err != nil {
+		return nil, fmt.Errorf("invalid path %v: %v", path, err.Error())
+	}
+
+	if object == nil {
+		return nil, nil
+	}
+
+	results, err := parser.FindResults(object)
+	if err != nil {
+		return nil, fmt.Errorf("could not evaluate path %v: %v", path, err.Error())
+	}
+
+	var values []interface{}
+	for _, result := range results {
+		for _, value := range result {
+			if value.IsValid() && value.CanInterface() {
+				values = append(values, value.Interface())
+			}
+		}
+	}
+
+	return values, nil
+}
+
+// toInt64 converts numbers and quantities like 1Gi to int64
+func toInt64(value interface{}) (int64, error) {
+	switch typedValue := value.(type) {
+	case json.Number:
+		return typedValue.Int64()
+	case string:
+		quantity, err := resource.ParseQuantity(typedValue)
+		if err != nil {
+			return 0, err
+		}
+		return quantity.Value(), nil
+	}
+
+	reflectValue := reflect.ValueOf(value)
+	switch reflectValue.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return reflectValue.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(reflectValue.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		if floatValue := reflectValue.Float(); floatValue == float64(int64(floatValue)) {
+			return int64(floatValue), nil
+		}
+	}
+
+	return 0, fmt.Errorf("%v is not an integer", value)
+}
@@ -0,0 +1,157 @@
+package templates_test
+
+import (
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zutils"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/sharedtest/testobjects/template"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	templatev1 "github.com/openshift/api/template/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/templates"
+)
+
+var _ = Describe("Template processor", func() {
+	var testTemplate *templatev1.Template
+
+	BeforeEach(func() {
+		testTemplate = template.NewFedoraServerTinyTemplate().Build()
+	})
+
+	It("substitutes provided and generated params", func() {
+		processedTemplate, err := templates.ProcessTemplate(testTemplate, map[string]string{
+			"PVCNAME": "my-pvc",
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		vm, _, err := zutils.DecodeVM(processedTemplate)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(vm.Name).To(MatchRegexp("^fedora-[a-z0-9]{16}$"))
+		Expect(vm.Labels).To(HaveKeyWithValue("app", vm.Name))
+		Expect(vm.Spec.Template.ObjectMeta.Labels).To(HaveKeyWithValue("kubevirt.io/domain", vm.Name))
+		Expect(vm.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName).To(Equal("my-pvc"))
+		Expect(processedTemplate.Namespace).To(Equal(testTemplate.Namespace))
+	})
+
+	It("does not modify the original template", func() {
+		original := testTemplate.DeepCopy()
+		_, err := templates.ProcessTemplate(testTemplate, map[string]string{
+			"NAME":    "my-vm",
+			"PVCNAME": "my-pvc",
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(testTemplate).To(Equal(original))
+	})
+
+	It("provided params take precedence over generated ones", func() {
+		processedTemplate, err := templates.ProcessTemplate(testTemplate, map[string]string{
+			"NAME":    "my-vm",
+			"PVCNAME": "my-pvc",
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		vm, _, err := zutils.DecodeVM(processedTemplate)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(vm.Name).To(Equal("my-vm"))
+	})
+
+	It("substitutes non-string params", func() {
+		testTemplate.Parameters = append(testTemplate.Parameters, templatev1.Parameter{
+			Name:  "RUNNING",
+			Value: "true",
+		}, templatev1.Parameter{
+			Name:  "SOCKETS",
+			Value: "2",
+		})
+		testTemplate.Objects[0] = runtime.RawExtension{
+			Raw: []byte(`{"apiVersion":"kubevirt.io/v1","kind":"VirtualMachine","metadata":{"name":"${NAME}"},` +
+				`"spec":{"running":"${{RUNNING}}","template":{"spec":{"domain":{"cpu":{"sockets":"${{SOCKETS}}"},"devices":{}}}}}}`),
+		}
+
+		processedTemplate, err := templates.ProcessTemplate(testTemplate, map[string]string{
+			"PVCNAME": "my-pvc",
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		vm, _, err := zutils.DecodeVM(processedTemplate)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(*vm.Spec.Running).To(BeTrue())
+		Expect(vm.Spec.Template.Spec.Domain.CPU.Sockets).To(Equal(uint32(2)))
+	})
+
+	It("substitutes non-string params embedded in strings", func() {
+		testTemplate.Parameters = append(testTemplate.Parameters, templatev1.Parameter{
+			Name:  "SOCKETS",
+			Value: "2",
+		})
+		testTemplate.Objects[0] = runtime.RawExtension{
+			Raw: []byte(`{"apiVersion":"kubevirt.io/v1","kind":"VirtualMachine","metadata":{"name":"${NAME}",` +
+				`"annotations":{"description":"${{SOCKETS}} sockets of ${NAME}","${{SOCKETS}}-sockets":"${{UNKNOWN}}"}}}`),
+		}
+
+		processedTemplate, err := templates.ProcessTemplate(testTemplate, map[string]string{
+			"NAME":    "my-vm",
+			"PVCNAME": "my-pvc",
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		vm, _, err := zutils.DecodeVM(processedTemplate)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(vm.Annotations).To(HaveKeyWithValue("description", "2 sockets of my-vm"))
+		Expect(vm.Annotations).To(HaveKeyWithValue("2-sockets", "${{UNKNOWN}}"))
+	})
+
+	It("adds object labels", func() {
+		testTemplate.ObjectLabels = map[string]string{
+			"owner": "${PVCNAME}",
+		}
+
+		processedTemplate, err := templates.ProcessTemplate(testTemplate, map[string]string{
+			"PVCNAME": "my-pvc",
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		vm, _, err := zutils.DecodeVM(processedTemplate)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(vm.Labels).To(HaveKeyWithValue("owner", "my-pvc"))
+		Expect(vm.Labels).To(HaveKeyWithValue("vm.kubevirt.io/template", "fedora-server-tiny"))
+	})
+
+	DescribeTable("fails on invalid params", func(paramValues map[string]string, expectedErrMessage string) {
+		_, err := templates.ProcessTemplate(testTemplate, paramValues)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(expectedErrMessage))
+	},
+		Entry("missing required param", map[string]string{}, "template params could not be resolved: PVCNAME"),
+		Entry("unknown param", map[string]string{"PVCNAME": "my-pvc", "UNKNOWN": "value"}, "unknown template params: [UNKNOWN]"),
+	)
+
+	DescribeTable("generates values", func(expression string, expectedPattern string) {
+		value, err := templates.GenerateValue(expression)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(value).To(MatchRegexp(expectedPattern))
+	},
+		Entry("lowercase and numerals", "fedora-[a-z0-9]{16}", "^fedora-[a-z0-9]{16}$"),
+		Entry("uppercase", "[A-Z]{4}", "^[A-Z]{4}$"),
+		Entry("word class", `[\w]{10}`, `^\w{10}$`),
+		Entry("digit class", `[\d]{3}`, `^\d{3}$`),
+		Entry("letters class", `[\a]{5}`, "^[a-zA-Z]{5}$"),
+		Entry("symbols class", `[\A]{5}`, "^[^a-zA-Z0-9]{5}$"),
+		Entry("single characters", "[xy]{6}", "^[xy]{6}$"),
+		Entry("multiple generators", "[a-f]{2}-[0-3]{2}", "^[a-f]{2}-[0-3]{2}$"),
+		Entry("no generator", "static-value", "^static-value$"),
+	)
+
+	DescribeTable("fails on invalid expressions", func(expression string, expectedErrMessage string) {
+		_, err := templates.GenerateValue(expression)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(expectedErrMessage))
+	},
+		Entry("too long", "[a-z]{256}", "must be within [1-255] characters"),
+		Entry("zero length", "[a-z]{0}", "must be within [1-255] characters"),
+		Entry("invalid length", "[a-z]{abc}", "invalid length abc"),
+		Entry("reversed range", "[z-a]{4}", "invalid range z-a"),
+		Entry("unknown class", `[\x]{4}`, `unknown class \x`),
+	)
+})
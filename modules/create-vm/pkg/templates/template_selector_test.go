@@ -0,0 +1,67 @@
+package templates_test
+
+import (
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/templates"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	templatev1 "github.com/openshift/api/template/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTemplate(name, version string, deprecated bool) templatev1.Template {
+	template := templatev1.Template{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Labels:      map[string]string{},
+			Annotations: map[string]string{},
+		},
+	}
+	if version != "" {
+		template.Labels["template.kubevirt.io/version"] = version
+	}
+	if deprecated {
+		template.Annotations["template.kubevirt.io/deprecated"] = "true"
+	}
+	return template
+}
+
+var _ = Describe("Template selector", func() {
+	DescribeTable("selects the newest template", func(candidates []templatev1.Template, expectedName string) {
+		template, err := templates.SelectTemplate(candidates)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(template.Name).To(Equal(expectedName))
+	},
+		Entry("single template", []templatev1.Template{
+			newTemplate("fedora-server-tiny", "v0.7.0", false),
+		}, "fedora-server-tiny"),
+		Entry("newest version", []templatev1.Template{
+			newTemplate("fedora-server-tiny-v0.9.0", "v0.9.0", false),
+			newTemplate("fedora-server-tiny-v0.10.1", "v0.10.1", false),
+			newTemplate("fedora-server-tiny-v0.10.0", "v0.10.0", false),
+		}, "fedora-server-tiny-v0.10.1"),
+		Entry("skips deprecated templates", []templatev1.Template{
+			newTemplate("fedora-server-tiny-v0.9.0", "v0.9.0", false),
+			newTemplate("fedora-server-tiny-v0.10.0", "v0.10.0", true),
+		}, "fedora-server-tiny-v0.9.0"),
+		Entry("templates without version are the oldest", []templatev1.Template{
+			newTemplate("fedora-server-tiny", "", false),
+			newTemplate("fedora-server-tiny-v0.1.0", "v0.1.0", false),
+		}, "fedora-server-tiny-v0.1.0"),
+		Entry("same versions are ordered by name", []templatev1.Template{
+			newTemplate("fedora-server-small", "v0.7.0", false),
+			newTemplate("fedora-server-tiny", "v0.7.0", false),
+			newTemplate("fedora-server-medium", "v0.7.0", false),
+		}, "fedora-server-tiny"),
+	)
+
+	DescribeTable("fails when there is no template to select", func(candidates []templatev1.Template) {
+		_, err := templates.SelectTemplate(candidates)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(Equal("no matching template found"))
+	},
+		Entry("no templates", nil),
+		Entry("only deprecated templates", []templatev1.Template{
+			newTemplate("fedora-server-tiny", "v0.7.0", true),
+		}),
+	)
+})
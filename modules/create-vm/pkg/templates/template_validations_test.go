@@ -0,0 +1,103 @@
+package templates_test
+
+import (
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zutils"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/sharedtest/testobjects/template"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	templatev1 "github.com/openshift/api/template/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/templates"
+)
+
+func processVM(testTemplate *templatev1.Template) *kubevirtv1.VirtualMachine {
+	processedTemplate, err := templates.ProcessTemplate(testTemplate, map[string]string{
+		"PVCNAME": "my-pvc",
+	})
+	Expect(err).ShouldNot(HaveOccurred())
+
+	vm, _, err := zutils.DecodeVM(processedTemplate)
+	Expect(err).ShouldNot(HaveOccurred())
+	return vm
+}
+
+var _ = Describe("Template validations", func() {
+	DescribeTable("VM satisfies validations", func(testTemplate *templatev1.Template) {
+		Expect(templates.ValidateVM(testTemplate, processVM(testTemplate))).To(Succeed())
+	},
+		Entry("minimal memory", template.NewFedoraServerTinyTemplate().Build()),
+		Entry("enum", template.NewFedoraServerTinyTemplate().WithVirtioDiskValidations().Build()),
+		Entry("violated warning", template.NewFedoraServerTinyTemplate().WithSCSIDiskValidations().Build()),
+	)
+
+	DescribeTable("VM violates validations", func(testTemplate *templatev1.Template, validations string, expectedErrMessages ...string) {
+		if validations != "" {
+			testTemplate.Annotations["validations"] = validations
+		}
+
+		err := templates.ValidateVM(testTemplate, processVM(testTemplate))
+		Expect(err).Should(HaveOccurred())
+		for _, expectedErrMessage := range expectedErrMessages {
+			Expect(err.Error()).To(ContainSubstring(expectedErrMessage))
+		}
+	},
+		Entry("enum", template.NewFedoraServerTinyTemplate().WithSataDiskValidations().Build(), "",
+			"disk-bus: value virtio of jsonpath::.spec.domain.devices.disks[*].disk.bus is not one of [sata]"),
+		Entry("integer min", template.NewFedoraServerTinyTemplate().Build(), `[{
+			"name": "minimal-required-memory",
+			"path": "jsonpath::.spec.domain.resources.requests.memory",
+			"rule": "integer",
+			"message": "This VM requires more memory.",
+			"min": "2Gi"
+		}]`, "minimal-required-memory: This VM requires more memory. (value 1Gi of jsonpath::.spec.domain.resources.requests.memory is lower than minimal value 2Gi)"),
+		Entry("integer max", template.NewFedoraServerTinyTemplate().Build(), `[{
+			"name": "maximal-cores",
+			"path": "jsonpath::.spec.domain.cpu.cores",
+			"rule": "integer",
+			"min": 0,
+			"max": 0
+		}]`, "maximal-cores: value 1 of jsonpath::.spec.domain.cpu.cores is greater than maximal value 0"),
+		Entry("regex", template.NewFedoraServerTinyTemplate().Build(), `[{
+			"name": "network-name",
+			"path": "jsonpath::.spec.networks[*].name",
+			"rule": "regex",
+			"regex": "^net-"
+		}]`, "network-name: value default of jsonpath::.spec.networks[*].name does not match ^net-"),
+		Entry("multiple violations", template.NewFedoraServerTinyTemplate().Build(), `[{
+			"name": "disk-bus",
+			"path": "jsonpath::.spec.domain.devices.disks[*].disk.bus",
+			"rule": "enum",
+			"values": ["sata"]
+		}, {
+			"name": "network-name",
+			"path": "jsonpath::.spec.networks[*].name",
+			"rule": "regex",
+			"regex": "^net-"
+		}]`, "disk-bus: value virtio", "network-name: value default"),
+		Entry("unsupported rule", template.NewFedoraServerTinyTemplate().Build(), `[{
+			"name": "length",
+			"path": "jsonpath::.spec.networks[*].name",
+			"rule": "string"
+		}]`, "length: unsupported rule string"),
+		Entry("invalid annotation", template.NewFedoraServerTinyTemplate().Build(), `{`, "could not parse validations annotation"),
+	)
+
+	It("VM without validations is valid", func() {
+		testTemplate := template.NewFedoraServerTinyTemplate().Build()
+		delete(testTemplate.Annotations, "validations")
+		Expect(templates.ValidateVM(testTemplate, processVM(testTemplate))).To(Succeed())
+	})
+
+	It("skips rules which are not valid for the VM", func() {
+		testTemplate := template.NewFedoraServerTinyTemplate().Build()
+		testTemplate.Annotations["validations"] = `[{
+			"name": "gpu-name",
+			"path": "jsonpath::.spec.domain.devices.gpus[*].name",
+			"valid": "jsonpath::.spec.domain.devices.gpus",
+			"rule": "regex",
+			"regex": "^gpu-"
+		}]`
+		Expect(templates.ValidateVM(testTemplate, processVM(testTemplate))).To(Succeed())
+	})
+})
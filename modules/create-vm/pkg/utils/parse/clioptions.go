@@ -2,43 +2,274 @@ package parse
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/bundle"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/constants"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/datavolume"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/service"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/vm"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/output"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/overrides"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/ownerref"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zerrors"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zutils"
 	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 const (
-	vmManifestOptionName        = "vm-manifest"
-	vmNamespaceOptionName       = "vm-namespace"
-	templateNameOptionName      = "template-name"
-	templateNamespaceOptionName = "template-namespace"
-	templateParamsOptionName    = "template-params"
-	virtctlOptionName           = "virtctl"
+	vmManifestOptionName          = "vm-manifest"
+	vmNamespaceOptionName         = "vm-namespace"
+	templateNameOptionName        = "template-name"
+	templateNamespaceOptionName   = "template-namespace"
+	templateParamsOptionName      = "template-params"
+	templateSelectorOptionName    = "template-selector"
+	virtctlOptionName             = "virtctl"
+	instancetypeOptionName        = "instancetype"
+	instancetypeKindOptionName    = "instancetype-kind"
+	preferenceOptionName          = "preference"
+	preferenceKindOptionName      = "preference-kind"
+	vmNameOptionName              = "vm-name"
+	dataSourceNameOptionName      = "datasource-name"
+	dataSourceNamespaceOptionName = "datasource-namespace"
+	diskSizeOptionName            = "disk-size"
+	vmSnapshotOptionName          = "vm-snapshot"
+	cloneSourceVMOptionName       = "clone-source-vm"
+	cloneLabelFiltersOptionName   = "clone-label-filters"
+	cloneAnnotationFiltersName    = "clone-annotation-filters"
+	cloneNewMacAddressesName      = "clone-new-mac-addresses"
+	dryRunOptionName              = "dry-run"
+	runStrategyOptionName         = "run-strategy"
+	waitForReadyOptionName        = "wait-for-ready"
+	waitTimeoutOptionName         = "wait-timeout"
+	waitForDataVolumesOptionName  = "wait-for-datavolumes"
+	countOptionName               = "count"
+	parallelismOptionName         = "parallelism"
+	servicePortsOptionName        = "service-ports"
+	serviceTypeOptionName         = "service-type"
+	sshPublicKeySecretOptionName  = "ssh-public-key-secret"
+	sshPropagationOptionName      = "ssh-propagation-method"
+	sshUsersOptionName            = "ssh-users"
+	userDataSecretOptionName      = "cloud-init-user-data-secret"
+	networkDataSecretOptionName   = "cloud-init-network-data-secret"
+	cpuSocketsOptionName          = "cpu-sockets"
+	cpuCoresOptionName            = "cpu-cores"
+	cpuThreadsOptionName          = "cpu-threads"
+	memoryRequestOptionName       = "memory-request"
+	memoryLimitOptionName         = "memory-limit"
+	nodeSelectorOptionName        = "node-selector"
+	tolerationsOptionName         = "tolerations"
+	affinityOptionName            = "affinity"
+	evictionStrategyOptionName    = "eviction-strategy"
+	priorityClassNameOptionName   = "priority-class-name"
+	dvTemplateNameOptionName      = "dv-template-name"
+	sourceDataSourceOptionName    = "source-datasource"
+	sourcePVCOptionName           = "source-pvc"
+	sourceSnapshotOptionName      = "source-snapshot"
+	storageClassOptionName        = "storage-class"
+	accessModesOptionName         = "access-modes"
+	volumeModeOptionName          = "volume-mode"
+	storageSizeOptionName         = "storage-size"
+)
+
+const (
+	osSelectorKey           = "os"
+	workloadSelectorKey     = "workload"
+	flavorSelectorKey       = "flavor"
+	architectureSelectorKey = "architecture"
 )
 
 const templateParamSep = ":"
+const templateSelectorSep = ","
+const templateSelectorKeyValueSep = "="
 const volumesSep = ":"
+const sshUsersSep = ","
+const accessModesSep = ","
+const namespaceNameSep = "/"
+const cloneFiltersSep = ","
+const macAddressesSep = ","
+const macAddressKeyValueSep = "="
 
 type CLIOptions struct {
 	TemplateName            string            `arg:"--template-name,env:TEMPLATE_NAME" placeholder:"NAME" help:"Name of a template to create VM from"`
 	TemplateNamespace       string            `arg:"--template-namespace,env:TEMPLATE_NAMESPACE" placeholder:"NAMESPACE" help:"Namespace of a template to create VM from"`
 	TemplateParams          []string          `arg:"--template-params" placeholder:"KEY1:VAL1 KEY2:VAL2" help:"Template params to pass when processing the template manifest"`
+	TemplateSelector        string            `arg:"--template-selector,env:TEMPLATE_SELECTOR" placeholder:"KEY1=VAL1,KEY2=VAL2" help:"Select the newest non deprecated template by labels instead of by name. Supports os, workload, flavor and architecture keys (eg os=fedora,workload=server,flavor=small) and any other label keys."`
 	VirtualMachineManifest  string            `arg:"--vm-manifest,env:VM_MANIFEST" placeholder:"MANIFEST" help:"YAML manifest of a VirtualMachine resource to be created (can be set by VM_MANIFEST env variable)."`
 	VirtualMachineNamespace string            `arg:"--vm-namespace,env:VM_NAMESPACE" placeholder:"NAMESPACE" help:"Namespace where to create the VM"`
 	StartVM                 string            `arg:"--start-vm,env:START_VM" help:"Start vm after creation"`
 	RunStrategy             string            `arg:"--run-strategy,env:RUN_STRATEGY" help:"Set run strategy to vm"`
+	WaitForReady            string            `arg:"--wait-for-ready,env:WAIT_FOR_READY" help:"Wait until the VMI is running, has a connected guest agent and reports an IP address"`
+	RollbackOnFailure       string            `arg:"--rollback-on-failure,env:ROLLBACK_ON_FAILURE" help:"Delete the VM and all objects created by this task if any step fails"`
+	WaitForDataVolumes      string            `arg:"--wait-for-datavolumes,env:WAIT_FOR_DATAVOLUMES" help:"Wait until all data volumes of the VM are imported or cloned and log their progress. Data volumes with WaitForFirstConsumer binding mode finish only when the VM is started."`
+	WaitTimeout             string            `arg:"--wait-timeout,env:WAIT_TIMEOUT" placeholder:"TIMEOUT" help:"Timeout for waiting for the restore or the clone, for the data volumes and for the VMI to be ready. Should be in a 3h2m1s format (defaults to 1h)."`
+	Count                   string            `arg:"--count,env:COUNT" placeholder:"COUNT" help:"Number of VMs to create. VM names are derived from the name or generateName of the VM with the index as a suffix (defaults to 1)."`
+	Parallelism             string            `arg:"--parallelism,env:PARALLELISM" placeholder:"PARALLELISM" help:"Maximum number of VMs created at once when count is greater than 1 (defaults to 5)."`
+	ServicePorts            []string          `arg:"--service-ports" placeholder:"NAME1:PORT1/PROTOCOL1 PORT2" help:"Create a service named after the VM exposing these ports of the VM. Each port should have [NAME:]PORT[/PROTOCOL] format. Protocol is one of: TCP|UDP|SCTP (defaults to TCP)."`
+	ServiceType             string            `arg:"--service-type,env:SERVICE_TYPE" placeholder:"TYPE" help:"Type of the service. One of: ClusterIP|NodePort|LoadBalancer (defaults to ClusterIP)."`
+	SSHPublicKeySecret      string            `arg:"--ssh-public-key-secret,env:SSH_PUBLIC_KEY_SECRET" placeholder:"NAME" help:"Name of a secret with public SSH keys to add to access credentials of the VM"`
+	SSHPropagationMethod    string            `arg:"--ssh-propagation-method,env:SSH_PROPAGATION_METHOD" placeholder:"METHOD" help:"Method to propagate the public SSH keys to the guest. One of: configDrive|qemuGuestAgent (defaults to configDrive). A cloudInitConfigDrive volume is added for configDrive when missing. noCloud is not supported by this KubeVirt API version."`
+	SSHUsers                string            `arg:"--ssh-users,env:SSH_USERS" placeholder:"USER1,USER2" help:"Guest users to propagate the public SSH keys to. Required for qemuGuestAgent propagation method."`
+	UserDataSecret          string            `arg:"--cloud-init-user-data-secret,env:CLOUD_INIT_USER_DATA_SECRET" placeholder:"NAME" help:"Name of a secret with cloud-init user data to use in the cloud-init volume of the VM. The volume is added when missing."`
+	NetworkDataSecret       string            `arg:"--cloud-init-network-data-secret,env:CLOUD_INIT_NETWORK_DATA_SECRET" placeholder:"NAME" help:"Name of a secret with cloud-init network data to use in the cloud-init volume of the VM. The volume is added when missing."`
+	CPUSockets              string            `arg:"--cpu-sockets,env:CPU_SOCKETS" placeholder:"CPU_SOCKETS" help:"Override number of CPU sockets of the VM"`
+	CPUCores                string            `arg:"--cpu-cores,env:CPU_CORES" placeholder:"CPU_CORES" help:"Override number of CPU cores of the VM"`
+	CPUThreads              string            `arg:"--cpu-threads,env:CPU_THREADS" placeholder:"CPU_THREADS" help:"Override number of CPU threads of the VM"`
+	MemoryRequest           string            `arg:"--memory-request,env:MEMORY_REQUEST" placeholder:"MEMORY" help:"Override memory request of the VM, format 1Gi"`
+	MemoryLimit             string            `arg:"--memory-limit,env:MEMORY_LIMIT" placeholder:"MEMORY" help:"Override memory limit of the VM, format 1Gi"`
+	NodeSelector            string            `arg:"--node-selector,env:NODE_SELECTOR" placeholder:"KEY1=VAL1,KEY2=VAL2" help:"Labels to add to the node selector of the VM"`
+	Tolerations             string            `arg:"--tolerations,env:TOLERATIONS" placeholder:"TOLERATIONS" help:"YAML or JSON list of tolerations to replace tolerations of the VM"`
+	Affinity                string            `arg:"--affinity,env:AFFINITY" placeholder:"AFFINITY" help:"YAML or JSON affinity to replace affinity of the VM"`
+	EvictionStrategy        string            `arg:"--eviction-strategy,env:EVICTION_STRATEGY" placeholder:"STRATEGY" help:"Override eviction strategy of the VM. One of: None|LiveMigrate|LiveMigrateIfPossible|External"`
+	PriorityClassName       string            `arg:"--priority-class-name,env:PRIORITY_CLASS_NAME" placeholder:"NAME" help:"Override priority class name of the VM"`
+	DataVolumeTemplateName  string            `arg:"--dv-template-name,env:DV_TEMPLATE_NAME" placeholder:"NAME" help:"Name of a data volume template of the VM to override by the source and storage options (defaults to the only data volume template of the VM)"`
+	SourceDataSource        string            `arg:"--source-datasource,env:SOURCE_DATASOURCE" placeholder:"[NAMESPACE/]NAME" help:"DataSource to set as a source ref of the data volume template. The namespace of the current source ref is kept if not specified."`
+	SourcePVC               string            `arg:"--source-pvc,env:SOURCE_PVC" placeholder:"[NAMESPACE/]NAME" help:"PVC to clone the data volume template from (namespace defaults to the namespace of the VM)"`
+	SourceSnapshot          string            `arg:"--source-snapshot,env:SOURCE_SNAPSHOT" placeholder:"[NAMESPACE/]NAME" help:"VolumeSnapshot to clone the data volume template from (namespace defaults to the namespace of the VM)"`
+	StorageClass            string            `arg:"--storage-class,env:STORAGE_CLASS" placeholder:"NAME" help:"Override storage class of the data volume template"`
+	AccessModes             string            `arg:"--access-modes,env:ACCESS_MODES" placeholder:"MODE1,MODE2" help:"Override access modes of the data volume template. Each mode is one of: ReadWriteOnce|ReadOnlyMany|ReadWriteMany|ReadWriteOncePod"`
+	VolumeMode              string            `arg:"--volume-mode,env:VOLUME_MODE" placeholder:"MODE" help:"Override volume mode of the data volume template. One of: Filesystem|Block"`
+	StorageSize             string            `arg:"--storage-size,env:STORAGE_SIZE" placeholder:"SIZE" help:"Override requested size of the data volume template, format 1Gi. Should not be smaller than the size of the source."`
 	Output                  output.OutputType `arg:"-o" placeholder:"FORMAT" help:"Output format. One of: yaml|json"`
 	Debug                   bool              `arg:"--debug" help:"Sets DEBUG log level"`
-	Virtctl                 string            `arg:"--virtctl,env:VIRTCTL" placeholder:"VIRTCTL" help:"Specifies the parameters for virtctl create vm command that will be used to create VirtualMachine."`
+	DryRun                  string            `arg:"--dry-run,env:DRY_RUN" placeholder:"STRATEGY" help:"Do not persist the VM. One of: client|server. The client strategy only prints the VM, the server strategy also submits it to the server for validation."`
+	Virtctl                 string            `arg:"--virtctl,env:VIRTCTL" placeholder:"VIRTCTL" help:"Flags of virtctl create vm command that will be used to create VirtualMachine. Either a YAML or JSON list with one argument per item or a string with arguments quoted as in a shell."`
+	Instancetype            string            `arg:"--instancetype,env:INSTANCETYPE" placeholder:"NAME" help:"Name of an instancetype to create VM from"`
+	InstancetypeKind        string            `arg:"--instancetype-kind,env:INSTANCETYPE_KIND" placeholder:"KIND" help:"Kind of an instancetype. One of: VirtualMachineInstancetype|VirtualMachineClusterInstancetype (defaults to VirtualMachineClusterInstancetype)"`
+	Preference              string            `arg:"--preference,env:PREFERENCE" placeholder:"NAME" help:"Name of a preference to create VM with"`
+	PreferenceKind          string            `arg:"--preference-kind,env:PREFERENCE_KIND" placeholder:"KIND" help:"Kind of a preference. One of: VirtualMachinePreference|VirtualMachineClusterPreference (defaults to VirtualMachineClusterPreference)"`
+	VirtualMachineName      string            `arg:"--vm-name,env:VM_NAME" placeholder:"NAME" help:"Name of the VM to create from an instancetype or a VM snapshot, or name of the clone of a VM (a name is generated for the clone if not specified)"`
+	DataSourceName          string            `arg:"--datasource-name,env:DATASOURCE_NAME" placeholder:"NAME" help:"Name of a DataSource to clone the boot disk of a VM created from an instancetype from"`
+	DataSourceNamespace     string            `arg:"--datasource-namespace,env:DATASOURCE_NAMESPACE" placeholder:"NAMESPACE" help:"Namespace of a DataSource to clone the boot disk from (defaults to vm-namespace)"`
+	DiskSize                string            `arg:"--disk-size,env:DISK_SIZE" placeholder:"SIZE" help:"Size of the boot disk of a VM created from an instancetype, format 1Gi (defaults to the size of the DataSource)"`
+	VirtualMachineSnapshot  string            `arg:"--vm-snapshot,env:VM_SNAPSHOT" placeholder:"NAME" help:"Name of a VirtualMachineSnapshot to restore into a new VM with the vm-name name"`
+	CloneSourceVM           string            `arg:"--clone-source-vm,env:CLONE_SOURCE_VM" placeholder:"NAME" help:"Name of a VM to clone into a new VM"`
+	CloneLabelFilters       string            `arg:"--clone-label-filters,env:CLONE_LABEL_FILTERS" placeholder:"FILTER1,FILTER2" help:"Filters selecting labels of the source VM to copy to the clone. Supports wildcards and negation, eg *,!example.com/* (defaults to all labels)"`
+	CloneAnnotationFilters  string            `arg:"--clone-annotation-filters,env:CLONE_ANNOTATION_FILTERS" placeholder:"FILTER1,FILTER2" help:"Filters selecting annotations of the source VM to copy to the clone. Supports wildcards and negation, eg *,!example.com/* (defaults to all annotations)"`
+	CloneNewMacAddresses    string            `arg:"--clone-new-mac-addresses,env:CLONE_NEW_MAC_ADDRESSES" placeholder:"IFACE1=MAC1,IFACE2=MAC2" help:"MAC addresses to set to interfaces of the clone. Interfaces which are not specified get a generated MAC address."`
+	ownerref.Options
+
+	vmOverrides                 *overrides.VMOverrides        `arg:"-"`
+	dataVolumeTemplateOverrides *datavolume.TemplateOverrides `arg:"-"`
+	virtctlArgs                 []string                      `arg:"-"`
+	vmBundle                    *bundle.Bundle                `arg:"-"`
 }
 
 func (c *CLIOptions) GetStartVMFlag() bool {
 	return c.StartVM == "true"
 }
 
+func (c *CLIOptions) GetWaitForReadyFlag() bool {
+	return c.WaitForReady == "true"
+}
+
+func (c *CLIOptions) GetWaitForDataVolumesFlag() bool {
+	return c.WaitForDataVolumes == "true"
+}
+
+func (c *CLIOptions) GetRollbackOnFailureFlag() bool {
+	return c.RollbackOnFailure == "true"
+}
+
+func (c *CLIOptions) GetWaitTimeout() time.Duration {
+	if c.WaitTimeout != "" {
+		timeout, err := time.ParseDuration(c.WaitTimeout)
+		if err == nil && timeout > 0 {
+			return timeout
+		}
+	}
+
+	return constants.DefaultWaitTimeout
+}
+
+func (c *CLIOptions) GetCount() int {
+	if count, err := strconv.Atoi(c.Count); err == nil {
+		return count
+	}
+	return 1
+}
+
+func (c *CLIOptions) GetParallelism() int {
+	if parallelism, err := strconv.Atoi(c.Parallelism); err == nil {
+		return parallelism
+	}
+	return constants.DefaultParallelism
+}
+
+// GetServicePorts returns ports of the service to create or nil if no service should be created
+func (c *CLIOptions) GetServicePorts() []corev1.ServicePort {
+	result, err := service.ParsePorts(c.ServicePorts)
+
+	if err != nil {
+		panic(fmt.Errorf("init was not called: %v", err.Error()))
+	}
+	return result
+}
+
+func (c *CLIOptions) GetServiceType() corev1.ServiceType {
+	if c.ServiceType == "" {
+		return corev1.ServiceTypeClusterIP
+	}
+	return corev1.ServiceType(c.ServiceType)
+}
+
+func (c *CLIOptions) GetSSHPropagationMethod() vm.PropagationMethod {
+	if c.SSHPropagationMethod == "" {
+		return vm.ConfigDrivePropagation
+	}
+	return vm.PropagationMethod(c.SSHPropagationMethod)
+}
+
+func (c *CLIOptions) GetSSHUsers() []string {
+	var result []string
+	for _, user := range strings.Split(c.SSHUsers, sshUsersSep) {
+		if user = strings.TrimSpace(user); user != "" {
+			result = append(result, user)
+		}
+	}
+	return result
+}
+
+// GetVMOverrides returns values to set to the VM. Init has to be called first.
+func (c *CLIOptions) GetVMOverrides() *overrides.VMOverrides {
+	if c.vmOverrides == nil {
+		panic(fmt.Errorf("init was not called"))
+	}
+	return c.vmOverrides
+}
+
+// GetDataVolumeTemplateOverrides returns values to set to a data volume template of the VM or nil if no data volume template should be overridden
+func (c *CLIOptions) GetDataVolumeTemplateOverrides() *datavolume.TemplateOverrides {
+	return c.dataVolumeTemplateOverrides
+}
+
+// GetVirtualMachineBundle returns the parsed VM manifest or nil if the VM is not created from a manifest
+func (c *CLIOptions) GetVirtualMachineBundle() *bundle.Bundle {
+	return c.vmBundle
+}
+
+func (c *CLIOptions) GetCloneLabelFilters() []string {
+	return splitList(c.CloneLabelFilters, cloneFiltersSep)
+}
+
+func (c *CLIOptions) GetCloneAnnotationFilters() []string {
+	return splitList(c.CloneAnnotationFilters, cloneFiltersSep)
+}
+
+// GetCloneNewMacAddresses returns MAC addresses by interface names or nil if none should be set
+func (c *CLIOptions) GetCloneNewMacAddresses() map[string]string {
+	result, err := parseMacAddresses(c.CloneNewMacAddresses)
+
+	if err != nil {
+		panic(fmt.Errorf("init was not called: %v", err.Error()))
+	}
+	return result
+}
+
 func (c *CLIOptions) GetRunStrategy() string {
 	return c.RunStrategy
 }
@@ -47,6 +278,11 @@ func (c *CLIOptions) GetVirtctl() string {
 	return c.Virtctl
 }
 
+// GetVirtctlArgs returns parsed flags of the virtctl create vm command
+func (c *CLIOptions) GetVirtctlArgs() []string {
+	return c.virtctlArgs
+}
+
 func (c *CLIOptions) GetTemplateParams() map[string]string {
 	result, err := zutils.ExtractKeysAndValuesByLastKnownKey(c.TemplateParams, templateParamSep)
 
@@ -69,7 +305,7 @@ func (c *CLIOptions) GetCreationMode() constants.CreationMode {
 		return constants.VMManifestCreationMode
 	}
 
-	if c.TemplateName != "" {
+	if c.TemplateName != "" || c.TemplateSelector != "" {
 		return constants.TemplateCreationMode
 	}
 
@@ -77,9 +313,31 @@ func (c *CLIOptions) GetCreationMode() constants.CreationMode {
 		return constants.VirtctlCreatingMode
 	}
 
+	if c.Instancetype != "" {
+		return constants.InstancetypeCreationMode
+	}
+
+	if c.VirtualMachineSnapshot != "" {
+		return constants.SnapshotCreationMode
+	}
+
+	if c.CloneSourceVM != "" {
+		return constants.CloneCreationMode
+	}
+
 	return ""
 }
 
+// GetTemplateSelector returns a label selector with os, workload, flavor and architecture keys translated to template labels
+func (c *CLIOptions) GetTemplateSelector() string {
+	result, err := parseTemplateSelector(c.TemplateSelector)
+
+	if err != nil {
+		panic(fmt.Errorf("init was not called: %v", err.Error()))
+	}
+	return result
+}
+
 func (c *CLIOptions) GetTemplateNamespace() string {
 	return c.TemplateNamespace
 }
@@ -92,6 +350,52 @@ func (c *CLIOptions) GetVirtualMachineNamespace() string {
 	return c.VirtualMachineNamespace
 }
 
+func (c *CLIOptions) GetVirtualMachineName() string {
+	return c.VirtualMachineName
+}
+
+func (c *CLIOptions) GetInstancetypeKind() string {
+	if c.InstancetypeKind == "" {
+		return constants.VirtualMachineClusterInstancetypeKind
+	}
+	return c.InstancetypeKind
+}
+
+func (c *CLIOptions) GetPreferenceKind() string {
+	if c.PreferenceKind == "" {
+		return constants.VirtualMachineClusterPreferenceKind
+	}
+	return c.PreferenceKind
+}
+
+func (c *CLIOptions) GetDataSourceNamespace() string {
+	return c.DataSourceNamespace
+}
+
+func (c *CLIOptions) GetDryRun() constants.DryRunStrategy {
+	return constants.DryRunStrategy(c.DryRun)
+}
+
+func (c *CLIOptions) IsDryRun() bool {
+	return c.GetDryRun() != constants.DryRunNone
+}
+
+func (c *CLIOptions) GetOutput() output.OutputType {
+	// dry run is pointless without seeing the result
+	if c.Output == "" && c.IsDryRun() {
+		return output.YamlOutput
+	}
+	return c.Output
+}
+
+func (c *CLIOptions) GetDiskSize() *resource.Quantity {
+	if c.DiskSize == "" {
+		return nil
+	}
+	q := resource.MustParse(c.DiskSize)
+	return &q
+}
+
 func (c *CLIOptions) Init() error {
 	if err := c.assertValidMode(); err != nil {
 		return err
@@ -111,5 +415,9 @@ func (c *CLIOptions) Init() error {
 
 	c.trimSpaces()
 
+	if err := c.Options.Init(); err != nil {
+		return err
+	}
+
 	return nil
 }
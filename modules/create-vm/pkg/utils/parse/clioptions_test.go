@@ -2,19 +2,36 @@ package parse_test
 
 import (
 	"reflect"
+	"time"
 
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/constants"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/datavolume"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/utils/parse"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/vm"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/output"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/overrides"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/ownerref"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/sharedtest/testobjects"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	cdiv1beta1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
 )
 
 var (
 	defaultNS      = "default"
 	testVMManifest = testobjects.NewTestVM().ToString()
+	diskSize       = resource.MustParse("30Gi")
+	memoryRequest  = resource.MustParse("2Gi")
+	memoryLimit    = resource.MustParse("4Gi")
+	liveMigrate    = kubevirtv1.EvictionStrategyLiveMigrate
+	storageSize    = resource.MustParse("20Gi")
+	blockMode      = corev1.PersistentVolumeBlock
 )
 
 var _ = Describe("CLIOptions", func() {
@@ -23,8 +40,8 @@ var _ = Describe("CLIOptions", func() {
 		Expect(err).Should(HaveOccurred())
 		Expect(err.Error()).To(ContainSubstring(expectedErrMessage))
 	},
-		Entry("no mode", "only one of vm-manifest, template-name or virtctl should be specified", &parse.CLIOptions{}),
-		Entry("multiple modes", "only one of vm-manifest, template-name or virtctl should be specified", &parse.CLIOptions{
+		Entry("no mode", "only one of vm-manifest, template-name, virtctl, instancetype, vm-snapshot or clone-source-vm should be specified", &parse.CLIOptions{}),
+		Entry("multiple modes", "only one of vm-manifest, template-name, virtctl, instancetype, vm-snapshot or clone-source-vm should be specified", &parse.CLIOptions{
 			TemplateName:           "test",
 			VirtualMachineManifest: testVMManifest,
 		}),
@@ -51,6 +68,276 @@ var _ = Describe("CLIOptions", func() {
 			TemplateName:   "test",
 			TemplateParams: []string{":V1"},
 		}),
+		Entry("invalid dry run", "invalid is not a valid dry-run strategy, only client|server is allowed", &parse.CLIOptions{
+			TemplateName: "test",
+			DryRun:       "invalid",
+		}),
+		Entry("invalid wait timeout", "could not parse wait-timeout", &parse.CLIOptions{
+			TemplateName: "test",
+			WaitTimeout:  "5 minutes",
+		}),
+		Entry("non positive wait timeout", "wait-timeout should be positive", &parse.CLIOptions{
+			TemplateName: "test",
+			WaitTimeout:  "0s",
+		}),
+		Entry("wait for data volumes with dry run", "wait-for-datavolumes option is not applicable for dry-run", &parse.CLIOptions{
+			TemplateName:       "test",
+			WaitForDataVolumes: "true",
+			DryRun:             "server",
+		}),
+		Entry("wait for ready with dry run", "wait-for-ready option is not applicable for dry-run", &parse.CLIOptions{
+			TemplateName: "test",
+			WaitForReady: "true",
+			DryRun:       "client",
+		}),
+		Entry("instancetype and template", "only one of vm-manifest, template-name, virtctl, instancetype, vm-snapshot or clone-source-vm should be specified", &parse.CLIOptions{
+			TemplateName: "test",
+			Instancetype: "u1.small",
+		}),
+		Entry("useless template ns with instancetype", "template-namespace, template-params options are not applicable for instancetype", &parse.CLIOptions{
+			Instancetype:      "u1.small",
+			TemplateNamespace: defaultNS,
+		}),
+		Entry("instancetype options with manifest", "instancetype-kind, preference, preference-kind, datasource-name, datasource-namespace, disk-size options are applicable only for instancetype", &parse.CLIOptions{
+			VirtualMachineManifest: testVMManifest,
+			Preference:             "fedora",
+		}),
+		Entry("missing vm name", "vm-name option is required for instancetype", &parse.CLIOptions{
+			Instancetype:   "u1.small",
+			DataSourceName: "fedora",
+		}),
+		Entry("invalid vm name", "vm-name is not a valid name", &parse.CLIOptions{
+			Instancetype:       "u1.small",
+			VirtualMachineName: "Invalid_Name",
+			DataSourceName:     "fedora",
+		}),
+		Entry("missing datasource name", "datasource-name option is required for instancetype", &parse.CLIOptions{
+			Instancetype:       "u1.small",
+			VirtualMachineName: "my-vm",
+		}),
+		Entry("invalid instancetype kind", "Flavor is not a valid instancetype-kind", &parse.CLIOptions{
+			Instancetype:       "u1.small",
+			InstancetypeKind:   "Flavor",
+			VirtualMachineName: "my-vm",
+			DataSourceName:     "fedora",
+		}),
+		Entry("invalid preference kind", "Preset is not a valid preference-kind", &parse.CLIOptions{
+			Instancetype:       "u1.small",
+			Preference:         "fedora",
+			PreferenceKind:     "Preset",
+			VirtualMachineName: "my-vm",
+			DataSourceName:     "fedora",
+		}),
+		Entry("preference kind without preference", "preference option is required when preference-kind is specified", &parse.CLIOptions{
+			Instancetype:       "u1.small",
+			PreferenceKind:     "VirtualMachinePreference",
+			VirtualMachineName: "my-vm",
+			DataSourceName:     "fedora",
+		}),
+		Entry("invalid disk size", "invalid disk-size", &parse.CLIOptions{
+			Instancetype:       "u1.small",
+			VirtualMachineName: "my-vm",
+			DataSourceName:     "fedora",
+			DiskSize:           "big",
+		}),
+		Entry("template name and selector", "only one of template-name or template-selector should be specified", &parse.CLIOptions{
+			TemplateName:     "test",
+			TemplateSelector: "os=fedora",
+		}),
+		Entry("template selector and manifest", "only one of vm-manifest, template-name, virtctl, instancetype, vm-snapshot or clone-source-vm should be specified", &parse.CLIOptions{
+			TemplateSelector:       "os=fedora",
+			VirtualMachineManifest: testVMManifest,
+		}),
+		Entry("invalid template selector format", "invalid template-selector: fedora should be in \"KEY=VAL\" format", &parse.CLIOptions{
+			TemplateSelector: "os=fedora,fedora",
+		}),
+		Entry("invalid template selector value", "invalid template-selector", &parse.CLIOptions{
+			TemplateSelector: "os=fedora 37",
+		}),
+		Entry("invalid ttl", "could not parse ttl", &parse.CLIOptions{
+			TemplateName:            "test",
+			TemplateNamespace:       defaultNS,
+			VirtualMachineNamespace: defaultNS,
+			Options:                 ownerref.Options{TTL: "1 day"},
+		}),
+		Entry("invalid owner kind", "Pod is not a valid owner-kind", &parse.CLIOptions{
+			TemplateName:            "test",
+			TemplateNamespace:       defaultNS,
+			VirtualMachineNamespace: defaultNS,
+			Options:                 ownerref.Options{OwnerKind: "Pod"},
+		}),
+		Entry("invalid count", "count should be a positive integer", &parse.CLIOptions{
+			TemplateName: "test",
+			Count:        "0",
+		}),
+		Entry("invalid parallelism", "parallelism should be a positive integer", &parse.CLIOptions{
+			TemplateName: "test",
+			Parallelism:  "many",
+		}),
+		Entry("count with virtctl", "count option is not applicable for virtctl", &parse.CLIOptions{
+			Virtctl: "--volume-import type:pvc,src:ns/name",
+			Count:   "2",
+		}),
+		Entry("invalid service ports", "invalid service-ports: ssh:twenty: twenty is not a valid port number", &parse.CLIOptions{
+			TemplateName: "test",
+			ServicePorts: []string{"ssh:twenty"},
+		}),
+		Entry("invalid service type", "ExternalName is not a valid service-type, only ClusterIP|NodePort|LoadBalancer is allowed", &parse.CLIOptions{
+			TemplateName: "test",
+			ServicePorts: []string{"22"},
+			ServiceType:  "ExternalName",
+		}),
+		Entry("service type without ports", "service-type option is applicable only for service-ports", &parse.CLIOptions{
+			TemplateName: "test",
+			ServiceType:  "NodePort",
+		}),
+		Entry("invalid ssh propagation method", "cloudInit is not a valid ssh-propagation-method, only configDrive|qemuGuestAgent is allowed", &parse.CLIOptions{
+			TemplateName:         "test",
+			SSHPublicKeySecret:   "my-key",
+			SSHPropagationMethod: "cloudInit",
+		}),
+		Entry("noCloud ssh propagation method", "noCloud ssh-propagation-method is not supported by this KubeVirt API version, only configDrive|qemuGuestAgent is allowed", &parse.CLIOptions{
+			TemplateName:         "test",
+			SSHPublicKeySecret:   "my-key",
+			SSHPropagationMethod: "noCloud",
+		}),
+		Entry("ssh propagation method without secret", "ssh-propagation-method, ssh-users options are applicable only for ssh-public-key-secret", &parse.CLIOptions{
+			TemplateName:         "test",
+			SSHPropagationMethod: "configDrive",
+		}),
+		Entry("qemuGuestAgent without users", "ssh-users option is required for qemuGuestAgent ssh-propagation-method", &parse.CLIOptions{
+			TemplateName:         "test",
+			SSHPublicKeySecret:   "my-key",
+			SSHPropagationMethod: "qemuGuestAgent",
+		}),
+		Entry("ssh users with configDrive", "ssh-users option is applicable only for qemuGuestAgent ssh-propagation-method", &parse.CLIOptions{
+			TemplateName:       "test",
+			SSHPublicKeySecret: "my-key",
+			SSHUsers:           "fedora",
+		}),
+		Entry("invalid user data secret", "cloud-init-user-data-secret is not a valid name", &parse.CLIOptions{
+			TemplateName:   "test",
+			UserDataSecret: "User Data",
+		}),
+		Entry("invalid cpu cores", "cpu-cores should be a positive integer", &parse.CLIOptions{
+			TemplateName: "test",
+			CPUCores:     "-1",
+		}),
+		Entry("invalid memory limit", "invalid memory-limit", &parse.CLIOptions{
+			TemplateName: "test",
+			MemoryLimit:  "4 gigs",
+		}),
+		Entry("memory with instancetype", "cpu-sockets, cpu-cores, cpu-threads, memory-request, memory-limit options are not applicable for instancetype", &parse.CLIOptions{
+			Instancetype:       "u1.small",
+			VirtualMachineName: "my-vm",
+			DataSourceName:     "fedora",
+			MemoryRequest:      "2Gi",
+		}),
+		Entry("invalid node selector", "invalid node-selector", &parse.CLIOptions{
+			TemplateName: "test",
+			NodeSelector: "worker",
+		}),
+		Entry("invalid tolerations", "invalid tolerations", &parse.CLIOptions{
+			TemplateName: "test",
+			Tolerations:  "- key: dedicated\n  unknown: true",
+		}),
+		Entry("invalid affinity", "invalid affinity", &parse.CLIOptions{
+			TemplateName: "test",
+			Affinity:     "[]",
+		}),
+		Entry("invalid eviction strategy", "Migrate is not a valid eviction-strategy, only None|LiveMigrate|LiveMigrateIfPossible|External is allowed", &parse.CLIOptions{
+			TemplateName:     "test",
+			EvictionStrategy: "Migrate",
+		}),
+		Entry("invalid priority class name", "priority-class-name is not a valid name", &parse.CLIOptions{
+			TemplateName:      "test",
+			PriorityClassName: "High Priority",
+		}),
+		Entry("dv template name without overrides", "dv-template-name option is applicable only for source-datasource, source-pvc, source-snapshot, storage-class, access-modes, volume-mode, storage-size", &parse.CLIOptions{
+			TemplateName:           "test",
+			DataVolumeTemplateName: "rootdisk",
+		}),
+		Entry("dv template overrides with instancetype", "dv-template-name, source-datasource, source-pvc, source-snapshot, storage-class, access-modes, volume-mode, storage-size options are not applicable for instancetype", &parse.CLIOptions{
+			Instancetype:       "u1.small",
+			VirtualMachineName: "vm",
+			DataSourceName:     "fedora",
+			StorageSize:        "20Gi",
+		}),
+		Entry("multiple sources", "only one of source-datasource, source-pvc or source-snapshot should be specified", &parse.CLIOptions{
+			TemplateName:   "test",
+			SourcePVC:      "fedora",
+			SourceSnapshot: "fedora-snapshot",
+		}),
+		Entry("invalid source pvc", "invalid source-pvc: Fedora is not a valid name", &parse.CLIOptions{
+			TemplateName: "test",
+			SourcePVC:    "Fedora",
+		}),
+		Entry("invalid source datasource namespace", "invalid source-datasource: os.images is not a valid namespace", &parse.CLIOptions{
+			TemplateName:     "test",
+			SourceDataSource: "os.images/fedora",
+		}),
+		Entry("invalid access mode", "ReadOnce is not a valid access mode, only ReadWriteOnce|ReadOnlyMany|ReadWriteMany|ReadWriteOncePod is allowed", &parse.CLIOptions{
+			TemplateName: "test",
+			AccessModes:  "ReadWriteMany,ReadOnce",
+		}),
+		Entry("invalid volume mode", "Raw is not a valid volume-mode, only Filesystem|Block is allowed", &parse.CLIOptions{
+			TemplateName: "test",
+			VolumeMode:   "Raw",
+		}),
+		Entry("invalid storage size", "invalid storage-size", &parse.CLIOptions{
+			TemplateName: "test",
+			StorageSize:  "twenty",
+		}),
+		Entry("zero storage size", "storage-size should be a positive quantity", &parse.CLIOptions{
+			TemplateName: "test",
+			StorageSize:  "0",
+		}),
+		Entry("snapshot and clone", "only one of vm-manifest, template-name, virtctl, instancetype, vm-snapshot or clone-source-vm should be specified", &parse.CLIOptions{
+			VirtualMachineSnapshot: "golden-snapshot",
+			CloneSourceVM:          "golden-vm",
+		}),
+		Entry("missing vm name with snapshot", "vm-name option is required for vm-snapshot", &parse.CLIOptions{
+			VirtualMachineSnapshot: "golden-snapshot",
+		}),
+		Entry("invalid vm name with clone", "vm-name is not a valid name", &parse.CLIOptions{
+			CloneSourceVM:      "golden-vm",
+			VirtualMachineName: "My VM",
+		}),
+		Entry("clone options with snapshot", "clone-label-filters, clone-annotation-filters, clone-new-mac-addresses options are applicable only for clone-source-vm", &parse.CLIOptions{
+			VirtualMachineSnapshot: "golden-snapshot",
+			VirtualMachineName:     "vm",
+			CloneLabelFilters:      "*",
+		}),
+		Entry("invalid clone mac addresses format", "invalid clone-new-mac-addresses: default should be in \"IFACE=MAC\" format", &parse.CLIOptions{
+			CloneSourceVM:        "golden-vm",
+			CloneNewMacAddresses: "default",
+		}),
+		Entry("invalid clone mac address", "invalid clone-new-mac-addresses: 02:00:00:00:00 is not a valid MAC address of default interface", &parse.CLIOptions{
+			CloneSourceVM:        "golden-vm",
+			CloneNewMacAddresses: "default=02:00:00:00:00",
+		}),
+		Entry("template params with clone", "template-namespace, template-params options are not applicable for clone-source-vm", &parse.CLIOptions{
+			CloneSourceVM:  "golden-vm",
+			TemplateParams: []string{"K1:V1"},
+		}),
+		Entry("dry run with snapshot", "dry-run option is not applicable for vm-snapshot", &parse.CLIOptions{
+			VirtualMachineSnapshot: "golden-snapshot",
+			VirtualMachineName:     "vm",
+			DryRun:                 "client",
+		}),
+		Entry("vm overrides with clone", "memory-request option is not applicable for clone-source-vm", &parse.CLIOptions{
+			CloneSourceVM: "golden-vm",
+			MemoryRequest: "2Gi",
+		}),
+		Entry("unknown virtctl flags", "invalid virtctl: unknown flags of virtctl create vm command: --namespace, -x", &parse.CLIOptions{
+			Virtctl: "--name vm --namespace default -x",
+		}),
+		Entry("invalid virtctl quoting", "invalid virtctl: EOF found when expecting closing quote", &parse.CLIOptions{
+			Virtctl: "--name 'vm",
+		}),
+		Entry("invalid virtctl list", "invalid virtctl: could not parse list of flags: error converting YAML to JSON: yaml: line 1: did not find expected ',' or ']'", &parse.CLIOptions{
+			Virtctl: `["--name", "vm"`,
+		}),
 	)
 
 	DescribeTable("Parses and returns correct values", func(options *parse.CLIOptions, expectedOptions map[string]interface{}) {
@@ -75,6 +362,15 @@ var _ = Describe("CLIOptions", func() {
 			"GetStartVMFlag":             false,
 			"GetRunStrategy":             "",
 		}),
+		Entry("handles template selector", &parse.CLIOptions{
+			TemplateSelector:        " os=fedora, workload=server,flavor=small , architecture=amd64,app=test",
+			TemplateNamespace:       defaultNS,
+			VirtualMachineNamespace: defaultNS,
+		}, map[string]interface{}{
+			"GetTemplateSelector": "app=test,flavor.template.kubevirt.io/small=true,os.template.kubevirt.io/fedora=true," +
+				"template.kubevirt.io/architecture=amd64,workload.template.kubevirt.io/server=true",
+			"GetCreationMode": constants.TemplateCreationMode,
+		}),
 		Entry("handles template cli arguments", &parse.CLIOptions{
 			TemplateName:            "test",
 			TemplateNamespace:       defaultNS,
@@ -114,6 +410,270 @@ var _ = Describe("CLIOptions", func() {
 			"GetStartVMFlag":             false,
 			"GetRunStrategy":             "Always",
 		}),
+		Entry("handles client dry run", &parse.CLIOptions{
+			VirtualMachineManifest:  testVMManifest,
+			VirtualMachineNamespace: defaultNS,
+			DryRun:                  "client",
+		}, map[string]interface{}{
+			"GetDryRun": constants.DryRunClient,
+			"IsDryRun":  true,
+			"GetOutput": output.YamlOutput,
+		}),
+		Entry("handles server dry run", &parse.CLIOptions{
+			VirtualMachineManifest:  testVMManifest,
+			VirtualMachineNamespace: defaultNS,
+			Output:                  output.JsonOutput,
+			DryRun:                  "server",
+		}, map[string]interface{}{
+			"GetDryRun": constants.DryRunServer,
+			"IsDryRun":  true,
+			"GetOutput": output.JsonOutput,
+		}),
+		Entry("handles no dry run", &parse.CLIOptions{
+			VirtualMachineManifest:  testVMManifest,
+			VirtualMachineNamespace: defaultNS,
+		}, map[string]interface{}{
+			"GetDryRun": constants.DryRunNone,
+			"IsDryRun":  false,
+			"GetOutput": output.OutputType(""),
+		}),
+		Entry("handles wait for ready", &parse.CLIOptions{
+			VirtualMachineManifest:  testVMManifest,
+			VirtualMachineNamespace: defaultNS,
+			WaitForReady:            "true",
+			WaitTimeout:             "5m",
+		}, map[string]interface{}{
+			"GetWaitForReadyFlag": true,
+			"GetWaitTimeout":      5 * time.Minute,
+		}),
+		Entry("handles count", &parse.CLIOptions{
+			VirtualMachineManifest:  testVMManifest,
+			VirtualMachineNamespace: defaultNS,
+			Count:                   "3",
+			Parallelism:             "2",
+		}, map[string]interface{}{
+			"GetCount":       3,
+			"GetParallelism": 2,
+		}),
+		Entry("handles count with wait for ready", &parse.CLIOptions{
+			VirtualMachineManifest:  testVMManifest,
+			VirtualMachineNamespace: defaultNS,
+			Count:                   "3",
+			WaitForReady:            "true",
+		}, map[string]interface{}{
+			"GetCount":            3,
+			"GetWaitForReadyFlag": true,
+		}),
+		Entry("handles service ports", &parse.CLIOptions{
+			VirtualMachineManifest:  testVMManifest,
+			VirtualMachineNamespace: defaultNS,
+			ServicePorts:            []string{"ssh:22", "rdp:3389/TCP"},
+			ServiceType:             "NodePort",
+		}, map[string]interface{}{
+			"GetServicePorts": []corev1.ServicePort{
+				{Name: "ssh", Protocol: corev1.ProtocolTCP, Port: 22, TargetPort: intstr.FromInt(22)},
+				{Name: "rdp", Protocol: corev1.ProtocolTCP, Port: 3389, TargetPort: intstr.FromInt(3389)},
+			},
+			"GetServiceType": corev1.ServiceTypeNodePort,
+		}),
+		Entry("handles no service ports", &parse.CLIOptions{
+			VirtualMachineManifest:  testVMManifest,
+			VirtualMachineNamespace: defaultNS,
+		}, map[string]interface{}{
+			"GetServicePorts": []corev1.ServicePort(nil),
+			"GetServiceType":  corev1.ServiceTypeClusterIP,
+		}),
+		Entry("handles access credentials", &parse.CLIOptions{
+			VirtualMachineManifest:  testVMManifest,
+			VirtualMachineNamespace: defaultNS,
+			SSHPublicKeySecret:      "my-key",
+			SSHPropagationMethod:    "qemuGuestAgent",
+			SSHUsers:                "fedora, cloud-user,",
+		}, map[string]interface{}{
+			"GetSSHPropagationMethod": vm.QemuGuestAgentPropagation,
+			"GetSSHUsers":             []string{"fedora", "cloud-user"},
+		}),
+		Entry("handles default ssh propagation method", &parse.CLIOptions{
+			VirtualMachineManifest:  testVMManifest,
+			VirtualMachineNamespace: defaultNS,
+			SSHPublicKeySecret:      "my-key",
+		}, map[string]interface{}{
+			"GetSSHPropagationMethod": vm.ConfigDrivePropagation,
+			"GetSSHUsers":             []string(nil),
+		}),
+		Entry("handles vm overrides", &parse.CLIOptions{
+			VirtualMachineManifest:  testVMManifest,
+			VirtualMachineNamespace: defaultNS,
+			CPUSockets:              "2",
+			CPUCores:                " 4 ",
+			MemoryRequest:           "2Gi",
+			MemoryLimit:             "4Gi",
+			NodeSelector:            "kubernetes.io/arch=amd64, node-role.kubernetes.io/worker=",
+			Tolerations:             "- key: dedicated\n  operator: Equal\n  value: vms\n  effect: NoSchedule",
+			Affinity:                `{"podAntiAffinity": {"preferredDuringSchedulingIgnoredDuringExecution": [{"weight": 1, "podAffinityTerm": {"topologyKey": "kubernetes.io/hostname"}}]}}`,
+			EvictionStrategy:        "LiveMigrate",
+			PriorityClassName:       "high",
+		}, map[string]interface{}{
+			"GetVMOverrides": &overrides.VMOverrides{
+				CPUSockets:    2,
+				CPUCores:      4,
+				MemoryRequest: &memoryRequest,
+				MemoryLimit:   &memoryLimit,
+				NodeSelector:  map[string]string{"kubernetes.io/arch": "amd64", "node-role.kubernetes.io/worker": ""},
+				Tolerations: []corev1.Toleration{
+					{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "vms", Effect: corev1.TaintEffectNoSchedule},
+				},
+				Affinity: &corev1.Affinity{
+					PodAntiAffinity: &corev1.PodAntiAffinity{
+						PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+							{Weight: 1, PodAffinityTerm: corev1.PodAffinityTerm{TopologyKey: "kubernetes.io/hostname"}},
+						},
+					},
+				},
+				EvictionStrategy:  &liveMigrate,
+				PriorityClassName: "high",
+			},
+		}),
+		Entry("handles no vm overrides", &parse.CLIOptions{
+			VirtualMachineManifest:  testVMManifest,
+			VirtualMachineNamespace: defaultNS,
+		}, map[string]interface{}{
+			"GetVMOverrides": &overrides.VMOverrides{},
+		}),
+		Entry("handles data volume template overrides", &parse.CLIOptions{
+			VirtualMachineManifest:  testVMManifest,
+			VirtualMachineNamespace: defaultNS,
+			DataVolumeTemplateName:  "rootdisk",
+			SourceDataSource:        " os-images/fedora ",
+			StorageClass:            "ocs-storagecluster-ceph-rbd",
+			AccessModes:             "ReadWriteMany, ReadOnlyMany",
+			VolumeMode:              "Block",
+			StorageSize:             "20Gi",
+		}, map[string]interface{}{
+			"GetDataVolumeTemplateOverrides": &datavolume.TemplateOverrides{
+				Name: "rootdisk",
+				SourceRef: &cdiv1beta1.DataVolumeSourceRef{
+					Kind:      constants.DataSourceKind,
+					Name:      "fedora",
+					Namespace: pointer.String("os-images"),
+				},
+				StorageClass: "ocs-storagecluster-ceph-rbd",
+				AccessModes:  []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany, corev1.ReadOnlyMany},
+				VolumeMode:   &blockMode,
+				Size:         &storageSize,
+			},
+		}),
+		Entry("handles source pvc without namespace", &parse.CLIOptions{
+			VirtualMachineManifest:  testVMManifest,
+			VirtualMachineNamespace: defaultNS,
+			SourcePVC:               "fedora",
+		}, map[string]interface{}{
+			"GetDataVolumeTemplateOverrides": &datavolume.TemplateOverrides{
+				PVC: &cdiv1beta1.DataVolumeSourcePVC{Name: "fedora"},
+			},
+		}),
+		Entry("handles no data volume template overrides", &parse.CLIOptions{
+			VirtualMachineManifest:  testVMManifest,
+			VirtualMachineNamespace: defaultNS,
+		}, map[string]interface{}{
+			"GetDataVolumeTemplateOverrides": (*datavolume.TemplateOverrides)(nil),
+		}),
+		Entry("handles default count", &parse.CLIOptions{
+			VirtualMachineManifest:  testVMManifest,
+			VirtualMachineNamespace: defaultNS,
+		}, map[string]interface{}{
+			"GetCount":       1,
+			"GetParallelism": constants.DefaultParallelism,
+		}),
+		Entry("handles wait for data volumes", &parse.CLIOptions{
+			VirtualMachineManifest:  testVMManifest,
+			VirtualMachineNamespace: defaultNS,
+			WaitForDataVolumes:      "true",
+		}, map[string]interface{}{
+			"GetWaitForDataVolumesFlag": true,
+		}),
+		Entry("handles no wait for ready", &parse.CLIOptions{
+			VirtualMachineManifest:  testVMManifest,
+			VirtualMachineNamespace: defaultNS,
+		}, map[string]interface{}{
+			"GetWaitForReadyFlag":       false,
+			"GetWaitForDataVolumesFlag": false,
+			"GetWaitTimeout":            constants.DefaultWaitTimeout,
+		}),
+		Entry("handles instancetype cli arguments", &parse.CLIOptions{
+			Instancetype:            "u1.small",
+			Preference:              "fedora",
+			VirtualMachineName:      "my-vm",
+			VirtualMachineNamespace: defaultNS,
+			DataSourceName:          "fedora",
+			DiskSize:                "30Gi",
+		}, map[string]interface{}{
+			"GetVirtualMachineNamespace": defaultNS,
+			"GetVirtualMachineName":      "my-vm",
+			"GetCreationMode":            constants.InstancetypeCreationMode,
+			"GetInstancetypeKind":        "VirtualMachineClusterInstancetype",
+			"GetPreferenceKind":          "VirtualMachineClusterPreference",
+			"GetDataSourceNamespace":     defaultNS,
+			"GetDiskSize":                &diskSize,
+		}),
+		Entry("handles instancetype kinds", &parse.CLIOptions{
+			Instancetype:            "u1.small",
+			InstancetypeKind:        "VirtualMachineInstancetype",
+			Preference:              "fedora",
+			PreferenceKind:          "VirtualMachinePreference",
+			VirtualMachineName:      "my-vm",
+			VirtualMachineNamespace: defaultNS,
+			DataSourceName:          "fedora",
+			DataSourceNamespace:     "images",
+		}, map[string]interface{}{
+			"GetInstancetypeKind":    "VirtualMachineInstancetype",
+			"GetPreferenceKind":      "VirtualMachinePreference",
+			"GetDataSourceNamespace": "images",
+		}),
+		Entry("handles vm snapshot cli arguments", &parse.CLIOptions{
+			VirtualMachineSnapshot:  " golden-snapshot ",
+			VirtualMachineNamespace: defaultNS,
+			VirtualMachineName:      "vm",
+		}, map[string]interface{}{
+			"GetCreationMode":            constants.SnapshotCreationMode,
+			"GetVirtualMachineNamespace": defaultNS,
+			"GetVirtualMachineName":      "vm",
+		}),
+		Entry("handles clone cli arguments", &parse.CLIOptions{
+			CloneSourceVM:           "golden-vm",
+			VirtualMachineNamespace: defaultNS,
+			CloneLabelFilters:       "*, !example.com/*",
+			CloneAnnotationFilters:  "example.com/*",
+			CloneNewMacAddresses:    "default=02:00:00:00:00:01, secondary = 02:00:00:00:00:02",
+		}, map[string]interface{}{
+			"GetCreationMode":           constants.CloneCreationMode,
+			"GetVirtualMachineName":     "",
+			"GetCloneLabelFilters":      []string{"*", "!example.com/*"},
+			"GetCloneAnnotationFilters": []string{"example.com/*"},
+			"GetCloneNewMacAddresses":   map[string]string{"default": "02:00:00:00:00:01", "secondary": "02:00:00:00:00:02"},
+		}),
+		Entry("handles virtctl cli arguments", &parse.CLIOptions{
+			Virtctl:                 `--name vm --volume-import "type:blank,size:1Gi,name:data disk" --cloud-init-user-data=I2Nsb3VkLWNvbmZpZwo=`,
+			VirtualMachineNamespace: defaultNS,
+		}, map[string]interface{}{
+			"GetCreationMode":            constants.VirtctlCreatingMode,
+			"GetVirtualMachineNamespace": defaultNS,
+			"GetVirtctlArgs":             []string{"--name", "vm", "--volume-import", "type:blank,size:1Gi,name:data disk", "--cloud-init-user-data=I2Nsb3VkLWNvbmZpZwo="},
+		}),
+		Entry("handles virtctl list", &parse.CLIOptions{
+			Virtctl:                 `["--name=vm", "--volume-import=type:blank,size:1Gi,name:data disk"]`,
+			VirtualMachineNamespace: defaultNS,
+		}, map[string]interface{}{
+			"GetVirtctlArgs": []string{"--name=vm", "--volume-import=type:blank,size:1Gi,name:data disk"},
+		}),
+		Entry("handles default clone filters", &parse.CLIOptions{
+			CloneSourceVM:           "golden-vm",
+			VirtualMachineNamespace: defaultNS,
+		}, map[string]interface{}{
+			"GetCloneLabelFilters":      []string(nil),
+			"GetCloneAnnotationFilters": []string(nil),
+			"GetCloneNewMacAddresses":   map[string]string(nil),
+		}),
 		Entry("handles trim", &parse.CLIOptions{
 			TemplateName:            "test",
 			TemplateNamespace:       "  " + defaultNS + " ",
@@ -1,7 +1,11 @@
 package parse
 
 import (
+	"fmt"
+	"net"
 	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 func (c *CLIOptions) getMissingNamespaceOptionNames() string {
@@ -50,3 +54,50 @@ func splitVolumePrefix(input string) (string, string) {
 
 	return "", strings.TrimSpace(input)
 }
+
+func splitList(input, sep string) []string {
+	var result []string
+	for _, item := range strings.Split(input, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// parseMacAddresses parses MAC addresses of interfaces in a IFACE1=MAC1,IFACE2=MAC2 format
+func parseMacAddresses(input string) (map[string]string, error) {
+	var result map[string]string
+	for _, item := range splitList(input, macAddressesSep) {
+		keyValue := strings.SplitN(item, macAddressKeyValueSep, 2)
+		if len(keyValue) != 2 || strings.TrimSpace(keyValue[0]) == "" {
+			return nil, fmt.Errorf("%v should be in \"IFACE=MAC\" format", item)
+		}
+		iface, macAddress := strings.TrimSpace(keyValue[0]), strings.TrimSpace(keyValue[1])
+		if _, err := net.ParseMAC(macAddress); err != nil {
+			return nil, fmt.Errorf("%v is not a valid MAC address of %v interface", macAddress, iface)
+		}
+		if result == nil {
+			result = map[string]string{}
+		}
+		result[iface] = macAddress
+	}
+	return result, nil
+}
+
+// parseNamespacedName parses a name in a [NAMESPACE/]NAME format. The namespace is empty when not specified.
+func parseNamespacedName(input string) (string, string, error) {
+	var namespace string
+	name := input
+	if split := strings.SplitN(input, namespaceNameSep, 2); len(split) == 2 {
+		namespace, name = strings.TrimSpace(split[0]), strings.TrimSpace(split[1])
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return "", "", fmt.Errorf("%v is not a valid namespace: %v", namespace, strings.Join(errs, ";"))
+		}
+	}
+
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return "", "", fmt.Errorf("%v is not a valid name: %v", name, strings.Join(errs, ";"))
+	}
+	return namespace, name, nil
+}
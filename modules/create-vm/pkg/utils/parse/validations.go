@@ -1,41 +1,527 @@
 package parse
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/bundle"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/constants"
+	lab "github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/constants/labels"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/datavolume"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/service"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/virtctl"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/vm"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/env"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/output"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/overrides"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zconstants"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zerrors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
 	kubevirtv1 "kubevirt.io/api/core/v1"
+	cdiv1beta1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
 	"sigs.k8s.io/yaml"
 )
 
 func (c *CLIOptions) assertValidMode() error {
-	if c.VirtualMachineManifest != "" && c.TemplateName == "" && c.Virtctl == "" {
+	if c.TemplateName != "" && c.TemplateSelector != "" {
+		return zerrors.NewSoftError("only one of %v or %v should be specified", templateNameOptionName, templateSelectorOptionName)
+	}
+
+	modesCount := 0
+	for _, modeOption := range []string{c.VirtualMachineManifest, c.TemplateName + c.TemplateSelector, c.Virtctl, c.Instancetype, c.VirtualMachineSnapshot, c.CloneSourceVM} {
+		if modeOption != "" {
+			modesCount++
+		}
+	}
+
+	if modesCount != 1 {
+		return zerrors.NewSoftError("only one of %v, %v, %v, %v, %v or %v should be specified", vmManifestOptionName, templateNameOptionName, virtctlOptionName,
+			instancetypeOptionName, vmSnapshotOptionName, cloneSourceVMOptionName)
+	}
+
+	mode := c.GetCreationMode()
+
+	if mode != constants.TemplateCreationMode && mode != constants.VirtctlCreatingMode {
 		if len(c.GetTemplateParams()) > 0 || c.GetTemplateNamespace() != "" {
-			return zerrors.NewSoftError("%v, %v options are not applicable for %v", templateNamespaceOptionName, templateParamsOptionName, vmManifestOptionName)
+			return zerrors.NewSoftError("%v, %v options are not applicable for %v", templateNamespaceOptionName, templateParamsOptionName, c.getModeOptionName())
 		}
-		return nil
 	}
 
-	if (c.VirtualMachineManifest == "" && c.TemplateName != "" && c.Virtctl == "") ||
-		(c.VirtualMachineManifest == "" && c.TemplateName == "" && c.Virtctl != "") {
-		return nil
+	if mode != constants.CloneCreationMode && (c.CloneLabelFilters != "" || c.CloneAnnotationFilters != "" || c.CloneNewMacAddresses != "") {
+		return zerrors.NewSoftError("%v, %v, %v options are applicable only for %v", cloneLabelFiltersOptionName, cloneAnnotationFiltersName,
+			cloneNewMacAddressesName, cloneSourceVMOptionName)
+	}
+
+	if mode == constants.InstancetypeCreationMode {
+		return c.assertValidInstancetypeOptions()
+	}
+
+	if c.InstancetypeKind != "" || c.Preference != "" || c.PreferenceKind != "" || c.DataSourceName != "" || c.DataSourceNamespace != "" || c.DiskSize != "" {
+		return zerrors.NewSoftError("%v, %v, %v, %v, %v, %v options are applicable only for %v", instancetypeKindOptionName, preferenceOptionName, preferenceKindOptionName,
+			dataSourceNameOptionName, dataSourceNamespaceOptionName, diskSizeOptionName, instancetypeOptionName)
+	}
+
+	if mode == constants.SnapshotCreationMode || mode == constants.CloneCreationMode {
+		return c.assertValidSnapshotAndCloneOptions()
 	}
 
-	return zerrors.NewSoftError("only one of %v, %v or %v should be specified", vmManifestOptionName, templateNameOptionName, virtctlOptionName)
+	return nil
+}
+
+// assertValidSnapshotAndCloneOptions rejects options modifying the VM, because the VM is created by KubeVirt in these modes
+func (c *CLIOptions) assertValidSnapshotAndCloneOptions() error {
+	modeOptionName := c.getModeOptionName()
+
+	if c.GetCreationMode() == constants.SnapshotCreationMode && c.VirtualMachineName == "" {
+		return zerrors.NewMissingRequiredError("%v option is required for %v", vmNameOptionName, vmSnapshotOptionName)
+	}
+
+	for optionName, name := range map[string]string{vmNameOptionName: c.VirtualMachineName, modeOptionName: c.VirtualMachineSnapshot + c.CloneSourceVM} {
+		if name == "" {
+			continue
+		}
+		if errs := validation.IsDNS1123Subdomain(strings.TrimSpace(name)); len(errs) > 0 {
+			return zerrors.NewMissingRequiredError("%v is not a valid name: %v", optionName, strings.Join(errs, ";"))
+		}
+	}
+
+	if _, err := parseMacAddresses(c.CloneNewMacAddresses); err != nil {
+		return zerrors.NewMissingRequiredError("invalid %v: %v", cloneNewMacAddressesName, err.Error())
+	}
+
+	for _, option := range []struct {
+		name  string
+		value string
+	}{
+		{dryRunOptionName, c.DryRun},
+		{countOptionName, c.Count},
+		{runStrategyOptionName, c.RunStrategy},
+		{servicePortsOptionName, strings.Join(c.ServicePorts, "")},
+		{sshPublicKeySecretOptionName, c.SSHPublicKeySecret},
+		{userDataSecretOptionName, c.UserDataSecret},
+		{networkDataSecretOptionName, c.NetworkDataSecret},
+		{cpuSocketsOptionName, c.CPUSockets},
+		{cpuCoresOptionName, c.CPUCores},
+		{cpuThreadsOptionName, c.CPUThreads},
+		{memoryRequestOptionName, c.MemoryRequest},
+		{memoryLimitOptionName, c.MemoryLimit},
+		{nodeSelectorOptionName, c.NodeSelector},
+		{tolerationsOptionName, c.Tolerations},
+		{affinityOptionName, c.Affinity},
+		{evictionStrategyOptionName, c.EvictionStrategy},
+		{priorityClassNameOptionName, c.PriorityClassName},
+		{dvTemplateNameOptionName, c.DataVolumeTemplateName},
+		{sourceDataSourceOptionName, c.SourceDataSource},
+		{sourcePVCOptionName, c.SourcePVC},
+		{sourceSnapshotOptionName, c.SourceSnapshot},
+		{storageClassOptionName, c.StorageClass},
+		{accessModesOptionName, c.AccessModes},
+		{volumeModeOptionName, c.VolumeMode},
+		{storageSizeOptionName, c.StorageSize},
+	} {
+		if strings.TrimSpace(option.value) != "" {
+			return zerrors.NewSoftError("%v option is not applicable for %v", option.name, modeOptionName)
+		}
+	}
+
+	return nil
+}
+
+func (c *CLIOptions) assertValidInstancetypeOptions() error {
+	if c.VirtualMachineName == "" {
+		return zerrors.NewMissingRequiredError("%v option is required for %v", vmNameOptionName, instancetypeOptionName)
+	}
+
+	if errs := validation.IsDNS1123Subdomain(c.VirtualMachineName); len(errs) > 0 {
+		return zerrors.NewMissingRequiredError("%v is not a valid name: %v", vmNameOptionName, strings.Join(errs, ";"))
+	}
+
+	if c.DataSourceName == "" {
+		return zerrors.NewMissingRequiredError("%v option is required for %v", dataSourceNameOptionName, instancetypeOptionName)
+	}
+
+	if kind := c.GetInstancetypeKind(); kind != constants.VirtualMachineInstancetypeKind && kind != constants.VirtualMachineClusterInstancetypeKind {
+		return zerrors.NewMissingRequiredError("%v is not a valid %v, only %v|%v is allowed", kind, instancetypeKindOptionName,
+			constants.VirtualMachineInstancetypeKind, constants.VirtualMachineClusterInstancetypeKind)
+	}
+
+	if kind := c.GetPreferenceKind(); kind != constants.VirtualMachinePreferenceKind && kind != constants.VirtualMachineClusterPreferenceKind {
+		return zerrors.NewMissingRequiredError("%v is not a valid %v, only %v|%v is allowed", kind, preferenceKindOptionName,
+			constants.VirtualMachinePreferenceKind, constants.VirtualMachineClusterPreferenceKind)
+	}
+
+	if c.PreferenceKind != "" && c.Preference == "" {
+		return zerrors.NewMissingRequiredError("%v option is required when %v is specified", preferenceOptionName, preferenceKindOptionName)
+	}
+
+	if c.DiskSize != "" {
+		if _, err := resource.ParseQuantity(c.DiskSize); err != nil {
+			return zerrors.NewMissingRequiredError("invalid %v: %v", diskSizeOptionName, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (c *CLIOptions) getModeOptionName() string {
+	switch c.GetCreationMode() {
+	case constants.TemplateCreationMode:
+		if c.TemplateSelector != "" {
+			return templateSelectorOptionName
+		}
+		return templateNameOptionName
+	case constants.VMManifestCreationMode:
+		return vmManifestOptionName
+	case constants.VirtctlCreatingMode:
+		return virtctlOptionName
+	case constants.InstancetypeCreationMode:
+		return instancetypeOptionName
+	case constants.SnapshotCreationMode:
+		return vmSnapshotOptionName
+	case constants.CloneCreationMode:
+		return cloneSourceVMOptionName
+	}
+	return ""
 }
 
 func (c *CLIOptions) assertValidTypes() error {
 	if !output.IsOutputType(string(c.Output)) {
 		return zerrors.NewMissingRequiredError("%v is not a valid output type", c.Output)
 	}
+
+	if dryRun := c.GetDryRun(); dryRun != constants.DryRunNone && dryRun != constants.DryRunClient && dryRun != constants.DryRunServer {
+		return zerrors.NewMissingRequiredError("%v is not a valid %v strategy, only %v|%v is allowed", dryRun, dryRunOptionName, constants.DryRunClient, constants.DryRunServer)
+	}
+
+	if c.TemplateSelector != "" {
+		if _, err := parseTemplateSelector(c.TemplateSelector); err != nil {
+			return zerrors.NewMissingRequiredError("invalid %v: %v", templateSelectorOptionName, err.Error())
+		}
+	}
+
+	if c.WaitTimeout != "" {
+		timeout, err := time.ParseDuration(c.WaitTimeout)
+		if err != nil {
+			return zerrors.NewMissingRequiredError("could not parse %v: %v", waitTimeoutOptionName, err.Error())
+		}
+		if timeout <= 0 {
+			return zerrors.NewMissingRequiredError("%v should be positive", waitTimeoutOptionName)
+		}
+	}
+
+	if c.GetWaitForReadyFlag() && c.IsDryRun() {
+		return zerrors.NewMissingRequiredError("%v option is not applicable for %v", waitForReadyOptionName, dryRunOptionName)
+	}
+
+	if c.GetWaitForDataVolumesFlag() && c.IsDryRun() {
+		return zerrors.NewMissingRequiredError("%v option is not applicable for %v", waitForDataVolumesOptionName, dryRunOptionName)
+	}
+
+	for optionName, value := range map[string]string{countOptionName: c.Count, parallelismOptionName: c.Parallelism} {
+		if value != "" {
+			if number, err := strconv.Atoi(value); err != nil || number < 1 {
+				return zerrors.NewMissingRequiredError("%v should be a positive integer", optionName)
+			}
+		}
+	}
+
+	if _, err := service.ParsePorts(c.ServicePorts); err != nil {
+		return zerrors.NewMissingRequiredError("invalid %v: %v", servicePortsOptionName, err.Error())
+	}
+
+	if c.ServiceType != "" {
+		if serviceType := c.GetServiceType(); !service.IsServiceType(serviceType) {
+			return zerrors.NewMissingRequiredError("%v is not a valid %v, only %v|%v|%v is allowed", serviceType, serviceTypeOptionName,
+				corev1.ServiceTypeClusterIP, corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer)
+		}
+		if len(c.GetServicePorts()) == 0 {
+			return zerrors.NewMissingRequiredError("%v option is applicable only for %v", serviceTypeOptionName, servicePortsOptionName)
+		}
+	}
+
+	if err := c.assertValidAccessCredentials(); err != nil {
+		return err
+	}
+
+	vmOverrides, err := c.parseVMOverrides()
+	if err != nil {
+		return err
+	}
+	c.vmOverrides = vmOverrides
+
+	dataVolumeTemplateOverrides, err := c.parseDataVolumeTemplateOverrides()
+	if err != nil {
+		return err
+	}
+	c.dataVolumeTemplateOverrides = dataVolumeTemplateOverrides
+
+	if c.GetCreationMode() == constants.VirtctlCreatingMode {
+		virtctlArgs, err := c.parseVirtctlArgs()
+		if err != nil {
+			return err
+		}
+		c.virtctlArgs = virtctlArgs
+	}
+
+	if c.GetCount() > 1 && c.GetCreationMode() == constants.VirtctlCreatingMode {
+		return zerrors.NewMissingRequiredError("%v option is not applicable for %v", countOptionName, virtctlOptionName)
+	}
 	return nil
 }
 
+func (c *CLIOptions) assertValidAccessCredentials() error {
+	for optionName, secretName := range map[string]string{sshPublicKeySecretOptionName: c.SSHPublicKeySecret,
+		userDataSecretOptionName: c.UserDataSecret, networkDataSecretOptionName: c.NetworkDataSecret} {
+		if secretName == "" {
+			continue
+		}
+		if errs := validation.IsDNS1123Subdomain(secretName); len(errs) > 0 {
+			return zerrors.NewMissingRequiredError("%v is not a valid name: %v", optionName, strings.Join(errs, ";"))
+		}
+	}
+
+	if c.SSHPublicKeySecret == "" {
+		if c.SSHPropagationMethod != "" || c.SSHUsers != "" {
+			return zerrors.NewMissingRequiredError("%v, %v options are applicable only for %v", sshPropagationOptionName, sshUsersOptionName, sshPublicKeySecretOptionName)
+		}
+		return nil
+	}
+
+	switch method := c.GetSSHPropagationMethod(); method {
+	case vm.ConfigDrivePropagation:
+		if c.SSHUsers != "" {
+			return zerrors.NewMissingRequiredError("%v option is applicable only for %v %v", sshUsersOptionName, vm.QemuGuestAgentPropagation, sshPropagationOptionName)
+		}
+	case vm.QemuGuestAgentPropagation:
+		if len(c.GetSSHUsers()) == 0 {
+			return zerrors.NewMissingRequiredError("%v option is required for %v %v", sshUsersOptionName, vm.QemuGuestAgentPropagation, sshPropagationOptionName)
+		}
+	case vm.NoCloudPropagation:
+		return zerrors.NewMissingRequiredError("%v %v is not supported by this KubeVirt API version, only %v|%v is allowed", method, sshPropagationOptionName,
+			vm.ConfigDrivePropagation, vm.QemuGuestAgentPropagation)
+	default:
+		return zerrors.NewMissingRequiredError("%v is not a valid %v, only %v|%v is allowed", method, sshPropagationOptionName,
+			vm.ConfigDrivePropagation, vm.QemuGuestAgentPropagation)
+	}
+
+	return nil
+}
+
+// parseVMOverrides converts the override options. CPU and memory can not be overridden for instancetypes.
+func (c *CLIOptions) parseVMOverrides() (*overrides.VMOverrides, error) {
+	vmOverrides := &overrides.VMOverrides{
+		PriorityClassName: strings.TrimSpace(c.PriorityClassName),
+	}
+
+	for optionName, option := range map[string]struct {
+		value  string
+		target *uint32
+	}{
+		cpuSocketsOptionName: {c.CPUSockets, &vmOverrides.CPUSockets},
+		cpuCoresOptionName:   {c.CPUCores, &vmOverrides.CPUCores},
+		cpuThreadsOptionName: {c.CPUThreads, &vmOverrides.CPUThreads},
+	} {
+		if value := strings.TrimSpace(option.value); value != "" {
+			number, err := strconv.ParseUint(value, 10, 32)
+			if err != nil || number == 0 {
+				return nil, zerrors.NewMissingRequiredError("%v should be a positive integer", optionName)
+			}
+			*option.target = uint32(number)
+		}
+	}
+
+	for optionName, option := range map[string]struct {
+		value  string
+		target **resource.Quantity
+	}{
+		memoryRequestOptionName: {c.MemoryRequest, &vmOverrides.MemoryRequest},
+		memoryLimitOptionName:   {c.MemoryLimit, &vmOverrides.MemoryLimit},
+	} {
+		if value := strings.TrimSpace(option.value); value != "" {
+			quantity, err := resource.ParseQuantity(value)
+			if err != nil {
+				return nil, zerrors.NewMissingRequiredError("invalid %v: %v", optionName, err.Error())
+			}
+			*option.target = &quantity
+		}
+	}
+
+	if c.GetCreationMode() == constants.InstancetypeCreationMode &&
+		(c.CPUSockets != "" || c.CPUCores != "" || c.CPUThreads != "" || c.MemoryRequest != "" || c.MemoryLimit != "") {
+		return nil, zerrors.NewMissingRequiredError("%v, %v, %v, %v, %v options are not applicable for %v", cpuSocketsOptionName, cpuCoresOptionName,
+			cpuThreadsOptionName, memoryRequestOptionName, memoryLimitOptionName, instancetypeOptionName)
+	}
+
+	if nodeSelector := strings.TrimSpace(c.NodeSelector); nodeSelector != "" {
+		selector, err := labels.ConvertSelectorToLabelsMap(nodeSelector)
+		if err != nil {
+			return nil, zerrors.NewMissingRequiredError("invalid %v: %v", nodeSelectorOptionName, err.Error())
+		}
+		vmOverrides.NodeSelector = selector
+	}
+
+	if tolerations := strings.TrimSpace(c.Tolerations); tolerations != "" {
+		if err := yaml.UnmarshalStrict([]byte(tolerations), &vmOverrides.Tolerations); err != nil {
+			return nil, zerrors.NewMissingRequiredError("invalid %v: %v", tolerationsOptionName, err.Error())
+		}
+	}
+
+	if affinity := strings.TrimSpace(c.Affinity); affinity != "" {
+		if err := yaml.UnmarshalStrict([]byte(affinity), &vmOverrides.Affinity); err != nil {
+			return nil, zerrors.NewMissingRequiredError("invalid %v: %v", affinityOptionName, err.Error())
+		}
+	}
+
+	if evictionStrategy := kubevirtv1.EvictionStrategy(strings.TrimSpace(c.EvictionStrategy)); evictionStrategy != "" {
+		switch evictionStrategy {
+		case kubevirtv1.EvictionStrategyNone, kubevirtv1.EvictionStrategyLiveMigrate, kubevirtv1.EvictionStrategyLiveMigrateIfPossible, kubevirtv1.EvictionStrategyExternal:
+			vmOverrides.EvictionStrategy = &evictionStrategy
+		default:
+			return nil, zerrors.NewMissingRequiredError("%v is not a valid %v, only %v|%v|%v|%v is allowed", evictionStrategy, evictionStrategyOptionName,
+				kubevirtv1.EvictionStrategyNone, kubevirtv1.EvictionStrategyLiveMigrate, kubevirtv1.EvictionStrategyLiveMigrateIfPossible, kubevirtv1.EvictionStrategyExternal)
+		}
+	}
+
+	if vmOverrides.PriorityClassName != "" {
+		if errs := validation.IsDNS1123Subdomain(vmOverrides.PriorityClassName); len(errs) > 0 {
+			return nil, zerrors.NewMissingRequiredError("%v is not a valid name: %v", priorityClassNameOptionName, strings.Join(errs, ";"))
+		}
+	}
+
+	return vmOverrides, nil
+}
+
+// parseDataVolumeTemplateOverrides converts the source and storage options. Nil is returned when none of them is specified.
+func (c *CLIOptions) parseDataVolumeTemplateOverrides() (*datavolume.TemplateOverrides, error) {
+	dvOverrides := &datavolume.TemplateOverrides{
+		Name:         strings.TrimSpace(c.DataVolumeTemplateName),
+		StorageClass: strings.TrimSpace(c.StorageClass),
+	}
+
+	sourceDataSource, sourcePVC, sourceSnapshot := strings.TrimSpace(c.SourceDataSource), strings.TrimSpace(c.SourcePVC), strings.TrimSpace(c.SourceSnapshot)
+	accessModes, volumeMode, storageSize := strings.TrimSpace(c.AccessModes), strings.TrimSpace(c.VolumeMode), strings.TrimSpace(c.StorageSize)
+
+	if sourceDataSource == "" && sourcePVC == "" && sourceSnapshot == "" && dvOverrides.StorageClass == "" && accessModes == "" && volumeMode == "" && storageSize == "" {
+		if dvOverrides.Name != "" {
+			return nil, zerrors.NewMissingRequiredError("%v option is applicable only for %v, %v, %v, %v, %v, %v, %v", dvTemplateNameOptionName, sourceDataSourceOptionName,
+				sourcePVCOptionName, sourceSnapshotOptionName, storageClassOptionName, accessModesOptionName, volumeModeOptionName, storageSizeOptionName)
+		}
+		return nil, nil
+	}
+
+	if c.GetCreationMode() == constants.InstancetypeCreationMode {
+		return nil, zerrors.NewMissingRequiredError("%v, %v, %v, %v, %v, %v, %v, %v options are not applicable for %v", dvTemplateNameOptionName, sourceDataSourceOptionName,
+			sourcePVCOptionName, sourceSnapshotOptionName, storageClassOptionName, accessModesOptionName, volumeModeOptionName, storageSizeOptionName, instancetypeOptionName)
+	}
+
+	sourcesCount := 0
+	for _, source := range []string{sourceDataSource, sourcePVC, sourceSnapshot} {
+		if source != "" {
+			sourcesCount++
+		}
+	}
+	if sourcesCount > 1 {
+		return nil, zerrors.NewMissingRequiredError("only one of %v, %v or %v should be specified", sourceDataSourceOptionName, sourcePVCOptionName, sourceSnapshotOptionName)
+	}
+
+	for optionName, name := range map[string]string{dvTemplateNameOptionName: dvOverrides.Name, storageClassOptionName: dvOverrides.StorageClass} {
+		if name == "" {
+			continue
+		}
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			return nil, zerrors.NewMissingRequiredError("%v is not a valid name: %v", optionName, strings.Join(errs, ";"))
+		}
+	}
+
+	if sourceDataSource != "" {
+		namespace, name, err := parseNamespacedName(sourceDataSource)
+		if err != nil {
+			return nil, zerrors.NewMissingRequiredError("invalid %v: %v", sourceDataSourceOptionName, err.Error())
+		}
+		dvOverrides.SourceRef = &cdiv1beta1.DataVolumeSourceRef{
+			Kind: constants.DataSourceKind,
+			Name: name,
+		}
+		if namespace != "" {
+			dvOverrides.SourceRef.Namespace = &namespace
+		}
+	}
+
+	if sourcePVC != "" {
+		namespace, name, err := parseNamespacedName(sourcePVC)
+		if err != nil {
+			return nil, zerrors.NewMissingRequiredError("invalid %v: %v", sourcePVCOptionName, err.Error())
+		}
+		dvOverrides.PVC = &cdiv1beta1.DataVolumeSourcePVC{Namespace: namespace, Name: name}
+	}
+
+	if sourceSnapshot != "" {
+		namespace, name, err := parseNamespacedName(sourceSnapshot)
+		if err != nil {
+			return nil, zerrors.NewMissingRequiredError("invalid %v: %v", sourceSnapshotOptionName, err.Error())
+		}
+		dvOverrides.Snapshot = &cdiv1beta1.DataVolumeSourceSnapshot{Namespace: namespace, Name: name}
+	}
+
+	for _, accessMode := range strings.Split(accessModes, accessModesSep) {
+		accessMode := corev1.PersistentVolumeAccessMode(strings.TrimSpace(accessMode))
+		if accessMode == "" {
+			continue
+		}
+		switch accessMode {
+		case corev1.ReadWriteOnce, corev1.ReadOnlyMany, corev1.ReadWriteMany, corev1.ReadWriteOncePod:
+			dvOverrides.AccessModes = append(dvOverrides.AccessModes, accessMode)
+		default:
+			return nil, zerrors.NewMissingRequiredError("%v is not a valid access mode, only %v|%v|%v|%v is allowed", accessMode,
+				corev1.ReadWriteOnce, corev1.ReadOnlyMany, corev1.ReadWriteMany, corev1.ReadWriteOncePod)
+		}
+	}
+
+	if volumeMode != "" {
+		mode := corev1.PersistentVolumeMode(volumeMode)
+		if mode != corev1.PersistentVolumeFilesystem && mode != corev1.PersistentVolumeBlock {
+			return nil, zerrors.NewMissingRequiredError("%v is not a valid %v, only %v|%v is allowed", mode, volumeModeOptionName,
+				corev1.PersistentVolumeFilesystem, corev1.PersistentVolumeBlock)
+		}
+		dvOverrides.VolumeMode = &mode
+	}
+
+	if storageSize != "" {
+		size, err := resource.ParseQuantity(storageSize)
+		if err != nil {
+			return nil, zerrors.NewMissingRequiredError("invalid %v: %v", storageSizeOptionName, err.Error())
+		}
+		if size.Sign() <= 0 {
+			return nil, zerrors.NewMissingRequiredError("%v should be a positive quantity", storageSizeOptionName)
+		}
+		dvOverrides.Size = &size
+	}
+
+	return dvOverrides, nil
+}
+
+// parseVirtctlArgs parses flags of the virtctl create vm command, so unknown flags are reported before running virtctl
+func (c *CLIOptions) parseVirtctlArgs() ([]string, error) {
+	args, err := virtctl.ParseArgs(c.Virtctl)
+	if err != nil {
+		return nil, zerrors.NewMissingRequiredError("invalid %v: %v", virtctlOptionName, err.Error())
+	}
+
+	if err := virtctl.ValidateFlags(args); err != nil {
+		return nil, zerrors.NewMissingRequiredError("invalid %v: %v", virtctlOptionName, err.Error())
+	}
+	return args, nil
+}
+
 func (c *CLIOptions) trimSpaces() {
-	for _, strVariablePtr := range []*string{&c.TemplateName, &c.TemplateNamespace, &c.VirtualMachineNamespace} {
+	for _, strVariablePtr := range []*string{&c.TemplateName, &c.TemplateSelector, &c.TemplateNamespace, &c.VirtualMachineNamespace, &c.VirtualMachineName,
+		&c.Instancetype, &c.InstancetypeKind, &c.Preference, &c.PreferenceKind, &c.DataSourceName, &c.DataSourceNamespace, &c.DiskSize, &c.DryRun, &c.Count, &c.Parallelism, &c.ServiceType,
+		&c.SSHPublicKeySecret, &c.SSHPropagationMethod, &c.UserDataSecret, &c.NetworkDataSecret, &c.VirtualMachineSnapshot, &c.CloneSourceVM} {
 		*strVariablePtr = strings.TrimSpace(*strVariablePtr)
 	}
 }
@@ -57,14 +543,15 @@ func (c *CLIOptions) resolveDefaultNamespacesAndManifests() error {
 			}
 		}
 	} else if c.GetCreationMode() == constants.VMManifestCreationMode {
+		vmBundle, err := bundle.Parse(c.VirtualMachineManifest)
+		if err != nil {
+			return err
+		}
+		c.vmBundle = vmBundle
+
 		vmNamespace := c.GetVirtualMachineNamespace()
 		if vmNamespace == "" {
-			var vm kubevirtv1.VirtualMachine
-
-			if err := yaml.Unmarshal([]byte(c.VirtualMachineManifest), &vm); err != nil {
-				return zerrors.NewMissingRequiredError("could not read VM manifest: %v", err.Error())
-			}
-			if vm.Namespace != "" {
+			if vm := vmBundle.VM; vm.Namespace != "" {
 				c.VirtualMachineNamespace = vm.Namespace
 			} else {
 				activeNamespace, err := env.GetActiveNamespace()
@@ -74,7 +561,60 @@ func (c *CLIOptions) resolveDefaultNamespacesAndManifests() error {
 				c.VirtualMachineNamespace = activeNamespace
 			}
 		}
+	} else if mode := c.GetCreationMode(); mode == constants.VirtctlCreatingMode || mode == constants.InstancetypeCreationMode || mode == constants.SnapshotCreationMode ||
+		mode == constants.CloneCreationMode {
+		if c.GetVirtualMachineNamespace() == "" {
+			activeNamespace, err := env.GetActiveNamespace()
+			if err != nil {
+				return zerrors.NewMissingRequiredError("%v: %v option is empty", err.Error(), vmNamespaceOptionName)
+			}
+			c.VirtualMachineNamespace = activeNamespace
+		}
+		if mode == constants.InstancetypeCreationMode && c.GetDataSourceNamespace() == "" {
+			c.DataSourceNamespace = c.GetVirtualMachineNamespace()
+		}
 	}
 
 	return nil
 }
+
+// parseTemplateSelector translates os, workload, flavor and architecture keys of the selector to template labels
+func parseTemplateSelector(selector string) (string, error) {
+	labelSet := labels.Set{}
+
+	for _, term := range strings.Split(selector, templateSelectorSep) {
+		if term = strings.TrimSpace(term); term == "" {
+			continue
+		}
+
+		keyValue := strings.SplitN(term, templateSelectorKeyValueSep, 2)
+		if len(keyValue) != 2 || strings.TrimSpace(keyValue[0]) == "" {
+			return "", fmt.Errorf("%v should be in \"KEY=VAL\" format", term)
+		}
+		key, value := strings.TrimSpace(keyValue[0]), strings.TrimSpace(keyValue[1])
+
+		switch key {
+		case osSelectorKey:
+			labelSet[lab.TemplateOsLabel+"/"+value] = zconstants.True
+		case workloadSelectorKey:
+			labelSet[lab.TemplateWorkloadLabel+"/"+value] = zconstants.True
+		case flavorSelectorKey:
+			labelSet[lab.TemplateFlavorLabel+"/"+value] = zconstants.True
+		case architectureSelectorKey:
+			labelSet[lab.TemplateArchitectureLabel] = value
+		default:
+			labelSet[key] = value
+		}
+	}
+
+	if len(labelSet) == 0 {
+		return "", fmt.Errorf("selector is empty")
+	}
+
+	parsedSelector, err := labels.ValidatedSelectorFromSet(labelSet)
+	if err != nil {
+		return "", err
+	}
+
+	return parsedSelector.String(), nil
+}
@@ -0,0 +1,86 @@
+package virtctl
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/options"
+	"kubevirt.io/kubevirt/pkg/virtctl/create"
+	createvm "kubevirt.io/kubevirt/pkg/virtctl/create/vm"
+	"sigs.k8s.io/yaml"
+)
+
+// ParseArgs parses flags of the virtctl create vm command. The flags can be a YAML or JSON list with one argument per item
+// or a string with arguments separated by spaces. Arguments of the string can be quoted or escaped as in a shell.
+func ParseArgs(value string) ([]string, error) {
+	value = strings.TrimSpace(value)
+	if isList(value) {
+		var args []string
+		if err := yaml.Unmarshal([]byte(value), &args); err != nil {
+			return nil, fmt.Errorf("could not parse list of flags: %v", err.Error())
+		}
+		return args, nil
+	}
+
+	opts, err := options.NewCommandOptions(value)
+	if err != nil {
+		return nil, err
+	}
+	return opts.GetAll(), nil
+}
+
+// ValidateFlags checks that all flags in the arguments are supported by the virtctl create vm command
+func ValidateFlags(args []string) error {
+	flags := createvm.NewCommand().Flags()
+
+	var unknownFlags []string
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			continue
+		}
+
+		name := strings.SplitN(strings.TrimLeft(arg, "-"), "=", 2)[0]
+		if strings.HasPrefix(arg, "--") {
+			if flags.Lookup(name) == nil {
+				unknownFlags = append(unknownFlags, "--"+name)
+			}
+		} else if flags.ShorthandLookup(name[:1]) == nil {
+			unknownFlags = append(unknownFlags, "-"+name[:1])
+		}
+	}
+
+	if len(unknownFlags) > 0 {
+		sort.Strings(unknownFlags)
+		return fmt.Errorf("unknown flags of virtctl create vm command: %v", strings.Join(unknownFlags, ", "))
+	}
+	return nil
+}
+
+// Run runs the virtctl create vm command and returns the manifest of the VM.
+// The error contains the error output of virtctl.
+func Run(args []string) ([]byte, error) {
+	output, errOutput := &bytes.Buffer{}, &bytes.Buffer{}
+
+	cmd := create.NewCommand()
+	cmd.SetArgs(append([]string{createvm.VM}, args...))
+	cmd.SetOut(output)
+	cmd.SetErr(errOutput)
+	// the output should contain only the manifest
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+
+	if err := cmd.Execute(); err != nil {
+		if message := strings.TrimSpace(errOutput.String()); message != "" {
+			return nil, fmt.Errorf("%v: %v", err.Error(), message)
+		}
+		return nil, err
+	}
+
+	return output.Bytes(), nil
+}
+
+func isList(value string) bool {
+	return strings.HasPrefix(value, "[") || strings.HasPrefix(value, "- ") || strings.HasPrefix(value, "-\n")
+}
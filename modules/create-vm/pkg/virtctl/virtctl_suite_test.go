@@ -0,0 +1,17 @@
+package virtctl_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/utilstest"
+)
+
+func TestVirtctl(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Virtctl Suite")
+}
+
+var _ = BeforeSuite(utilstest.SetupTestSuite)
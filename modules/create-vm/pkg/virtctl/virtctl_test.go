@@ -0,0 +1,70 @@
+package virtctl_test
+
+import (
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/virtctl"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+var _ = Describe("Virtctl", func() {
+	DescribeTable("ParseArgs parses flags", func(value string, expectedArgs []string) {
+		args, err := virtctl.ParseArgs(value)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(args).To(Equal(expectedArgs))
+	},
+		Entry("string", " --name vm  --memory=1Gi ", []string{"--name", "vm", "--memory=1Gi"}),
+		Entry("quoted string", `--name vm --volume-import "type:blank,size:1Gi,name:data disk" --cloud-init-user-data='a b'`,
+			[]string{"--name", "vm", "--volume-import", "type:blank,size:1Gi,name:data disk", "--cloud-init-user-data=a b"}),
+		Entry("escaped string", `--volume-import type:blank,size:1Gi,name:data\ disk`, []string{"--volume-import", "type:blank,size:1Gi,name:data disk"}),
+		Entry("JSON list", `["--name", "vm", "--volume-import=type:blank,size:1Gi,name:data disk"]`,
+			[]string{"--name", "vm", "--volume-import=type:blank,size:1Gi,name:data disk"}),
+		Entry("YAML list", "- --name=vm\n- --volume-import=type:blank,size:1Gi,name:data disk\n",
+			[]string{"--name=vm", "--volume-import=type:blank,size:1Gi,name:data disk"}),
+		Entry("empty", "", []string{}),
+	)
+
+	DescribeTable("ParseArgs fails", func(value, expectedErrMessage string) {
+		_, err := virtctl.ParseArgs(value)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(expectedErrMessage))
+	},
+		Entry("unclosed quote", `--name "vm`, "EOF found when expecting closing quote"),
+		Entry("invalid list", `["--name", "vm"`, "could not parse list of flags"),
+	)
+
+	DescribeTable("ValidateFlags accepts flags of virtctl create vm", func(args []string) {
+		Expect(virtctl.ValidateFlags(args)).To(Succeed())
+	},
+		Entry("no flags", []string{}),
+		Entry("flags with values", []string{"--name", "vm", "--run-strategy=Always", "--volume-import", "type:blank,size:1Gi"}),
+		Entry("flag without value", []string{"--infer-instancetype", "--infer-preference"}),
+	)
+
+	DescribeTable("ValidateFlags rejects unknown flags", func(args []string, expectedErrMessage string) {
+		err := virtctl.ValidateFlags(args)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(Equal(expectedErrMessage))
+	},
+		Entry("long flags", []string{"--name", "vm", "--volume", "disk", "--namespace=default"}, "unknown flags of virtctl create vm command: --namespace, --volume"),
+		Entry("short flag", []string{"-n", "default"}, "unknown flags of virtctl create vm command: -n"),
+	)
+
+	It("Run creates a VM manifest", func() {
+		output, err := virtctl.Run([]string{"--name", "vm", "--volume-import", "type:blank,size:1Gi,name:data-disk"})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		var vm kubevirtv1.VirtualMachine
+		Expect(yaml.Unmarshal(output, &vm)).To(Succeed())
+		Expect(vm.Name).To(Equal("vm"))
+		Expect(vm.Spec.DataVolumeTemplates).To(HaveLen(1))
+		Expect(vm.Spec.DataVolumeTemplates[0].Name).To(Equal("data-disk"))
+	})
+
+	It("Run returns the error of virtctl", func() {
+		_, err := virtctl.Run([]string{"--volume-import", "type:unknown"})
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("failed to parse \"--volume-import\" flag"))
+	})
+})
@@ -0,0 +1,159 @@
+package vm
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+const CloudInitDiskName = "cloudinitdisk"
+
+// KubeVirt requires cloud-init volumes to have some user data
+const emptyUserData = "#cloud-config\n"
+
+type PropagationMethod string
+
+const (
+	ConfigDrivePropagation    PropagationMethod = "configDrive"
+	QemuGuestAgentPropagation PropagationMethod = "qemuGuestAgent"
+	// NoCloudPropagation is not supported by the vendored KubeVirt API version
+	NoCloudPropagation PropagationMethod = "noCloud"
+)
+
+// AddSSHPublicKey adds an access credential with the public key secret to the VM.
+// The configDrive propagation method also requires a cloudInitConfigDrive volume which is added when missing.
+func AddSSHPublicKey(vm *kubevirtv1.VirtualMachine, secretName string, method PropagationMethod, users []string) error {
+	spec := ensureTemplateSpec(vm)
+
+	propagationMethod := kubevirtv1.SSHPublicKeyAccessCredentialPropagationMethod{}
+	switch method {
+	case ConfigDrivePropagation:
+		if _, err := ensureCloudInitVolume(vm, true); err != nil {
+			return err
+		}
+		propagationMethod.ConfigDrive = &kubevirtv1.ConfigDriveSSHPublicKeyAccessCredentialPropagation{}
+	case QemuGuestAgentPropagation:
+		propagationMethod.QemuGuestAgent = &kubevirtv1.QemuGuestAgentSSHPublicKeyAccessCredentialPropagation{
+			Users: users,
+		}
+	default:
+		return fmt.Errorf("unsupported propagation method %v", method)
+	}
+
+	for _, accessCredential := range spec.AccessCredentials {
+		if sshPublicKey := accessCredential.SSHPublicKey; sshPublicKey != nil && sshPublicKey.Source.Secret != nil && sshPublicKey.Source.Secret.SecretName == secretName {
+			return nil
+		}
+	}
+
+	spec.AccessCredentials = append(spec.AccessCredentials, kubevirtv1.AccessCredential{
+		SSHPublicKey: &kubevirtv1.SSHPublicKeyAccessCredential{
+			Source: kubevirtv1.SSHPublicKeyAccessCredentialSource{
+				Secret: &kubevirtv1.AccessCredentialSecretSource{
+					SecretName: secretName,
+				},
+			},
+			PropagationMethod: propagationMethod,
+		},
+	})
+
+	return nil
+}
+
+// AddCloudInitSecrets references cloud-init user data and network data secrets from the cloud-init volume of the VM.
+// Inline data of the volume is replaced. A volume is added when missing, with configDrive type if requested or noCloud type otherwise.
+func AddCloudInitSecrets(vm *kubevirtv1.VirtualMachine, userDataSecretName, networkDataSecretName string, configDrive bool) error {
+	volume, err := ensureCloudInitVolume(vm, configDrive)
+	if err != nil {
+		return err
+	}
+
+	if source := volume.CloudInitConfigDrive; source != nil {
+		if userDataSecretName != "" {
+			source.UserData, source.UserDataBase64 = "", ""
+			source.UserDataSecretRef = &corev1.LocalObjectReference{Name: userDataSecretName}
+		}
+		if networkDataSecretName != "" {
+			source.NetworkData, source.NetworkDataBase64 = "", ""
+			source.NetworkDataSecretRef = &corev1.LocalObjectReference{Name: networkDataSecretName}
+		}
+	} else if source := volume.CloudInitNoCloud; source != nil {
+		if userDataSecretName != "" {
+			source.UserData, source.UserDataBase64 = "", ""
+			source.UserDataSecretRef = &corev1.LocalObjectReference{Name: userDataSecretName}
+		}
+		if networkDataSecretName != "" {
+			source.NetworkData, source.NetworkDataBase64 = "", ""
+			source.NetworkDataSecretRef = &corev1.LocalObjectReference{Name: networkDataSecretName}
+		}
+	}
+
+	return nil
+}
+
+// ensureCloudInitVolume returns the cloud-init volume of the VM or adds a new one. A matching disk is added when missing.
+// A disk is not added when the VM does not specify any disks, so KubeVirt or a preference can choose it.
+func ensureCloudInitVolume(vm *kubevirtv1.VirtualMachine, configDrive bool) (*kubevirtv1.Volume, error) {
+	spec := ensureTemplateSpec(vm)
+
+	for i := range spec.Volumes {
+		volume := &spec.Volumes[i]
+		if volume.CloudInitNoCloud != nil && configDrive {
+			return nil, fmt.Errorf("%v propagation method requires a cloudInitConfigDrive volume, but %v volume is cloudInitNoCloud", ConfigDrivePropagation, volume.Name)
+		}
+		if volume.CloudInitConfigDrive != nil || volume.CloudInitNoCloud != nil {
+			ensureDisk(spec, volume.Name)
+			return volume, nil
+		}
+	}
+
+	volume := kubevirtv1.Volume{Name: CloudInitDiskName}
+	if configDrive {
+		volume.CloudInitConfigDrive = &kubevirtv1.CloudInitConfigDriveSource{UserData: emptyUserData}
+	} else {
+		volume.CloudInitNoCloud = &kubevirtv1.CloudInitNoCloudSource{UserData: emptyUserData}
+	}
+
+	for _, existingVolume := range spec.Volumes {
+		if existingVolume.Name == volume.Name {
+			return nil, fmt.Errorf("could not add %v volume: volume with the same name already exists", volume.Name)
+		}
+	}
+
+	for _, disk := range spec.Domain.Devices.Disks {
+		if disk.Name == volume.Name {
+			return nil, fmt.Errorf("could not add %v volume: disk with the same name already exists", volume.Name)
+		}
+	}
+
+	ensureDisk(spec, volume.Name)
+	spec.Volumes = append(spec.Volumes, volume)
+	return &spec.Volumes[len(spec.Volumes)-1], nil
+}
+
+func ensureDisk(spec *kubevirtv1.VirtualMachineInstanceSpec, volumeName string) {
+	if len(spec.Domain.Devices.Disks) == 0 {
+		return
+	}
+
+	for _, disk := range spec.Domain.Devices.Disks {
+		if disk.Name == volumeName {
+			return
+		}
+	}
+
+	spec.Domain.Devices.Disks = append(spec.Domain.Devices.Disks, kubevirtv1.Disk{
+		Name: volumeName,
+		DiskDevice: kubevirtv1.DiskDevice{
+			Disk: &kubevirtv1.DiskTarget{},
+		},
+	})
+}
+
+func ensureTemplateSpec(vm *kubevirtv1.VirtualMachine) *kubevirtv1.VirtualMachineInstanceSpec {
+	if vm.Spec.Template == nil {
+		vm.Spec.Template = &kubevirtv1.VirtualMachineInstanceTemplateSpec{}
+	}
+	return &vm.Spec.Template.Spec
+}
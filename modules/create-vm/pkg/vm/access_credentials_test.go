@@ -0,0 +1,126 @@
+package vm_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	vm2 "github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/vm"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/sharedtest/testobjects"
+)
+
+var _ = Describe("Access credentials", func() {
+	var vm *kubevirtv1.VirtualMachine
+
+	BeforeEach(func() {
+		vm = testobjects.NewTestVM().Build()
+		vm.Spec.Template.Spec.Domain.Devices.Disks = []kubevirtv1.Disk{{Name: "rootdisk"}}
+		vm.Spec.Template.Spec.Volumes = []kubevirtv1.Volume{{Name: "rootdisk"}}
+	})
+
+	Describe("SSH public key", func() {
+		It("adds configDrive access credential with a volume and a disk", func() {
+			Expect(vm2.AddSSHPublicKey(vm, "my-key", vm2.ConfigDrivePropagation, nil)).To(Succeed())
+
+			spec := vm.Spec.Template.Spec
+			Expect(spec.AccessCredentials).To(HaveLen(1))
+			Expect(spec.AccessCredentials[0].SSHPublicKey.Source.Secret.SecretName).To(Equal("my-key"))
+			Expect(spec.AccessCredentials[0].SSHPublicKey.PropagationMethod.ConfigDrive).ToNot(BeNil())
+			Expect(spec.Volumes).To(HaveLen(2))
+			Expect(spec.Volumes[1].Name).To(Equal(vm2.CloudInitDiskName))
+			Expect(spec.Volumes[1].CloudInitConfigDrive).ToNot(BeNil())
+			Expect(spec.Domain.Devices.Disks).To(HaveLen(2))
+			Expect(spec.Domain.Devices.Disks[1].Name).To(Equal(vm2.CloudInitDiskName))
+		})
+
+		It("adds qemuGuestAgent access credential without a volume", func() {
+			Expect(vm2.AddSSHPublicKey(vm, "my-key", vm2.QemuGuestAgentPropagation, []string{"fedora"})).To(Succeed())
+
+			spec := vm.Spec.Template.Spec
+			Expect(spec.AccessCredentials).To(HaveLen(1))
+			Expect(spec.AccessCredentials[0].SSHPublicKey.PropagationMethod.QemuGuestAgent.Users).To(Equal([]string{"fedora"}))
+			Expect(spec.Volumes).To(HaveLen(1))
+		})
+
+		It("reuses existing configDrive volume and credential and adds a missing disk", func() {
+			vm.Spec.Template.Spec.Volumes = append(vm.Spec.Template.Spec.Volumes, kubevirtv1.Volume{
+				Name:         "cloudinit",
+				VolumeSource: kubevirtv1.VolumeSource{CloudInitConfigDrive: &kubevirtv1.CloudInitConfigDriveSource{UserData: "#cloud-config"}},
+			})
+
+			Expect(vm2.AddSSHPublicKey(vm, "my-key", vm2.ConfigDrivePropagation, nil)).To(Succeed())
+			Expect(vm2.AddSSHPublicKey(vm, "my-key", vm2.ConfigDrivePropagation, nil)).To(Succeed())
+
+			spec := vm.Spec.Template.Spec
+			Expect(spec.AccessCredentials).To(HaveLen(1))
+			Expect(spec.Volumes).To(HaveLen(2))
+			Expect(spec.Domain.Devices.Disks).To(HaveLen(2))
+			Expect(spec.Domain.Devices.Disks[1].Name).To(Equal("cloudinit"))
+		})
+
+		It("does not add a disk when the VM does not specify disks", func() {
+			vm.Spec.Template.Spec.Domain.Devices.Disks = nil
+
+			Expect(vm2.AddSSHPublicKey(vm, "my-key", vm2.ConfigDrivePropagation, nil)).To(Succeed())
+			Expect(vm.Spec.Template.Spec.Volumes).To(HaveLen(2))
+			Expect(vm.Spec.Template.Spec.Domain.Devices.Disks).To(BeEmpty())
+		})
+
+		It("fails with configDrive propagation and noCloud volume", func() {
+			vm.Spec.Template.Spec.Volumes = append(vm.Spec.Template.Spec.Volumes, kubevirtv1.Volume{
+				Name:         "cloudinit",
+				VolumeSource: kubevirtv1.VolumeSource{CloudInitNoCloud: &kubevirtv1.CloudInitNoCloudSource{}},
+			})
+
+			err := vm2.AddSSHPublicKey(vm, "my-key", vm2.ConfigDrivePropagation, nil)
+			Expect(err).To(MatchError("configDrive propagation method requires a cloudInitConfigDrive volume, but cloudinit volume is cloudInitNoCloud"))
+		})
+
+		It("fails when the volume name is taken", func() {
+			vm.Spec.Template.Spec.Volumes = append(vm.Spec.Template.Spec.Volumes, kubevirtv1.Volume{Name: vm2.CloudInitDiskName})
+
+			err := vm2.AddSSHPublicKey(vm, "my-key", vm2.ConfigDrivePropagation, nil)
+			Expect(err).To(MatchError("could not add cloudinitdisk volume: volume with the same name already exists"))
+		})
+	})
+
+	Describe("Cloud-init secrets", func() {
+		It("adds noCloud volume with secrets", func() {
+			Expect(vm2.AddCloudInitSecrets(vm, "user-data", "network-data", false)).To(Succeed())
+
+			volume := vm.Spec.Template.Spec.Volumes[1]
+			Expect(volume.CloudInitNoCloud).To(Equal(&kubevirtv1.CloudInitNoCloudSource{
+				UserDataSecretRef:    &corev1.LocalObjectReference{Name: "user-data"},
+				NetworkDataSecretRef: &corev1.LocalObjectReference{Name: "network-data"},
+			}))
+		})
+
+		It("adds configDrive volume with secrets", func() {
+			Expect(vm2.AddCloudInitSecrets(vm, "user-data", "", true)).To(Succeed())
+
+			volume := vm.Spec.Template.Spec.Volumes[1]
+			Expect(volume.CloudInitConfigDrive).To(Equal(&kubevirtv1.CloudInitConfigDriveSource{
+				UserDataSecretRef: &corev1.LocalObjectReference{Name: "user-data"},
+			}))
+		})
+
+		It("replaces inline data of existing volume", func() {
+			vm.Spec.Template.Spec.Volumes = append(vm.Spec.Template.Spec.Volumes, kubevirtv1.Volume{
+				Name: "cloudinit",
+				VolumeSource: kubevirtv1.VolumeSource{CloudInitNoCloud: &kubevirtv1.CloudInitNoCloudSource{
+					UserData:    "#cloud-config",
+					NetworkData: "version: 2",
+				}},
+			})
+
+			Expect(vm2.AddCloudInitSecrets(vm, "user-data", "", false)).To(Succeed())
+
+			Expect(vm.Spec.Template.Spec.Volumes).To(HaveLen(2))
+			Expect(vm.Spec.Template.Spec.Volumes[1].CloudInitNoCloud).To(Equal(&kubevirtv1.CloudInitNoCloudSource{
+				UserDataSecretRef: &corev1.LocalObjectReference{Name: "user-data"},
+				NetworkData:       "version: 2",
+			}))
+		})
+	})
+})
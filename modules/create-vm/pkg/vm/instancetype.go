@@ -0,0 +1,64 @@
+package vm
+
+import (
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	cdiv1beta1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+)
+
+// NewInstancetypeVM builds a VM which boots from a clone of the dataSourceRef.
+// Disks are intentionally not specified, so the preferred disk bus of the preference is applied by KubeVirt.
+func NewInstancetypeVM(name string, instancetype *kubevirtv1.InstancetypeMatcher, preference *kubevirtv1.PreferenceMatcher, dataSourceRef *cdiv1beta1.DataVolumeSourceRef, diskSize *resource.Quantity) *kubevirtv1.VirtualMachine {
+	runStrategy := kubevirtv1.RunStrategyHalted
+	dataVolumeName := name + "-" + constants.RootDiskName
+
+	storage := &cdiv1beta1.StorageSpec{}
+	if diskSize != nil {
+		storage.Resources.Requests = corev1.ResourceList{
+			corev1.ResourceStorage: *diskSize,
+		}
+	}
+
+	return &kubevirtv1.VirtualMachine{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: kubevirtv1.GroupVersion.String(),
+			Kind:       kubevirtv1.VirtualMachineGroupVersionKind.Kind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: kubevirtv1.VirtualMachineSpec{
+			RunStrategy:  &runStrategy,
+			Instancetype: instancetype,
+			Preference:   preference,
+			DataVolumeTemplates: []kubevirtv1.DataVolumeTemplateSpec{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: dataVolumeName,
+					},
+					Spec: cdiv1beta1.DataVolumeSpec{
+						SourceRef: dataSourceRef,
+						Storage:   storage,
+					},
+				},
+			},
+			Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+				Spec: kubevirtv1.VirtualMachineInstanceSpec{
+					Volumes: []kubevirtv1.Volume{
+						{
+							Name: constants.RootDiskName,
+							VolumeSource: kubevirtv1.VolumeSource{
+								DataVolume: &kubevirtv1.DataVolumeSource{
+									Name: dataVolumeName,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
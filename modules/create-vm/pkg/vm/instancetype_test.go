@@ -0,0 +1,55 @@
+package vm_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	cdiv1beta1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+
+	vm2 "github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/vm"
+)
+
+var _ = Describe("Instancetype VM", func() {
+	var (
+		instancetype  *kubevirtv1.InstancetypeMatcher
+		preference    *kubevirtv1.PreferenceMatcher
+		dataSourceRef *cdiv1beta1.DataVolumeSourceRef
+	)
+
+	BeforeEach(func() {
+		dataSourceNamespace := "images"
+		instancetype = &kubevirtv1.InstancetypeMatcher{Name: "u1.small", Kind: "VirtualMachineClusterInstancetype"}
+		preference = &kubevirtv1.PreferenceMatcher{Name: "fedora", Kind: "VirtualMachineClusterPreference"}
+		dataSourceRef = &cdiv1beta1.DataVolumeSourceRef{Kind: "DataSource", Name: "fedora", Namespace: &dataSourceNamespace}
+	})
+
+	It("Creates VM booting from the data source", func() {
+		vm := vm2.NewInstancetypeVM("my-vm", instancetype, preference, dataSourceRef, nil)
+
+		Expect(vm.Name).To(Equal("my-vm"))
+		Expect(vm.Spec.Instancetype).To(Equal(instancetype))
+		Expect(vm.Spec.Preference).To(Equal(preference))
+		Expect(*vm.Spec.RunStrategy).To(Equal(kubevirtv1.RunStrategyHalted))
+
+		Expect(vm.Spec.DataVolumeTemplates).To(HaveLen(1))
+		dataVolumeTemplate := vm.Spec.DataVolumeTemplates[0]
+		Expect(dataVolumeTemplate.Name).To(Equal("my-vm-rootdisk"))
+		Expect(dataVolumeTemplate.Spec.SourceRef).To(Equal(dataSourceRef))
+		Expect(dataVolumeTemplate.Spec.Storage.Resources.Requests).To(BeEmpty())
+
+		Expect(vm.Spec.Template.Spec.Volumes).To(HaveLen(1))
+		Expect(vm.Spec.Template.Spec.Volumes[0].Name).To(Equal("rootdisk"))
+		Expect(vm.Spec.Template.Spec.Volumes[0].DataVolume.Name).To(Equal("my-vm-rootdisk"))
+		Expect(vm.Spec.Template.Spec.Domain.Devices.Disks).To(BeEmpty())
+	})
+
+	It("Requests disk size", func() {
+		diskSize := resource.MustParse("30Gi")
+		vm := vm2.NewInstancetypeVM("my-vm", instancetype, nil, dataSourceRef, &diskSize)
+
+		Expect(vm.Spec.Preference).To(BeNil())
+		Expect(vm.Spec.DataVolumeTemplates[0].Spec.Storage.Resources.Requests).To(HaveKeyWithValue(corev1.ResourceStorage, diskSize))
+	})
+})
@@ -0,0 +1,62 @@
+package vm
+
+import (
+	"fmt"
+	"strings"
+
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+// NewReplica returns a copy of the VM with a name derived from the name or generateName of the VM and the index.
+// Labels and DataVolume templates derived from the VM name are renamed as well,
+// the remaining DataVolume templates get the index as a suffix, so the replicas do not share disks.
+func NewReplica(vm *kubevirtv1.VirtualMachine, index int) *kubevirtv1.VirtualMachine {
+	replica := vm.DeepCopy()
+	suffix := fmt.Sprintf("-%v", index)
+
+	oldName := vm.Name
+	if oldName == "" {
+		if replica.GenerateName != "" {
+			replica.GenerateName = strings.TrimSuffix(replica.GenerateName, "-") + suffix + "-"
+		}
+	} else {
+		replica.Name = oldName + suffix
+		renameLabels(replica.Labels, oldName, replica.Name)
+		if replica.Spec.Template != nil {
+			renameLabels(replica.Spec.Template.ObjectMeta.Labels, oldName, replica.Name)
+		}
+	}
+
+	dataVolumeNames := map[string]string{}
+	for i := range replica.Spec.DataVolumeTemplates {
+		dataVolumeTemplate := &replica.Spec.DataVolumeTemplates[i]
+		var newName string
+		if oldName != "" && strings.Contains(dataVolumeTemplate.Name, oldName) {
+			newName = strings.Replace(dataVolumeTemplate.Name, oldName, replica.Name, 1)
+		} else {
+			newName = dataVolumeTemplate.Name + suffix
+		}
+		dataVolumeNames[dataVolumeTemplate.Name] = newName
+		dataVolumeTemplate.Name = newName
+	}
+
+	if replica.Spec.Template != nil {
+		for _, volume := range replica.Spec.Template.Spec.Volumes {
+			if volume.DataVolume != nil {
+				if newName, ok := dataVolumeNames[volume.DataVolume.Name]; ok {
+					volume.DataVolume.Name = newName
+				}
+			}
+		}
+	}
+
+	return replica
+}
+
+func renameLabels(labels map[string]string, oldName, newName string) {
+	for key, value := range labels {
+		if value == oldName {
+			labels[key] = newName
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package vm_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	cdiv1beta1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+
+	vm2 "github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/vm"
+)
+
+var _ = Describe("Replica", func() {
+	var vm *kubevirtv1.VirtualMachine
+
+	BeforeEach(func() {
+		vm = vm2.NewInstancetypeVM("my-vm",
+			&kubevirtv1.InstancetypeMatcher{Name: "u1.small"},
+			nil,
+			&cdiv1beta1.DataVolumeSourceRef{Kind: "DataSource", Name: "fedora"},
+			nil,
+		)
+		vm.Labels = map[string]string{"app": "my-vm", "tier": "test"}
+		vm2.AddMetadata(vm, nil)
+	})
+
+	It("derives names from the VM name", func() {
+		replica := vm2.NewReplica(vm, 3)
+
+		Expect(replica.Name).To(Equal("my-vm-3"))
+		Expect(replica.Labels).To(Equal(map[string]string{"app": "my-vm-3", "tier": "test"}))
+		Expect(replica.Spec.Template.ObjectMeta.Labels).To(HaveKeyWithValue("vm.kubevirt.io/name", "my-vm-3"))
+		Expect(replica.Spec.DataVolumeTemplates[0].Name).To(Equal("my-vm-3-rootdisk"))
+		Expect(replica.Spec.Template.Spec.Volumes[0].DataVolume.Name).To(Equal("my-vm-3-rootdisk"))
+	})
+
+	It("does not modify the original VM", func() {
+		original := vm.DeepCopy()
+		vm2.NewReplica(vm, 1)
+		Expect(vm).To(Equal(original))
+	})
+
+	It("adds the index to the generate name and data volumes not derived from the VM name", func() {
+		vm.Name = ""
+		vm.GenerateName = "my-vm-"
+		vm.Spec.DataVolumeTemplates[0].Name = "rootdisk"
+		vm.Spec.Template.Spec.Volumes[0].DataVolume.Name = "rootdisk"
+
+		replica := vm2.NewReplica(vm, 0)
+
+		Expect(replica.Name).To(BeEmpty())
+		Expect(replica.GenerateName).To(Equal("my-vm-0-"))
+		Expect(replica.Spec.DataVolumeTemplates[0].Name).To(Equal("rootdisk-0"))
+		Expect(replica.Spec.Template.Spec.Volumes[0].DataVolume.Name).To(Equal("rootdisk-0"))
+	})
+})
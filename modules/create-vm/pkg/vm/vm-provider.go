@@ -3,6 +3,8 @@ package vm
 import (
 	"context"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	kubevirtv1 "kubevirt.io/api/core/v1"
 	kubevirtcliv1 "kubevirt.io/client-go/kubecli"
 )
@@ -12,8 +14,13 @@ type virtualMachineProvider struct {
 }
 
 type VirtualMachineProvider interface {
+	Get(namespace, name string) (*kubevirtv1.VirtualMachine, error)
 	Create(namespace string, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error)
+	Update(namespace string, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error)
+	AddTemplateLabels(namespace, name string, labels map[string]string) (*kubevirtv1.VirtualMachine, error)
+	DryRunCreate(namespace string, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error)
 	Start(namespace, name string) error
+	Delete(namespace, name string) error
 }
 
 func NewVirtualMachineProvider(client kubevirtcliv1.KubevirtClient) VirtualMachineProvider {
@@ -22,10 +29,49 @@ func NewVirtualMachineProvider(client kubevirtcliv1.KubevirtClient) VirtualMachi
 	}
 }
 
+func (v *virtualMachineProvider) Get(namespace, name string) (*kubevirtv1.VirtualMachine, error) {
+	return v.client.VirtualMachine(namespace).Get(context.Background(), name, &metav1.GetOptions{})
+}
+
 func (v *virtualMachineProvider) Create(namespace string, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
 	return v.client.VirtualMachine(namespace).Create(context.Background(), vm)
 }
 
+func (v *virtualMachineProvider) Update(namespace string, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+	return v.client.VirtualMachine(namespace).Update(context.Background(), vm)
+}
+
+func (v *virtualMachineProvider) AddTemplateLabels(namespace, name string, labels map[string]string) (*kubevirtv1.VirtualMachine, error) {
+	data, err := NewTemplateLabelsPatch(labels)
+	if err != nil {
+		return nil, err
+	}
+	return v.client.VirtualMachine(namespace).Patch(context.Background(), name, types.MergePatchType, data, &metav1.PatchOptions{})
+}
+
+// DryRunCreate sends the VM to the server with DryRun: All, so it is validated by admission webhooks but not persisted.
+// kubecli does not support create options, so the request is sent through the rest client.
+func (v *virtualMachineProvider) DryRunCreate(namespace string, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+	newVM := &kubevirtv1.VirtualMachine{}
+	err := v.client.RestClient().Post().
+		Resource("virtualmachines").
+		Namespace(namespace).
+		Param("dryRun", metav1.DryRunAll).
+		Body(vm).
+		Do(context.Background()).
+		Into(newVM)
+	if err != nil {
+		return nil, err
+	}
+
+	newVM.SetGroupVersionKind(kubevirtv1.VirtualMachineGroupVersionKind)
+	return newVM, nil
+}
+
 func (v *virtualMachineProvider) Start(namespace, name string) error {
 	return v.client.VirtualMachine(namespace).Start(context.Background(), name, &kubevirtv1.StartOptions{})
 }
+
+func (v *virtualMachineProvider) Delete(namespace, name string) error {
+	return v.client.VirtualMachine(namespace).Delete(context.Background(), name, &metav1.DeleteOptions{})
+}
@@ -1,6 +1,9 @@
 package vm
 
 import (
+	"encoding/json"
+	"strings"
+
 	lab "github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/constants/labels"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/k8s"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/templates"
@@ -30,8 +33,59 @@ func AddMetadata(vm *kubevirtv1.VirtualMachine, template *templatev1.Template) {
 		}
 	}
 
-	// for pairing service-vm (like for RDP)
+	// for pairing service-vm (like for RDP), VMs with a generated name get the label after creation
 	if vmName := vm.GetName(); vmName != "" {
 		tempLabels[lab.VMNameLabel] = vmName
 	}
 }
+
+// IsNameGenerated returns true if the name of the VM is generated by the server from its generateName
+func IsNameGenerated(vm *kubevirtv1.VirtualMachine) bool {
+	return vm.GetName() == "" && vm.GetGenerateName() != ""
+}
+
+// GetDataVolumeNames returns names of the data volumes used by the volumes of the VM followed by the remaining data volume templates of the VM
+func GetDataVolumeNames(vm *kubevirtv1.VirtualMachine) []string {
+	var names []string
+	seen := map[string]bool{}
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	if vm.Spec.Template != nil {
+		for _, volume := range vm.Spec.Template.Spec.Volumes {
+			if volume.DataVolume != nil {
+				add(volume.DataVolume.Name)
+			}
+		}
+	}
+	for _, dataVolumeTemplate := range vm.Spec.DataVolumeTemplates {
+		add(dataVolumeTemplate.Name)
+	}
+
+	return names
+}
+
+// AddGenerateName lets the server generate the name of a VM which has neither a name nor a generateName.
+// The generated name starts with the prefix.
+func AddGenerateName(vm *kubevirtv1.VirtualMachine, prefix string) {
+	if vm.GetName() == "" && vm.GetGenerateName() == "" {
+		vm.SetGenerateName(strings.TrimSuffix(prefix, "-") + "-")
+	}
+}
+
+// NewTemplateLabelsPatch returns a merge patch which adds the labels to the VMI template of a VM
+func NewTemplateLabelsPatch(labels map[string]string) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"labels": labels,
+				},
+			},
+		},
+	})
+}
@@ -3,6 +3,7 @@ package vm_test
 import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubevirtv1 "kubevirt.io/api/core/v1"
 
 	vm2 "github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/vm"
@@ -47,4 +48,58 @@ var _ = Describe("VM", func() {
 		}))
 
 	})
+
+	It("Does not add name label to VM with generated name", func() {
+		vm.Spec.Template.ObjectMeta.Labels = nil
+		vm.GenerateName = vm.Name + "-"
+		vm.Name = ""
+		vm2.AddMetadata(vm, nil)
+
+		Expect(vm.Spec.Template.ObjectMeta.Labels).To(BeEmpty())
+	})
+
+	DescribeTable("IsNameGenerated", func(name, generateName string, expected bool) {
+		vm.Name, vm.GenerateName = name, generateName
+		Expect(vm2.IsNameGenerated(vm)).To(Equal(expected))
+	},
+		Entry("name", "my-vm", "", false),
+		Entry("generateName", "", "my-vm-", true),
+		Entry("name and generateName", "my-vm", "my-vm-", false),
+		Entry("no name", "", "", false),
+	)
+
+	DescribeTable("AddGenerateName", func(name, generateName, expectedGenerateName string) {
+		vm.Name, vm.GenerateName = name, generateName
+		vm2.AddGenerateName(vm, "fedora-server-tiny")
+		Expect(vm.Name).To(Equal(name))
+		Expect(vm.GenerateName).To(Equal(expectedGenerateName))
+	},
+		Entry("VM without a name", "", "", "fedora-server-tiny-"),
+		Entry("VM with a name", "my-vm", "", ""),
+		Entry("VM with a generateName", "", "my-vm-", "my-vm-"),
+	)
+
+	It("Creates template labels patch", func() {
+		patch, err := vm2.NewTemplateLabelsPatch(map[string]string{"vm.kubevirt.io/name": "my-vm-x7k2p"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(string(patch)).To(Equal(`{"spec":{"template":{"metadata":{"labels":{"vm.kubevirt.io/name":"my-vm-x7k2p"}}}}}`))
+	})
+
+	It("Returns names of data volumes used by the VM", func() {
+		vm.Spec.DataVolumeTemplates = []kubevirtv1.DataVolumeTemplateSpec{
+			{ObjectMeta: metav1.ObjectMeta{Name: "rootdisk"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "unused"}},
+		}
+		vm.Spec.Template = &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+			Spec: kubevirtv1.VirtualMachineInstanceSpec{
+				Volumes: []kubevirtv1.Volume{
+					{Name: "datadisk", VolumeSource: kubevirtv1.VolumeSource{DataVolume: &kubevirtv1.DataVolumeSource{Name: "restored-datadisk"}}},
+					{Name: "cloudinitdisk", VolumeSource: kubevirtv1.VolumeSource{CloudInitNoCloud: &kubevirtv1.CloudInitNoCloudSource{}}},
+					{Name: "rootdisk", VolumeSource: kubevirtv1.VolumeSource{DataVolume: &kubevirtv1.DataVolumeSource{Name: "rootdisk"}}},
+				},
+			},
+		}
+
+		Expect(vm2.GetDataVolumeNames(vm)).To(Equal([]string{"restored-datadisk", "rootdisk", "unused"}))
+	})
 })
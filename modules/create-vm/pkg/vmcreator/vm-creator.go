@@ -1,24 +1,37 @@
 package vmcreator
 
 import (
-	"bytes"
 	"fmt"
-	"strings"
+	"sync"
 
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/bundle"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/clone"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/constants"
+	lab "github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/constants/labels"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/datasource"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/datavolume"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/instancetype"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/restore"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/rollback"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/service"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/templates"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/utils/parse"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/virtctl"
 	virtualMachine "github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/vm"
-	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/env"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/vmi"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/log"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zerrors"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zutils"
+	templatev1api "github.com/openshift/api/template/v1"
 	templatev1 "github.com/openshift/client-go/template/clientset/versioned/typed/template/v1"
 	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/rest"
 	kubevirtv1 "kubevirt.io/api/core/v1"
 	kubevirtcliv1 "kubevirt.io/client-go/kubecli"
-	virtctl "kubevirt.io/kubevirt/pkg/virtctl/create"
+	cdiv1beta1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
 	"sigs.k8s.io/yaml"
 )
 
@@ -28,11 +41,38 @@ type VMCreator struct {
 	config                 *rest.Config
 	templateProvider       templates.TemplateProvider
 	virtualMachineProvider virtualMachine.VirtualMachineProvider
+	vmiProvider            vmi.VirtualMachineInstanceProvider
+	dataVolumeProvider     datavolume.DataVolumeProvider
+	instancetypeProvider   instancetype.InstancetypeProvider
+	dataSourceProvider     datasource.DataSourceProvider
+	sourceProvider         datavolume.SourceProvider
+	objectProvider         bundle.ObjectProvider
+	serviceProvider        service.ServiceProvider
+	restoreProvider        restore.RestoreProvider
+	cloneProvider          clone.CloneProvider
+	tracker                *rollback.Tracker
+	objects                []bundle.ObjectReference
+	services               map[string]*corev1.Service
+	objectsLock            sync.Mutex
+}
+
+// Providers access the cluster resources used by the VMCreator. Providers not used by the creation mode can be nil.
+type Providers struct {
+	Template       templates.TemplateProvider
+	VirtualMachine virtualMachine.VirtualMachineProvider
+	VMI            vmi.VirtualMachineInstanceProvider
+	DataVolume     datavolume.DataVolumeProvider
+	Instancetype   instancetype.InstancetypeProvider
+	DataSource     datasource.DataSourceProvider
+	Source         datavolume.SourceProvider
+	Object         bundle.ObjectProvider
+	Service        service.ServiceProvider
+	Restore        restore.RestoreProvider
+	Clone          clone.CloneProvider
 }
 
 func NewVMCreator(cliOptions *parse.CLIOptions) (*VMCreator, error) {
 	log.Logger().Debug("initialized clients and providers")
-	targetNS := cliOptions.GetVirtualMachineNamespace()
 
 	config, err := rest.InClusterConfig()
 	if err != nil {
@@ -45,27 +85,122 @@ func NewVMCreator(cliOptions *parse.CLIOptions) (*VMCreator, error) {
 		return nil, fmt.Errorf("cannot create kubevirt client: %v", err.Error())
 	}
 
-	var templateProvider templates.TemplateProvider
-	virtualMachineProvider := virtualMachine.NewVirtualMachineProvider(kubevirtClient)
+	providers := &Providers{
+		VirtualMachine: virtualMachine.NewVirtualMachineProvider(kubevirtClient),
+		VMI:            vmi.NewVirtualMachineInstanceProvider(kubevirtClient),
+		DataVolume:     datavolume.NewDataVolumeProvider(kubevirtClient),
+		DataSource:     datasource.NewDataSourceProvider(kubevirtClient),
+		Source:         datavolume.NewSourceProvider(kubevirtClient),
+		Service:        service.NewServiceProvider(kubevirtClient),
+	}
 
-	if cliOptions.GetCreationMode() == constants.TemplateCreationMode {
-		templateProvider = templates.NewTemplateProvider(templatev1.NewForConfigOrDie(config))
+	switch cliOptions.GetCreationMode() {
+	case constants.TemplateCreationMode:
+		providers.Template = templates.NewTemplateProvider(templatev1.NewForConfigOrDie(config))
+	case constants.VMManifestCreationMode:
+		providers.Object = bundle.NewObjectProvider(kubevirtClient.DynamicClient())
+	case constants.InstancetypeCreationMode:
+		providers.Instancetype = instancetype.NewInstancetypeProvider(kubevirtClient)
+	case constants.SnapshotCreationMode:
+		providers.Restore = restore.NewRestoreProvider(kubevirtClient)
+	case constants.CloneCreationMode:
+		providers.Clone = clone.NewCloneProvider(kubevirtClient)
 	}
 
+	vmCreator := NewVMCreatorWithProviders(cliOptions, providers)
+	vmCreator.config = config
+	return vmCreator, nil
+}
+
+// NewVMCreatorWithProviders creates a VMCreator which accesses the cluster through the providers
+func NewVMCreatorWithProviders(cliOptions *parse.CLIOptions, providers *Providers) *VMCreator {
 	return &VMCreator{
-		targetNamespace:        targetNS,
+		targetNamespace:        cliOptions.GetVirtualMachineNamespace(),
 		cliOptions:             cliOptions,
-		config:                 config,
-		templateProvider:       templateProvider,
-		virtualMachineProvider: virtualMachineProvider,
-	}, nil
+		templateProvider:       providers.Template,
+		virtualMachineProvider: providers.VirtualMachine,
+		vmiProvider:            providers.VMI,
+		dataVolumeProvider:     providers.DataVolume,
+		instancetypeProvider:   providers.Instancetype,
+		dataSourceProvider:     providers.DataSource,
+		sourceProvider:         providers.Source,
+		objectProvider:         providers.Object,
+		serviceProvider:        providers.Service,
+		restoreProvider:        providers.Restore,
+		cloneProvider:          providers.Clone,
+		tracker:                rollback.NewTracker(cliOptions.GetRollbackOnFailureFlag()),
+		services:               map[string]*corev1.Service{},
+	}
 }
 
 func (v *VMCreator) StartVM(namespace, name string) error {
 	return v.virtualMachineProvider.Start(namespace, name)
 }
 
-func (v *VMCreator) CreateVM() (*kubevirtv1.VirtualMachine, error) {
+// GetObjects returns all objects created by the task in the order of creation
+func (v *VMCreator) GetObjects() []bundle.ObjectReference {
+	return v.objects
+}
+
+// GetService returns the service created for the VM or nil
+func (v *VMCreator) GetService(vmName string) *corev1.Service {
+	v.objectsLock.Lock()
+	defer v.objectsLock.Unlock()
+	return v.services[vmName]
+}
+
+// Rollback deletes objects created so far if rollback on failure is enabled and returns the cause together with any cleanup errors
+func (v *VMCreator) Rollback(cause error) error {
+	return v.tracker.Rollback(cause)
+}
+
+// WaitForDataVolumes waits until data volumes used by the created VMs succeed and returns their last observed phases
+func (v *VMCreator) WaitForDataVolumes(vms []*kubevirtv1.VirtualMachine) (map[string]cdiv1beta1.DataVolumePhase, error) {
+	phases := map[string]cdiv1beta1.DataVolumePhase{}
+
+	var names []string
+	for _, vm := range vms {
+		names = append(names, virtualMachine.GetDataVolumeNames(vm)...)
+	}
+
+	if len(names) == 0 {
+		return phases, nil
+	}
+
+	// all VMs are created in the target namespace
+	return datavolume.WaitForSuccess(v.dataVolumeProvider, v.targetNamespace, names, v.cliOptions.GetWaitTimeout())
+}
+
+// WaitForVMIsReady waits concurrently until VMIs of all VMs are ready and returns them in the order of the VMs
+func (v *VMCreator) WaitForVMIsReady(vms []*kubevirtv1.VirtualMachine) ([]*kubevirtv1.VirtualMachineInstance, error) {
+	readyVMIs := make([]*kubevirtv1.VirtualMachineInstance, len(vms))
+	errs := make([]error, len(vms))
+
+	var wg sync.WaitGroup
+	for i, vm := range vms {
+		wg.Add(1)
+		go func(i int, vm *kubevirtv1.VirtualMachine) {
+			defer wg.Done()
+			readyVMIs[i], errs[i] = vmi.WaitForReady(v.vmiProvider, vm.Namespace, vm.Name, v.cliOptions.GetWaitTimeout())
+		}(i, vm)
+	}
+	wg.Wait()
+
+	var multiError zerrors.MultiError
+	for i, vm := range vms {
+		if errs[i] != nil {
+			multiError.Add(vm.Name, errs[i])
+		}
+	}
+
+	if len(vms) == 1 {
+		return readyVMIs, errs[0]
+	}
+	return readyVMIs, multiError.AsOptional()
+}
+
+// CreateVMs creates as many VMs as requested by the count option
+func (v *VMCreator) CreateVMs() ([]*kubevirtv1.VirtualMachine, error) {
 	switch v.cliOptions.GetCreationMode() {
 	case constants.TemplateCreationMode:
 		return v.createVMFromTemplate()
@@ -73,72 +208,425 @@ func (v *VMCreator) CreateVM() (*kubevirtv1.VirtualMachine, error) {
 		return v.createVMFromManifest()
 	case constants.VirtctlCreatingMode:
 		return v.createVMVirtctl()
+	case constants.InstancetypeCreationMode:
+		return v.createVMFromInstancetype()
+	case constants.SnapshotCreationMode:
+		return v.createVMFromSnapshot()
+	case constants.CloneCreationMode:
+		return v.createVMFromClone()
 	}
 	return nil, zerrors.NewMissingRequiredError("unknown creation mode: %v", v.cliOptions.GetCreationMode())
 }
 
-func (v *VMCreator) createVMVirtctl() (*kubevirtv1.VirtualMachine, error) {
-	var vm kubevirtv1.VirtualMachine
+// createVMs creates replicas of the VM concurrently when the count is greater than 1.
+// Errors of the replicas are reported together.
+func (v *VMCreator) createVMs(namespace string, vm *kubevirtv1.VirtualMachine) ([]*kubevirtv1.VirtualMachine, error) {
+	count := v.cliOptions.GetCount()
+	if count <= 1 {
+		createdVM, err := v.createVM(namespace, vm)
+		if err != nil {
+			return nil, err
+		}
+		return []*kubevirtv1.VirtualMachine{createdVM}, nil
+	}
+
+	createdVMs := make([]*kubevirtv1.VirtualMachine, count)
+	errs := make([]error, count)
+	replicaNames := make([]string, count)
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, v.cliOptions.GetParallelism())
+
+	for i := 0; i < count; i++ {
+		replica := virtualMachine.NewReplica(vm, i)
+		replicaNames[i] = replica.Name
+		if replicaNames[i] == "" {
+			replicaNames[i] = replica.GenerateName
+		}
+
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, replica *kubevirtv1.VirtualMachine) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			createdVMs[i], errs[i] = v.createVM(namespace, replica)
+		}(i, replica)
+	}
+	wg.Wait()
+
+	var multiError zerrors.MultiError
+	var result []*kubevirtv1.VirtualMachine
+	for i := 0; i < count; i++ {
+		if errs[i] != nil {
+			multiError.Add(replicaNames[i], zerrors.NewSoftError("could not create VM %v: %v", replicaNames[i], errs[i].Error()))
+		} else {
+			result = append(result, createdVMs[i])
+		}
+	}
+
+	return result, multiError.AsOptional()
+}
+
+func (v *VMCreator) createVM(namespace string, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+	vm.Namespace = namespace
+	if err := v.addAccessCredentials(vm); err != nil {
+		return nil, zerrors.NewSoftError("could not add access credentials to VM %v: %v", vm.Name, err.Error())
+	}
+	if err := v.cliOptions.Options.Apply(vm); err != nil {
+		return nil, err
+	}
+
+	var createdVM *kubevirtv1.VirtualMachine
+	var err error
+	nameGenerated := virtualMachine.IsNameGenerated(vm)
+
+	switch v.cliOptions.GetDryRun() {
+	case constants.DryRunClient:
+		log.Logger().Debug("skipping creation of VM in client dry run", zap.Reflect("vm", vm))
+		createdVM = vm
+	case constants.DryRunServer:
+		log.Logger().Debug("creating VM in server dry run", zap.Reflect("vm", vm))
+		if createdVM, err = v.virtualMachineProvider.DryRunCreate(namespace, vm); err == nil && nameGenerated {
+			virtualMachine.AddMetadata(createdVM, nil)
+		}
+	default:
+		log.Logger().Debug("creating VM", zap.Reflect("vm", vm))
+		if createdVM, err = v.virtualMachineProvider.Create(namespace, vm); err == nil {
+			v.trackVM(createdVM)
+			if nameGenerated {
+				createdVM, err = v.addNameLabel(createdVM)
+			}
+		}
+	}
 
-	output, err := runCommand(v.cliOptions.Virtctl)
 	if err != nil {
-		return nil, zerrors.NewSoftError("failed to execute command: %v", err.Error())
+		return nil, err
 	}
 
-	if err := yaml.Unmarshal(output, &vm); err != nil {
-		return nil, zerrors.NewSoftError("could not read from virtctl output: %v", err.Error())
+	v.addObject(bundle.ObjectReference{Kind: constants.VirtualMachineKind, Name: createdVM.Name, Namespace: createdVM.Namespace})
+
+	if ports := v.cliOptions.GetServicePorts(); len(ports) > 0 {
+		if err := v.createService(createdVM, ports); err != nil {
+			return nil, err
+		}
+	}
+
+	return createdVM, nil
+}
+
+// addNameLabel labels the VMI template of a VM created with a generateName by the name assigned by the server
+func (v *VMCreator) addNameLabel(vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+	log.Logger().Debug("adding name label to VM template", zap.String("name", vm.Name), zap.String("namespace", vm.Namespace))
+	patchedVM, err := v.virtualMachineProvider.AddTemplateLabels(vm.Namespace, vm.Name, map[string]string{lab.VMNameLabel: vm.Name})
+	if err != nil {
+		return nil, zerrors.NewSoftError("could not add %v label to template of VM %v: %v", lab.VMNameLabel, vm.Name, err.Error())
 	}
+	return patchedVM, nil
+}
 
-	namespace := v.targetNamespace
-	if namespace == "" {
-		if namespace, err = env.GetActiveNamespace(); err != nil {
-			return nil, zerrors.NewMissingRequiredError("can't get active namespace: %v", err.Error())
+// addAccessCredentials adds the public SSH key and cloud-init secrets to the VM
+func (v *VMCreator) addAccessCredentials(vm *kubevirtv1.VirtualMachine) error {
+	configDrive := false
+
+	if secretName := v.cliOptions.SSHPublicKeySecret; secretName != "" {
+		method := v.cliOptions.GetSSHPropagationMethod()
+		if err := virtualMachine.AddSSHPublicKey(vm, secretName, method, v.cliOptions.GetSSHUsers()); err != nil {
+			return err
+		}
+		configDrive = method == virtualMachine.ConfigDrivePropagation
+	}
+
+	if v.cliOptions.UserDataSecret != "" || v.cliOptions.NetworkDataSecret != "" {
+		return virtualMachine.AddCloudInitSecrets(vm, v.cliOptions.UserDataSecret, v.cliOptions.NetworkDataSecret, configDrive)
+	}
+
+	return nil
+}
+
+// createService exposes ports of the VM through a service owned by the VM
+func (v *VMCreator) createService(vm *kubevirtv1.VirtualMachine, ports []corev1.ServicePort) error {
+	if vm.Spec.Template == nil || vm.Spec.Template.ObjectMeta.Labels[lab.VMNameLabel] != vm.Name {
+		return zerrors.NewSoftError("could not create service for VM %v: VM template should have a %v label with the name of the VM", vm.Name, lab.VMNameLabel)
+	}
+
+	svc := service.NewService(vm, v.cliOptions.GetServiceType(), ports)
+	createdService := svc
+	var err error
+
+	switch v.cliOptions.GetDryRun() {
+	case constants.DryRunClient:
+		log.Logger().Debug("skipping creation of service in client dry run", zap.Reflect("service", svc))
+	case constants.DryRunServer:
+		log.Logger().Debug("creating service in server dry run", zap.Reflect("service", svc))
+		createdService, err = v.serviceProvider.DryRunCreate(svc)
+	default:
+		log.Logger().Debug("creating service", zap.Reflect("service", svc))
+		if createdService, err = v.serviceProvider.Create(svc); err == nil {
+			namespace, name := createdService.Namespace, createdService.Name
+			v.tracker.Track(constants.ServiceKind, namespace, name, func() error {
+				return v.serviceProvider.Delete(namespace, name)
+			})
+		}
+	}
+
+	if err != nil {
+		return zerrors.NewSoftError("could not create service for VM %v: %v", vm.Name, err.Error())
+	}
+
+	v.addObject(bundle.ObjectReference{Kind: constants.ServiceKind, Name: createdService.Name, Namespace: createdService.Namespace})
+
+	v.objectsLock.Lock()
+	defer v.objectsLock.Unlock()
+	v.services[vm.Name] = createdService
+	return nil
+}
+
+// createObject creates a dependency of the VM from the VM manifest bundle
+func (v *VMCreator) createObject(obj *unstructured.Unstructured) error {
+	kind, name := obj.GetKind(), obj.GetName()
+	if namespace := obj.GetNamespace(); namespace != "" && namespace != v.targetNamespace {
+		return zerrors.NewSoftError("%v %v should be in the %v namespace of the VM, but is in %v", kind, name, v.targetNamespace, namespace)
+	}
+
+	obj.SetNamespace(v.targetNamespace)
+	if err := v.cliOptions.Options.Apply(obj); err != nil {
+		return err
+	}
+
+	createdObj := obj
+	var err error
+
+	switch v.cliOptions.GetDryRun() {
+	case constants.DryRunClient:
+		log.Logger().Debug("skipping creation of object in client dry run", zap.String("kind", kind), zap.Reflect("object", obj))
+	case constants.DryRunServer:
+		log.Logger().Debug("creating object in server dry run", zap.String("kind", kind), zap.Reflect("object", obj))
+		createdObj, err = v.objectProvider.DryRunCreate(obj)
+	default:
+		log.Logger().Debug("creating object", zap.String("kind", kind), zap.Reflect("object", obj))
+		if createdObj, err = v.objectProvider.Create(obj); err == nil {
+			v.tracker.Track(kind, createdObj.GetNamespace(), createdObj.GetName(), func() error {
+				return v.objectProvider.Delete(createdObj)
+			})
 		}
 	}
 
-	return v.virtualMachineProvider.Create(namespace, &vm)
+	if err != nil {
+		return zerrors.NewSoftError("could not create %v %v: %v", kind, name, err.Error())
+	}
+
+	v.addObject(bundle.ObjectReference{Kind: kind, Name: createdObj.GetName(), Namespace: createdObj.GetNamespace()})
+	return nil
 }
 
-func runCommand(params string) ([]byte, error) {
-	args := strings.Split(params, " ")
-	output := &bytes.Buffer{}
-	cmd := virtctl.NewCommand()
-	cmd.SetArgs(append([]string{"vm"}, args...))
-	cmd.SetOut(output)
-	err := cmd.Execute()
+func (v *VMCreator) addObject(object bundle.ObjectReference) {
+	v.objectsLock.Lock()
+	defer v.objectsLock.Unlock()
+	v.objects = append(v.objects, object)
+}
+
+func (v *VMCreator) trackVM(vm *kubevirtv1.VirtualMachine) {
+	namespace, name := vm.Namespace, vm.Name
+	v.tracker.Track(constants.VirtualMachineKind, namespace, name, func() error {
+		return v.virtualMachineProvider.Delete(namespace, name)
+	})
 
-	return output.Bytes(), err
+	for _, dataVolumeTemplate := range vm.Spec.DataVolumeTemplates {
+		dataVolumeName := dataVolumeTemplate.Name
+		v.tracker.Track(constants.DataVolumeKind, namespace, dataVolumeName, func() error {
+			return v.dataVolumeProvider.Delete(namespace, dataVolumeName)
+		})
+	}
 }
 
-func (v *VMCreator) createVMFromManifest() (*kubevirtv1.VirtualMachine, error) {
+// overrideDataVolumeTemplate rewrites the boot source and storage of a data volume template of the VM.
+// The requested size is checked against the size of the source, so the clone does not fail later.
+func (v *VMCreator) overrideDataVolumeTemplate(namespace string, vm *kubevirtv1.VirtualMachine) error {
+	dvOverrides := v.cliOptions.GetDataVolumeTemplateOverrides()
+	if dvOverrides == nil {
+		return nil
+	}
+
+	dataVolumeTemplate, err := datavolume.FindTemplate(vm, dvOverrides.Name)
+	if err != nil {
+		return zerrors.NewSoftError("could not override data volume template: %v", err.Error())
+	}
+	dvOverrides.Apply(dataVolumeTemplate, namespace)
+
+	requestedSize := datavolume.GetRequestedSize(dataVolumeTemplate)
+	if requestedSize == nil {
+		return nil
+	}
+
+	sourceSize, err := v.getSourceSize(namespace, dataVolumeTemplate)
+	if err != nil {
+		return zerrors.NewSoftError("could not get size of the source of %v data volume template: %v", dataVolumeTemplate.Name, err.Error())
+	}
+
+	if sourceSize != nil && requestedSize.Cmp(*sourceSize) < 0 {
+		return zerrors.NewSoftError("requested size %v of %v data volume template is smaller than the size %v of its source",
+			requestedSize.String(), dataVolumeTemplate.Name, sourceSize.String())
+	}
+	return nil
+}
+
+// getSourceSize returns the size of a PVC or a snapshot the data volume template clones from, directly or through a data source.
+// Nil is returned for other sources or when the size is not known yet.
+func (v *VMCreator) getSourceSize(namespace string, dataVolumeTemplate *kubevirtv1.DataVolumeTemplateSpec) (*resource.Quantity, error) {
+	var pvcSource *cdiv1beta1.DataVolumeSourcePVC
+	var snapshotSource *cdiv1beta1.DataVolumeSourceSnapshot
+
+	if sourceRef := dataVolumeTemplate.Spec.SourceRef; sourceRef != nil {
+		if sourceRef.Kind != constants.DataSourceKind {
+			return nil, nil
+		}
+		dataSourceNamespace := namespace
+		if sourceRef.Namespace != nil && *sourceRef.Namespace != "" {
+			dataSourceNamespace = *sourceRef.Namespace
+		}
+
+		log.Logger().Debug("retrieving data source", zap.String("name", sourceRef.Name), zap.String("namespace", dataSourceNamespace))
+		dataSource, err := v.dataSourceProvider.Get(dataSourceNamespace, sourceRef.Name)
+		if err != nil {
+			return nil, err
+		}
+		pvcSource, snapshotSource = dataSource.Spec.Source.PVC, dataSource.Spec.Source.Snapshot
+	} else if source := dataVolumeTemplate.Spec.Source; source != nil {
+		pvcSource, snapshotSource = source.PVC, source.Snapshot
+	}
+
+	if pvcSource != nil {
+		log.Logger().Debug("retrieving PVC", zap.String("name", pvcSource.Name), zap.String("namespace", pvcSource.Namespace))
+		pvc, err := v.sourceProvider.GetPVC(pvcSource.Namespace, pvcSource.Name)
+		if err != nil {
+			return nil, err
+		}
+		if size, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+			return &size, nil
+		}
+		if size, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+			return &size, nil
+		}
+	}
+
+	if snapshotSource != nil {
+		log.Logger().Debug("retrieving volume snapshot", zap.String("name", snapshotSource.Name), zap.String("namespace", snapshotSource.Namespace))
+		snapshot, err := v.sourceProvider.GetSnapshot(snapshotSource.Namespace, snapshotSource.Name)
+		if err != nil {
+			return nil, err
+		}
+		if snapshot.Status != nil {
+			return snapshot.Status.RestoreSize, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (v *VMCreator) createVMVirtctl() ([]*kubevirtv1.VirtualMachine, error) {
 	var vm kubevirtv1.VirtualMachine
 
-	if err := yaml.Unmarshal([]byte(v.cliOptions.VirtualMachineManifest), &vm); err != nil {
-		return nil, zerrors.NewSoftError("could not read VM manifest: %v", err.Error())
+	log.Logger().Debug("running virtctl create vm", zap.Strings("args", v.cliOptions.GetVirtctlArgs()))
+	output, err := virtctl.Run(v.cliOptions.GetVirtctlArgs())
+	if err != nil {
+		return nil, zerrors.NewSoftError("virtctl create vm failed: %v", err.Error())
+	}
+
+	if err := yaml.Unmarshal(output, &vm); err != nil {
+		return nil, zerrors.NewSoftError("could not read from virtctl output: %v", err.Error())
+	}
+
+	if vm.Namespace != "" && vm.Namespace != v.targetNamespace {
+		return nil, zerrors.NewSoftError("VM from virtctl output should be in the %v namespace, but is in %v", v.targetNamespace, vm.Namespace)
 	}
 
-	vm.Namespace = v.targetNamespace
 	virtualMachine.AddMetadata(&vm, nil)
+	v.cliOptions.GetVMOverrides().SetValuesToVM(&vm)
+
+	if err := v.overrideDataVolumeTemplate(v.targetNamespace, &vm); err != nil {
+		return nil, err
+	}
+
+	createdVM, err := v.createVM(v.targetNamespace, &vm)
+	if err != nil {
+		return nil, err
+	}
+	return []*kubevirtv1.VirtualMachine{createdVM}, nil
+}
+
+func (v *VMCreator) createVMFromManifest() ([]*kubevirtv1.VirtualMachine, error) {
+	vmBundle := v.cliOptions.GetVirtualMachineBundle()
+
+	vm := vmBundle.VM
+	vm.Namespace = v.targetNamespace
+	virtualMachine.AddMetadata(vm, nil)
 
 	runStrategy := kubevirtv1.VirtualMachineRunStrategy(v.cliOptions.GetRunStrategy())
 	if runStrategy != "" {
 		vm.Spec.Running = nil
 		vm.Spec.RunStrategy = &runStrategy
 	}
+	v.cliOptions.GetVMOverrides().SetValuesToVM(vm)
+	if err := v.overrideDataVolumeTemplate(v.targetNamespace, vm); err != nil {
+		return nil, err
+	}
+
+	if v.cliOptions.GetCount() > 1 && len(vmBundle.Dependencies)+len(vmBundle.Dependents) > 0 {
+		return nil, zerrors.NewSoftError("count option is not applicable for VM manifests with multiple objects")
+	}
+
+	for _, obj := range vmBundle.Dependencies {
+		if err := v.createObject(obj); err != nil {
+			return nil, err
+		}
+	}
+
+	createdVMs, err := v.createVMs(v.targetNamespace, vm)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, obj := range vmBundle.Dependents {
+		if err := v.createObject(obj); err != nil {
+			return nil, err
+		}
+	}
 
-	log.Logger().Debug("creating VM", zap.Reflect("vm", vm))
-	return v.virtualMachineProvider.Create(v.targetNamespace, &vm)
+	return createdVMs, nil
 }
 
-func (v *VMCreator) createVMFromTemplate() (*kubevirtv1.VirtualMachine, error) {
-	log.Logger().Debug("retrieving template", zap.String("name", v.cliOptions.TemplateName), zap.String("namespace", v.cliOptions.GetTemplateNamespace()))
-	template, err := v.templateProvider.Get(v.cliOptions.GetTemplateNamespace(), v.cliOptions.TemplateName)
+func (v *VMCreator) getTemplate() (*templatev1api.Template, error) {
+	namespace := v.cliOptions.GetTemplateNamespace()
+
+	if v.cliOptions.TemplateSelector == "" {
+		log.Logger().Debug("retrieving template", zap.String("name", v.cliOptions.TemplateName), zap.String("namespace", namespace))
+		return v.templateProvider.Get(namespace, v.cliOptions.TemplateName)
+	}
+
+	selector := v.cliOptions.GetTemplateSelector()
+	log.Logger().Debug("listing templates", zap.String("selector", selector), zap.String("namespace", namespace))
+	templateList, err := v.templateProvider.List(namespace, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := templates.SelectTemplate(templateList)
+	if err != nil {
+		return nil, zerrors.NewSoftError("could not select template by %v in %v namespace: %v", selector, namespace, err.Error())
+	}
+
+	log.Logger().Debug("selected template", zap.String("name", template.Name), zap.String("namespace", namespace))
+	return template, nil
+}
+
+func (v *VMCreator) createVMFromTemplate() ([]*kubevirtv1.VirtualMachine, error) {
+	template, err := v.getTemplate()
 	if err != nil {
 		return nil, err
 	}
 
-	log.Logger().Debug("processing template", zap.String("name", v.cliOptions.TemplateName), zap.String("namespace", v.cliOptions.GetTemplateNamespace()))
-	processedTemplate, err := v.templateProvider.Process(v.targetNamespace, template, v.cliOptions.GetTemplateParams())
+	log.Logger().Debug("processing template", zap.String("name", template.Name), zap.String("namespace", template.Namespace))
+	processedTemplate, err := v.templateProvider.Process(template, v.cliOptions.GetTemplateParams())
 	if err != nil {
 		return nil, err
 	}
@@ -148,13 +636,185 @@ func (v *VMCreator) createVMFromTemplate() (*kubevirtv1.VirtualMachine, error) {
 	}
 
 	vm.Namespace = v.targetNamespace
+	// the name label is added to the VMI template after the server generates the name
+	virtualMachine.AddGenerateName(vm, template.Name)
+	virtualMachine.AddMetadata(vm, processedTemplate)
+
+	runStrategy := kubevirtv1.VirtualMachineRunStrategy(v.cliOptions.GetRunStrategy())
+	if runStrategy != "" {
+		vm.Spec.Running = nil
+		vm.Spec.RunStrategy = &runStrategy
+	}
+	v.cliOptions.GetVMOverrides().SetValuesToVM(vm)
+	if err := v.overrideDataVolumeTemplate(v.targetNamespace, vm); err != nil {
+		return nil, err
+	}
+
+	log.Logger().Debug("validating VM", zap.String("template", template.Name))
+	if err := templates.ValidateVM(template, vm); err != nil {
+		return nil, err
+	}
+
+	return v.createVMs(v.targetNamespace, vm)
+}
+
+func (v *VMCreator) createVMFromInstancetype() ([]*kubevirtv1.VirtualMachine, error) {
+	instancetypeMatcher := &kubevirtv1.InstancetypeMatcher{
+		Name: v.cliOptions.Instancetype,
+		Kind: v.cliOptions.GetInstancetypeKind(),
+	}
+
+	log.Logger().Debug("retrieving instancetype", zap.String("name", instancetypeMatcher.Name), zap.String("kind", instancetypeMatcher.Kind))
+	if _, err := v.instancetypeProvider.GetInstancetype(v.targetNamespace, instancetypeMatcher.Name, instancetypeMatcher.Kind); err != nil {
+		return nil, err
+	}
+
+	var preferenceMatcher *kubevirtv1.PreferenceMatcher
+	if v.cliOptions.Preference != "" {
+		preferenceMatcher = &kubevirtv1.PreferenceMatcher{
+			Name: v.cliOptions.Preference,
+			Kind: v.cliOptions.GetPreferenceKind(),
+		}
+
+		log.Logger().Debug("retrieving preference", zap.String("name", preferenceMatcher.Name), zap.String("kind", preferenceMatcher.Kind))
+		if _, err := v.instancetypeProvider.GetPreference(v.targetNamespace, preferenceMatcher.Name, preferenceMatcher.Kind); err != nil {
+			return nil, err
+		}
+	}
+
+	dataSourceNamespace := v.cliOptions.GetDataSourceNamespace()
+	log.Logger().Debug("retrieving data source", zap.String("name", v.cliOptions.DataSourceName), zap.String("namespace", dataSourceNamespace))
+	dataSource, err := v.dataSourceProvider.Get(dataSourceNamespace, v.cliOptions.DataSourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	if !datasource.IsReady(dataSource) {
+		return nil, zerrors.NewSoftError("data source %v/%v is not ready", dataSourceNamespace, dataSource.Name)
+	}
+
+	dataSourceRef := &cdiv1beta1.DataVolumeSourceRef{
+		Kind:      constants.DataSourceKind,
+		Name:      dataSource.Name,
+		Namespace: &dataSourceNamespace,
+	}
+
+	vm := virtualMachine.NewInstancetypeVM(v.cliOptions.GetVirtualMachineName(), instancetypeMatcher, preferenceMatcher, dataSourceRef, v.cliOptions.GetDiskSize())
+	vm.Namespace = v.targetNamespace
+	virtualMachine.AddMetadata(vm, nil)
 
 	runStrategy := kubevirtv1.VirtualMachineRunStrategy(v.cliOptions.GetRunStrategy())
 	if runStrategy != "" {
 		vm.Spec.Running = nil
 		vm.Spec.RunStrategy = &runStrategy
 	}
+	v.cliOptions.GetVMOverrides().SetValuesToVM(vm)
+
+	return v.createVMs(v.targetNamespace, vm)
+}
+
+func (v *VMCreator) createVMFromSnapshot() ([]*kubevirtv1.VirtualMachine, error) {
+	namespace, snapshotName, vmName := v.targetNamespace, v.cliOptions.VirtualMachineSnapshot, v.cliOptions.GetVirtualMachineName()
+
+	log.Logger().Debug("retrieving VM snapshot", zap.String("name", snapshotName), zap.String("namespace", namespace))
+	snapshot, err := v.restoreProvider.GetSnapshot(namespace, snapshotName)
+	if err != nil {
+		return nil, err
+	}
+
+	if !restore.IsSnapshotReady(snapshot) {
+		return nil, zerrors.NewSoftError("VM snapshot %v/%v is not ready", namespace, snapshotName)
+	}
+
+	vmRestore := restore.NewRestore(namespace, snapshotName, vmName)
+	if err := v.cliOptions.Options.Apply(vmRestore); err != nil {
+		return nil, err
+	}
+
+	log.Logger().Debug("creating restore", zap.Reflect("restore", vmRestore))
+	createdRestore, err := v.restoreProvider.Create(vmRestore)
+	if err != nil {
+		return nil, zerrors.NewSoftError("could not create restore of VM snapshot %v: %v", snapshotName, err.Error())
+	}
+
+	restoreName := createdRestore.Name
+	v.tracker.Track(constants.VirtualMachineRestoreKind, namespace, restoreName, func() error {
+		return v.restoreProvider.Delete(namespace, restoreName)
+	})
+	v.addObject(bundle.ObjectReference{Kind: constants.VirtualMachineRestoreKind, Name: restoreName, Namespace: namespace})
+
+	log.Logger().Debug("waiting for restore to complete", zap.String("name", restoreName), zap.String("namespace", namespace))
+	if err := restore.WaitForSuccess(v.restoreProvider, namespace, restoreName, v.cliOptions.GetWaitTimeout()); err != nil {
+		// the VM may have been created before the restore failed
+		_, _ = v.getTargetVM(namespace, vmName)
+		return nil, err
+	}
+
+	vm, err := v.getTargetVM(namespace, vmName)
+	if err != nil {
+		return nil, err
+	}
+	return []*kubevirtv1.VirtualMachine{vm}, nil
+}
+
+func (v *VMCreator) createVMFromClone() ([]*kubevirtv1.VirtualMachine, error) {
+	namespace, sourceName, targetName := v.targetNamespace, v.cliOptions.CloneSourceVM, v.cliOptions.GetVirtualMachineName()
+
+	vmClone := clone.NewClone(namespace, sourceName, targetName, v.cliOptions.GetCloneLabelFilters(), v.cliOptions.GetCloneAnnotationFilters(),
+		v.cliOptions.GetCloneNewMacAddresses())
+	if err := v.cliOptions.Options.Apply(vmClone); err != nil {
+		return nil, err
+	}
+
+	log.Logger().Debug("creating clone", zap.Reflect("clone", vmClone))
+	createdClone, err := v.cloneProvider.Create(vmClone)
+	if err != nil {
+		return nil, zerrors.NewSoftError("could not create clone of VM %v: %v", sourceName, err.Error())
+	}
+
+	cloneName := createdClone.Name
+	v.tracker.Track(constants.VirtualMachineCloneKind, namespace, cloneName, func() error {
+		return v.cloneProvider.Delete(namespace, cloneName)
+	})
+	v.addObject(bundle.ObjectReference{Kind: constants.VirtualMachineCloneKind, Name: cloneName, Namespace: namespace})
+
+	log.Logger().Debug("waiting for clone to succeed", zap.String("name", cloneName), zap.String("namespace", namespace))
+	succeededClone, err := clone.WaitForSuccess(v.cloneProvider, namespace, cloneName, v.cliOptions.GetWaitTimeout())
+	if err != nil {
+		// the VM may have been created before the clone failed
+		if targetName != "" {
+			_, _ = v.getTargetVM(namespace, targetName)
+		}
+		return nil, err
+	}
+
+	vm, err := v.getTargetVM(namespace, clone.GetTargetName(succeededClone))
+	if err != nil {
+		return nil, err
+	}
+	return []*kubevirtv1.VirtualMachine{vm}, nil
+}
+
+// getTargetVM retrieves a VM created by KubeVirt from a restore or a clone and tracks it for rollback.
+// The VM is updated with the owner reference and the TTL annotation when requested.
+func (v *VMCreator) getTargetVM(namespace, name string) (*kubevirtv1.VirtualMachine, error) {
+	log.Logger().Debug("retrieving VM", zap.String("name", name), zap.String("namespace", namespace))
+	vm, err := v.virtualMachineProvider.Get(namespace, name)
+	if err != nil {
+		return nil, zerrors.NewSoftError("could not get VM %v/%v: %v", namespace, name, err.Error())
+	}
+	v.trackVM(vm)
+	v.addObject(bundle.ObjectReference{Kind: constants.VirtualMachineKind, Name: vm.Name, Namespace: vm.Namespace})
+
+	if v.cliOptions.GetOwnerKind() != "" || v.cliOptions.GetTTL() != "" {
+		if err := v.cliOptions.Options.Apply(vm); err != nil {
+			return nil, err
+		}
+		log.Logger().Debug("updating VM", zap.Reflect("vm", vm))
+		if vm, err = v.virtualMachineProvider.Update(namespace, vm); err != nil {
+			return nil, zerrors.NewSoftError("could not update VM %v/%v: %v", namespace, name, err.Error())
+		}
+	}
 
-	log.Logger().Debug("creating VM", zap.Reflect("vm", vm))
-	return v.virtualMachineProvider.Create(v.targetNamespace, vm)
+	return vm, nil
 }
@@ -0,0 +1,69 @@
+package vmcreator
+
+import (
+	"encoding/json"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/bundle"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/constants"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/vmi"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zerrors"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+// VMResult contains the results of a single created VM. It is kept small, so results of many VMs fit into the termination message.
+type VMResult struct {
+	Name  string `json:"name"`
+	VMIIP string `json:"vmiIP,omitempty"`
+}
+
+// GetVMResults returns results of the VMs. ReadyVMIs should be in the order of the VMs or nil if VMIs were not waited for.
+func (v *VMCreator) GetVMResults(vms []*kubevirtv1.VirtualMachine, readyVMIs []*kubevirtv1.VirtualMachineInstance) []VMResult {
+	results := make([]VMResult, 0, len(vms))
+
+	for i, vm := range vms {
+		result := VMResult{
+			Name: vm.Name,
+		}
+		if i < len(readyVMIs) && readyVMIs[i] != nil {
+			result.VMIIP = vmi.GetIPAddress(readyVMIs[i])
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// GetObjectResults returns the objects created by the task. VMs and their services are left out when there are more VMs,
+// because they are already covered by the names result.
+func (v *VMCreator) GetObjectResults(vms []*kubevirtv1.VirtualMachine) []bundle.ObjectReference {
+	objects := v.GetObjects()
+	if len(vms) <= 1 {
+		return objects
+	}
+
+	results := make([]bundle.ObjectReference, 0, len(objects))
+	for _, object := range objects {
+		if object.Kind != constants.VirtualMachineKind && object.Kind != constants.ServiceKind {
+			results = append(results, object)
+		}
+	}
+	return results
+}
+
+// ValidateResultsSize checks the results fit into the termination message of the step once they are JSON encoded
+func ValidateResultsSize(results map[string]string) error {
+	size := 0
+	for name, value := range results {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		size += len(name) + len(encoded)
+	}
+
+	if size > constants.MaxResultsSize {
+		return zerrors.NewSoftError("results of %v bytes are too large to be recorded, only %v bytes are allowed: lower the count",
+			size, constants.MaxResultsSize)
+	}
+	return nil
+}
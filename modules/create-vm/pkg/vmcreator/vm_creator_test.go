@@ -0,0 +1,705 @@
+package vmcreator_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/bundle"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/templates"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/utils/parse"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/vmcreator"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	templatev1 "github.com/openshift/api/template/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	cdiv1beta1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/sharedtest/testobjects/template"
+)
+
+const (
+	testNamespace = "default"
+
+	testVMManifest = `apiVersion: kubevirt.io/v1
+kind: VirtualMachine
+metadata:
+  name: my-vm
+spec:
+  running: false
+  dataVolumeTemplates:
+  - metadata:
+      name: my-vm-rootdisk
+    spec:
+      source:
+        blank: {}
+      storage:
+        resources:
+          requests:
+            storage: 1Gi
+  template:
+    spec:
+      domain:
+        devices: {}
+      volumes:
+      - name: rootdisk
+        dataVolume:
+          name: my-vm-rootdisk
+`
+)
+
+// objects of the bundle are not in the order of creation
+var testBundleManifest = `apiVersion: v1
+kind: Service
+metadata:
+  name: my-vm-ssh
+spec:
+  selector:
+    vm.kubevirt.io/name: my-vm
+  ports:
+  - port: 22
+---
+` + testVMManifest + `---
+apiVersion: cdi.kubevirt.io/v1beta1
+kind: DataVolume
+metadata:
+  name: my-vm-data
+spec:
+  source:
+    blank: {}
+  storage:
+    resources:
+      requests:
+        storage: 1Gi
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-vm-config
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: my-vm-secret
+`
+
+var testGenerateNameVMManifest = strings.Replace(testVMManifest, "  name: my-vm\n", "  generateName: my-vm-\n", 1)
+
+// fakeVirtualMachineProvider stores VMs in memory and fails creation of VMs with the names in failNames
+type fakeVirtualMachineProvider struct {
+	lock          sync.Mutex
+	vms           map[string]*kubevirtv1.VirtualMachine
+	failNames     map[string]bool
+	createDelay   time.Duration
+	creating      int
+	maxCreating   int
+	generated     int
+	dryRunCreated []string
+	patchedLabels map[string]map[string]string
+	failPatch     bool
+	deleted       []string
+}
+
+func newFakeVirtualMachineProvider() *fakeVirtualMachineProvider {
+	return &fakeVirtualMachineProvider{
+		vms:           map[string]*kubevirtv1.VirtualMachine{},
+		failNames:     map[string]bool{},
+		patchedLabels: map[string]map[string]string{},
+	}
+}
+
+func (f *fakeVirtualMachineProvider) generateName(vm *kubevirtv1.VirtualMachine) {
+	if vm.Name == "" {
+		f.generated++
+		vm.Name = fmt.Sprintf("%vgen%v", vm.GenerateName, f.generated)
+	}
+}
+
+func (f *fakeVirtualMachineProvider) Get(namespace, name string) (*kubevirtv1.VirtualMachine, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	vm, ok := f.vms[namespace+"/"+name]
+	if !ok {
+		return nil, k8serrors.NewNotFound(schema.GroupResource{Group: "kubevirt.io", Resource: "virtualmachines"}, name)
+	}
+	return vm.DeepCopy(), nil
+}
+
+func (f *fakeVirtualMachineProvider) Create(namespace string, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+	f.lock.Lock()
+	f.creating++
+	if f.creating > f.maxCreating {
+		f.maxCreating = f.creating
+	}
+	f.lock.Unlock()
+
+	time.Sleep(f.createDelay)
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.creating--
+
+	if f.failNames[vm.Name+vm.GenerateName] {
+		return nil, fmt.Errorf("admission webhook denied the request")
+	}
+
+	createdVM := vm.DeepCopy()
+	createdVM.Namespace = namespace
+	f.generateName(createdVM)
+	createdVM.UID = types.UID("uid-" + createdVM.Name)
+	f.vms[namespace+"/"+createdVM.Name] = createdVM
+	return createdVM.DeepCopy(), nil
+}
+
+func (f *fakeVirtualMachineProvider) Update(namespace string, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.vms[namespace+"/"+vm.Name] = vm.DeepCopy()
+	return vm, nil
+}
+
+func (f *fakeVirtualMachineProvider) AddTemplateLabels(namespace, name string, labels map[string]string) (*kubevirtv1.VirtualMachine, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	vm, ok := f.vms[namespace+"/"+name]
+	if !ok {
+		return nil, fmt.Errorf("VM %v not found", name)
+	}
+	if f.failPatch {
+		return nil, fmt.Errorf("conflict")
+	}
+	f.patchedLabels[name] = labels
+	if vm.Spec.Template.ObjectMeta.Labels == nil {
+		vm.Spec.Template.ObjectMeta.Labels = map[string]string{}
+	}
+	for key, value := range labels {
+		vm.Spec.Template.ObjectMeta.Labels[key] = value
+	}
+	return vm.DeepCopy(), nil
+}
+
+func (f *fakeVirtualMachineProvider) DryRunCreate(namespace string, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	createdVM := vm.DeepCopy()
+	createdVM.Namespace = namespace
+	f.generateName(createdVM)
+	f.dryRunCreated = append(f.dryRunCreated, createdVM.Name)
+	return createdVM, nil
+}
+
+func (f *fakeVirtualMachineProvider) Start(_, _ string) error {
+	return nil
+}
+
+func (f *fakeVirtualMachineProvider) Delete(namespace, name string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	delete(f.vms, namespace+"/"+name)
+	f.deleted = append(f.deleted, name)
+	return nil
+}
+
+func (f *fakeVirtualMachineProvider) getNames() []string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	var names []string
+	for _, vm := range f.vms {
+		names = append(names, vm.Name)
+	}
+	return names
+}
+
+type fakeDataVolumeProvider struct {
+	lock    sync.Mutex
+	deleted []string
+}
+
+func (f *fakeDataVolumeProvider) Get(_, name string) (*cdiv1beta1.DataVolume, error) {
+	return nil, k8serrors.NewNotFound(schema.GroupResource{Group: "cdi.kubevirt.io", Resource: "datavolumes"}, name)
+}
+
+func (f *fakeDataVolumeProvider) Delete(_, name string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.deleted = append(f.deleted, name)
+	return nil
+}
+
+type fakeServiceProvider struct {
+	lock          sync.Mutex
+	created       []*corev1.Service
+	dryRunCreated []*corev1.Service
+	deleted       []string
+}
+
+func (f *fakeServiceProvider) Create(service *corev1.Service) (*corev1.Service, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.created = append(f.created, service)
+	return service, nil
+}
+
+func (f *fakeServiceProvider) DryRunCreate(service *corev1.Service) (*corev1.Service, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.dryRunCreated = append(f.dryRunCreated, service)
+	return service, nil
+}
+
+func (f *fakeServiceProvider) Delete(_, name string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.deleted = append(f.deleted, name)
+	return nil
+}
+
+// fakeVMIProvider returns VMIs by their names
+type fakeVMIProvider struct {
+	vmis map[string]*kubevirtv1.VirtualMachineInstance
+}
+
+func (f *fakeVMIProvider) Get(_, name string) (*kubevirtv1.VirtualMachineInstance, error) {
+	if vmi, ok := f.vmis[name]; ok {
+		return vmi, nil
+	}
+	return nil, k8serrors.NewNotFound(schema.GroupResource{Group: "kubevirt.io", Resource: "virtualmachineinstances"}, name)
+}
+
+func newVMI(name string, phase kubevirtv1.VirtualMachineInstancePhase, ip string) *kubevirtv1.VirtualMachineInstance {
+	return &kubevirtv1.VirtualMachineInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Status: kubevirtv1.VirtualMachineInstanceStatus{
+			Phase: phase,
+			Conditions: []kubevirtv1.VirtualMachineInstanceCondition{
+				{Type: kubevirtv1.VirtualMachineInstanceAgentConnected, Status: corev1.ConditionTrue},
+			},
+			Interfaces: []kubevirtv1.VirtualMachineInstanceNetworkInterface{{IP: ip}},
+		},
+	}
+}
+
+// fakeObjectProvider records created and deleted objects as KIND/NAME and fails creation of objects of failKind
+type fakeObjectProvider struct {
+	failKind string
+	created  []string
+	deleted  []string
+}
+
+func (f *fakeObjectProvider) Create(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	if obj.GetKind() == f.failKind {
+		return nil, fmt.Errorf("forbidden")
+	}
+	f.created = append(f.created, obj.GetKind()+"/"+obj.GetName())
+	return obj, nil
+}
+
+func (f *fakeObjectProvider) DryRunCreate(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	return obj, nil
+}
+
+func (f *fakeObjectProvider) Delete(obj *unstructured.Unstructured) error {
+	f.deleted = append(f.deleted, obj.GetKind()+"/"+obj.GetName())
+	return nil
+}
+
+type fakeTemplateProvider struct {
+	template *templatev1.Template
+}
+
+func (f *fakeTemplateProvider) Get(_, name string) (*templatev1.Template, error) {
+	if f.template.Name != name {
+		return nil, k8serrors.NewNotFound(schema.GroupResource{Group: "template.openshift.io", Resource: "templates"}, name)
+	}
+	return f.template, nil
+}
+
+func (f *fakeTemplateProvider) List(_, _ string) ([]templatev1.Template, error) {
+	return []templatev1.Template{*f.template}, nil
+}
+
+func (f *fakeTemplateProvider) Process(template *templatev1.Template, paramValues map[string]string) (*templatev1.Template, error) {
+	return templates.ProcessTemplate(template, paramValues)
+}
+
+func newVMCreator(options *parse.CLIOptions, providers *vmcreator.Providers) *vmcreator.VMCreator {
+	options.VirtualMachineNamespace = testNamespace
+	Expect(options.Init()).To(Succeed())
+	return vmcreator.NewVMCreatorWithProviders(options, providers)
+}
+
+func getVMNames(vms []*kubevirtv1.VirtualMachine) []string {
+	var names []string
+	for _, vm := range vms {
+		names = append(names, vm.Name)
+	}
+	return names
+}
+
+var _ = Describe("VMCreator", func() {
+	var vmProvider *fakeVirtualMachineProvider
+	var dataVolumeProvider *fakeDataVolumeProvider
+	var serviceProvider *fakeServiceProvider
+	var providers *vmcreator.Providers
+
+	BeforeEach(func() {
+		vmProvider = newFakeVirtualMachineProvider()
+		dataVolumeProvider = &fakeDataVolumeProvider{}
+		serviceProvider = &fakeServiceProvider{}
+		providers = &vmcreator.Providers{
+			VirtualMachine: vmProvider,
+			DataVolume:     dataVolumeProvider,
+			Service:        serviceProvider,
+		}
+	})
+
+	Describe("creates replicas", func() {
+		It("creates all replicas with their own data volumes", func() {
+			vmCreator := newVMCreator(&parse.CLIOptions{VirtualMachineManifest: testVMManifest, Count: "3"}, providers)
+
+			vms, err := vmCreator.CreateVMs()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(getVMNames(vms)).To(Equal([]string{"my-vm-0", "my-vm-1", "my-vm-2"}))
+			for _, vm := range vms {
+				Expect(vm.Namespace).To(Equal(testNamespace))
+				Expect(vm.Spec.DataVolumeTemplates[0].Name).To(Equal(vm.Name + "-rootdisk"))
+				Expect(vm.Spec.Template.ObjectMeta.Labels).To(HaveKeyWithValue("vm.kubevirt.io/name", vm.Name))
+			}
+			Expect(vmCreator.GetObjects()).To(ConsistOf(
+				bundle.ObjectReference{Kind: "VirtualMachine", Name: "my-vm-0", Namespace: testNamespace},
+				bundle.ObjectReference{Kind: "VirtualMachine", Name: "my-vm-1", Namespace: testNamespace},
+				bundle.ObjectReference{Kind: "VirtualMachine", Name: "my-vm-2", Namespace: testNamespace},
+			))
+		})
+
+		It("creates replicas concurrently up to the parallelism", func() {
+			vmProvider.createDelay = 50 * time.Millisecond
+			vmCreator := newVMCreator(&parse.CLIOptions{VirtualMachineManifest: testVMManifest, Count: "6", Parallelism: "2"}, providers)
+
+			vms, err := vmCreator.CreateVMs()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(vms).To(HaveLen(6))
+			Expect(vmProvider.maxCreating).To(Equal(2))
+		})
+
+		It("creates the remaining replicas and reports the failed ones", func() {
+			vmProvider.failNames["my-vm-1"] = true
+			vmProvider.failNames["my-vm-3"] = true
+			vmCreator := newVMCreator(&parse.CLIOptions{VirtualMachineManifest: testVMManifest, Count: "4"}, providers)
+
+			_, err := vmCreator.CreateVMs()
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("could not create VM my-vm-1: admission webhook denied the request"))
+			Expect(err.Error()).To(ContainSubstring("could not create VM my-vm-3: admission webhook denied the request"))
+			Expect(vmCreator.GetObjects()).To(ConsistOf(
+				bundle.ObjectReference{Kind: "VirtualMachine", Name: "my-vm-0", Namespace: testNamespace},
+				bundle.ObjectReference{Kind: "VirtualMachine", Name: "my-vm-2", Namespace: testNamespace},
+			))
+			Expect(vmProvider.getNames()).To(ConsistOf("my-vm-0", "my-vm-2"))
+		})
+
+		It("rolls back created replicas and their data volumes when a replica fails", func() {
+			vmProvider.failNames["my-vm-1"] = true
+			vmCreator := newVMCreator(&parse.CLIOptions{VirtualMachineManifest: testVMManifest, Count: "3", RollbackOnFailure: "true"}, providers)
+
+			_, err := vmCreator.CreateVMs()
+			Expect(err).Should(HaveOccurred())
+
+			err = vmCreator.Rollback(err)
+			Expect(err.Error()).To(ContainSubstring("could not create VM my-vm-1"))
+			Expect(vmProvider.getNames()).To(BeEmpty())
+			Expect(vmProvider.deleted).To(ConsistOf("my-vm-0", "my-vm-2"))
+			Expect(dataVolumeProvider.deleted).To(ConsistOf("my-vm-0-rootdisk", "my-vm-2-rootdisk"))
+		})
+
+		It("keeps created replicas without rollback", func() {
+			vmProvider.failNames["my-vm-1"] = true
+			vmCreator := newVMCreator(&parse.CLIOptions{VirtualMachineManifest: testVMManifest, Count: "3"}, providers)
+
+			_, err := vmCreator.CreateVMs()
+			Expect(err).Should(HaveOccurred())
+
+			Expect(vmCreator.Rollback(err)).To(Equal(err))
+			Expect(vmProvider.getNames()).To(ConsistOf("my-vm-0", "my-vm-2"))
+			Expect(vmProvider.deleted).To(BeEmpty())
+			Expect(dataVolumeProvider.deleted).To(BeEmpty())
+		})
+	})
+
+	Describe("creates services", func() {
+		It("exposes each replica through its own service owned by the VM", func() {
+			vmCreator := newVMCreator(&parse.CLIOptions{VirtualMachineManifest: testVMManifest, Count: "2", ServicePorts: []string{"ssh:22"}}, providers)
+
+			vms, err := vmCreator.CreateVMs()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(serviceProvider.created).To(HaveLen(2))
+
+			for _, vm := range vms {
+				createdService := vmCreator.GetService(vm.Name)
+				Expect(createdService).ToNot(BeNil())
+				Expect(createdService.Name).To(Equal(vm.Name))
+				Expect(createdService.Spec.Selector).To(HaveKeyWithValue("vm.kubevirt.io/name", vm.Name))
+				Expect(createdService.OwnerReferences).To(HaveLen(1))
+				Expect(createdService.OwnerReferences[0].UID).To(Equal(vm.UID))
+				Expect(vmCreator.GetObjects()).To(ContainElement(bundle.ObjectReference{Kind: "Service", Name: vm.Name, Namespace: testNamespace}))
+			}
+		})
+
+		It("rolls back services together with the VMs", func() {
+			vmCreator := newVMCreator(&parse.CLIOptions{VirtualMachineManifest: testVMManifest, ServicePorts: []string{"ssh:22"}, RollbackOnFailure: "true"}, providers)
+
+			_, err := vmCreator.CreateVMs()
+			Expect(err).ShouldNot(HaveOccurred())
+
+			err = vmCreator.Rollback(fmt.Errorf("VMI did not become ready"))
+			Expect(err.Error()).To(ContainSubstring("VMI did not become ready"))
+			Expect(serviceProvider.deleted).To(Equal([]string{"my-vm"}))
+			Expect(vmProvider.deleted).To(Equal([]string{"my-vm"}))
+			Expect(dataVolumeProvider.deleted).To(Equal([]string{"my-vm-rootdisk"}))
+		})
+	})
+
+	DescribeTable("dry run does not persist the VMs and services", func(dryRun string, expectedDryRunVMs, expectedDryRunServices int) {
+		vmCreator := newVMCreator(&parse.CLIOptions{VirtualMachineManifest: testVMManifest, Count: "2", ServicePorts: []string{"ssh:22"},
+			DryRun: dryRun, RollbackOnFailure: "true"}, providers)
+
+		vms, err := vmCreator.CreateVMs()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(getVMNames(vms)).To(Equal([]string{"my-vm-0", "my-vm-1"}))
+		for _, vm := range vms {
+			Expect(vmCreator.GetService(vm.Name)).ToNot(BeNil())
+		}
+		Expect(vmCreator.GetObjects()).To(HaveLen(4))
+
+		Expect(vmProvider.dryRunCreated).To(HaveLen(expectedDryRunVMs))
+		Expect(serviceProvider.dryRunCreated).To(HaveLen(expectedDryRunServices))
+		Expect(vmProvider.getNames()).To(BeEmpty())
+		Expect(serviceProvider.created).To(BeEmpty())
+
+		// nothing is tracked for rollback
+		cause := fmt.Errorf("failed")
+		Expect(vmCreator.Rollback(cause)).To(Equal(cause))
+		Expect(vmProvider.deleted).To(BeEmpty())
+		Expect(serviceProvider.deleted).To(BeEmpty())
+	},
+		Entry("client", "client", 0, 0),
+		Entry("server", "server", 2, 2),
+	)
+
+	Describe("creates VMs with generateName", func() {
+		It("labels the VMI template by the name generated by the server", func() {
+			vmCreator := newVMCreator(&parse.CLIOptions{VirtualMachineManifest: testGenerateNameVMManifest, ServicePorts: []string{"ssh:22"}}, providers)
+
+			vms, err := vmCreator.CreateVMs()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(getVMNames(vms)).To(Equal([]string{"my-vm-gen1"}))
+			Expect(vmProvider.patchedLabels).To(Equal(map[string]map[string]string{
+				"my-vm-gen1": {"vm.kubevirt.io/name": "my-vm-gen1"},
+			}))
+			Expect(vms[0].Spec.Template.ObjectMeta.Labels).To(HaveKeyWithValue("vm.kubevirt.io/name", "my-vm-gen1"))
+
+			// the service selects the VMI by the generated name
+			Expect(vmCreator.GetService("my-vm-gen1").Spec.Selector).To(HaveKeyWithValue("vm.kubevirt.io/name", "my-vm-gen1"))
+		})
+
+		It("labels each replica by its generated name", func() {
+			vmCreator := newVMCreator(&parse.CLIOptions{VirtualMachineManifest: testGenerateNameVMManifest, Count: "2"}, providers)
+
+			vms, err := vmCreator.CreateVMs()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(vms).To(HaveLen(2))
+			Expect(vms[0].Name).To(HavePrefix("my-vm-0-gen"))
+			Expect(vms[1].Name).To(HavePrefix("my-vm-1-gen"))
+			for _, vm := range vms {
+				Expect(vmProvider.patchedLabels).To(HaveKeyWithValue(vm.Name, map[string]string{"vm.kubevirt.io/name": vm.Name}))
+				Expect(vm.Spec.Template.ObjectMeta.Labels).To(HaveKeyWithValue("vm.kubevirt.io/name", vm.Name))
+			}
+		})
+
+		It("does not patch a VM in server dry run", func() {
+			vmCreator := newVMCreator(&parse.CLIOptions{VirtualMachineManifest: testGenerateNameVMManifest, DryRun: "server"}, providers)
+
+			vms, err := vmCreator.CreateVMs()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(vmProvider.patchedLabels).To(BeEmpty())
+			Expect(vms[0].Spec.Template.ObjectMeta.Labels).To(HaveKeyWithValue("vm.kubevirt.io/name", vms[0].Name))
+		})
+
+		It("rolls back the VM when the label cannot be added", func() {
+			vmProvider.failPatch = true
+			vmCreator := newVMCreator(&parse.CLIOptions{VirtualMachineManifest: testGenerateNameVMManifest, RollbackOnFailure: "true"}, providers)
+
+			_, err := vmCreator.CreateVMs()
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("could not add vm.kubevirt.io/name label to template of VM my-vm-gen1: conflict"))
+
+			Expect(vmCreator.Rollback(err)).Should(HaveOccurred())
+			Expect(vmProvider.deleted).To(Equal([]string{"my-vm-gen1"}))
+		})
+	})
+
+	Describe("creates VM manifest bundles", func() {
+		var objectProvider *fakeObjectProvider
+
+		BeforeEach(func() {
+			objectProvider = &fakeObjectProvider{}
+			providers.Object = objectProvider
+		})
+
+		It("creates dependencies before the VM and dependents after it", func() {
+			vmCreator := newVMCreator(&parse.CLIOptions{VirtualMachineManifest: testBundleManifest}, providers)
+
+			vms, err := vmCreator.CreateVMs()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(getVMNames(vms)).To(Equal([]string{"my-vm"}))
+			Expect(objectProvider.created).To(Equal([]string{"Secret/my-vm-secret", "ConfigMap/my-vm-config", "DataVolume/my-vm-data", "Service/my-vm-ssh"}))
+			Expect(vmCreator.GetObjects()).To(Equal([]bundle.ObjectReference{
+				{Kind: "Secret", Name: "my-vm-secret", Namespace: testNamespace},
+				{Kind: "ConfigMap", Name: "my-vm-config", Namespace: testNamespace},
+				{Kind: "DataVolume", Name: "my-vm-data", Namespace: testNamespace},
+				{Kind: "VirtualMachine", Name: "my-vm", Namespace: testNamespace},
+				{Kind: "Service", Name: "my-vm-ssh", Namespace: testNamespace},
+			}))
+		})
+
+		It("rolls back the VM and its dependencies in reverse order when a dependent fails", func() {
+			objectProvider.failKind = "Service"
+			vmCreator := newVMCreator(&parse.CLIOptions{VirtualMachineManifest: testBundleManifest, RollbackOnFailure: "true"}, providers)
+
+			_, err := vmCreator.CreateVMs()
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("could not create Service my-vm-ssh: forbidden"))
+
+			Expect(vmCreator.Rollback(err)).Should(HaveOccurred())
+			Expect(vmProvider.deleted).To(Equal([]string{"my-vm"}))
+			Expect(dataVolumeProvider.deleted).To(Equal([]string{"my-vm-rootdisk"}))
+			Expect(objectProvider.deleted).To(Equal([]string{"DataVolume/my-vm-data", "ConfigMap/my-vm-config", "Secret/my-vm-secret"}))
+		})
+
+		It("does not create the VM when a dependency fails", func() {
+			objectProvider.failKind = "ConfigMap"
+			vmCreator := newVMCreator(&parse.CLIOptions{VirtualMachineManifest: testBundleManifest, RollbackOnFailure: "true"}, providers)
+
+			_, err := vmCreator.CreateVMs()
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("could not create ConfigMap my-vm-config: forbidden"))
+			Expect(vmProvider.getNames()).To(BeEmpty())
+
+			Expect(vmCreator.Rollback(err)).Should(HaveOccurred())
+			Expect(objectProvider.deleted).To(Equal([]string{"Secret/my-vm-secret"}))
+		})
+
+		It("rejects objects in another namespace", func() {
+			manifest := strings.Replace(testBundleManifest, "  name: my-vm-config\n", "  name: my-vm-config\n  namespace: other\n", 1)
+			vmCreator := newVMCreator(&parse.CLIOptions{VirtualMachineManifest: manifest}, providers)
+
+			_, err := vmCreator.CreateVMs()
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("ConfigMap my-vm-config should be in the default namespace of the VM, but is in other"))
+			Expect(objectProvider.created).To(Equal([]string{"Secret/my-vm-secret"}))
+			Expect(vmProvider.getNames()).To(BeEmpty())
+		})
+
+		It("rejects count with multiple objects", func() {
+			vmCreator := newVMCreator(&parse.CLIOptions{VirtualMachineManifest: testBundleManifest, Count: "2"}, providers)
+
+			_, err := vmCreator.CreateVMs()
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("count option is not applicable for VM manifests with multiple objects"))
+			Expect(objectProvider.created).To(BeEmpty())
+		})
+	})
+
+	Describe("waits for VMIs of all replicas", func() {
+		var vmiProvider *fakeVMIProvider
+
+		BeforeEach(func() {
+			vmiProvider = &fakeVMIProvider{vmis: map[string]*kubevirtv1.VirtualMachineInstance{}}
+			providers.VMI = vmiProvider
+		})
+
+		It("returns ready VMIs in the order of the VMs", func() {
+			vmiProvider.vmis["my-vm-0"] = newVMI("my-vm-0", kubevirtv1.Running, "10.0.0.1")
+			vmiProvider.vmis["my-vm-1"] = newVMI("my-vm-1", kubevirtv1.Running, "10.0.0.2")
+			vmCreator := newVMCreator(&parse.CLIOptions{VirtualMachineManifest: testVMManifest, Count: "2", WaitForReady: "true"}, providers)
+
+			vms, err := vmCreator.CreateVMs()
+			Expect(err).ShouldNot(HaveOccurred())
+
+			readyVMIs, err := vmCreator.WaitForVMIsReady(vms)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(readyVMIs).To(HaveLen(2))
+			Expect(readyVMIs[0].Name).To(Equal("my-vm-0"))
+			Expect(readyVMIs[1].Name).To(Equal("my-vm-1"))
+			Expect(vmCreator.GetVMResults(vms, readyVMIs)).To(Equal([]vmcreator.VMResult{
+				{Name: "my-vm-0", VMIIP: "10.0.0.1"},
+				{Name: "my-vm-1", VMIIP: "10.0.0.2"},
+			}))
+		})
+
+		It("reports VMIs which failed to become ready", func() {
+			vmiProvider.vmis["my-vm-0"] = newVMI("my-vm-0", kubevirtv1.Running, "10.0.0.1")
+			vmiProvider.vmis["my-vm-1"] = newVMI("my-vm-1", kubevirtv1.Failed, "")
+			vmCreator := newVMCreator(&parse.CLIOptions{VirtualMachineManifest: testVMManifest, Count: "2", WaitForReady: "true"}, providers)
+
+			vms, err := vmCreator.CreateVMs()
+			Expect(err).ShouldNot(HaveOccurred())
+
+			readyVMIs, err := vmCreator.WaitForVMIsReady(vms)
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("VMI default/my-vm-1 is in Failed phase"))
+			Expect(err.Error()).ToNot(ContainSubstring("my-vm-0"))
+			Expect(readyVMIs[0].Name).To(Equal("my-vm-0"))
+			Expect(readyVMIs[1]).To(BeNil())
+		})
+	})
+
+	Describe("creates VMs from a template", func() {
+		BeforeEach(func() {
+			providers.Template = &fakeTemplateProvider{template: template.NewFedoraServerTinyTemplate().Build()}
+		})
+
+		createTemplateVM := func(dryRun string) *kubevirtv1.VirtualMachine {
+			vmCreator := newVMCreator(&parse.CLIOptions{
+				TemplateName:      "fedora-server-tiny-v0.7.0",
+				TemplateNamespace: "openshift",
+				TemplateParams:    []string{"NAME:my-vm", "PVCNAME:my-pvc"},
+				ServicePorts:      []string{"ssh:22"},
+				DryRun:            dryRun,
+			}, providers)
+
+			vms, err := vmCreator.CreateVMs()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(vms).To(HaveLen(1))
+			Expect(vmCreator.GetService(vms[0].Name)).ToNot(BeNil())
+			return vms[0]
+		}
+
+		It("adds the template metadata to the VM", func() {
+			vm := createTemplateVM("")
+			Expect(vm.Labels).To(HaveKeyWithValue("vm.kubevirt.io/template", "fedora-server-tiny-v0.7.0"))
+			Expect(vm.Labels).To(HaveKeyWithValue("vm.kubevirt.io/template.namespace", "openshift"))
+			Expect(vm.Spec.Template.ObjectMeta.Labels).To(HaveKeyWithValue("vm.kubevirt.io/name", "my-vm"))
+			Expect(serviceProvider.created).To(HaveLen(1))
+		})
+
+		DescribeTable("dry run renders the same metadata as a real run", func(dryRun string) {
+			vm := createTemplateVM("")
+			dryRunVM := createTemplateVM(dryRun)
+
+			Expect(dryRunVM.Labels).To(Equal(vm.Labels))
+			Expect(dryRunVM.Annotations).To(Equal(vm.Annotations))
+			Expect(dryRunVM.Spec.Template.ObjectMeta.Labels).To(Equal(vm.Spec.Template.ObjectMeta.Labels))
+		},
+			Entry("client", "client"),
+			Entry("server", "server"),
+		)
+	})
+})
@@ -0,0 +1,86 @@
+package vmcreator_test
+
+import (
+	"strings"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/bundle"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/utils/parse"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/vmcreator"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+var _ = Describe("VMResults", func() {
+	var vmCreator *vmcreator.VMCreator
+	var vms []*kubevirtv1.VirtualMachine
+
+	createVMs := func(count string) {
+		vmCreator = newVMCreator(&parse.CLIOptions{VirtualMachineManifest: testVMManifest, Count: count, ServicePorts: []string{"ssh:22"}},
+			&vmcreator.Providers{
+				VirtualMachine: newFakeVirtualMachineProvider(),
+				Service:        &fakeServiceProvider{},
+			})
+
+		var err error
+		vms, err = vmCreator.CreateVMs()
+		Expect(err).ShouldNot(HaveOccurred())
+	}
+
+	It("returns names of VMs without ready VMIs", func() {
+		createVMs("2")
+		Expect(vmCreator.GetVMResults(vms, nil)).To(Equal([]vmcreator.VMResult{
+			{Name: "my-vm-0"},
+			{Name: "my-vm-1"},
+		}))
+	})
+
+	It("returns the IP address of each VM", func() {
+		createVMs("2")
+		readyVMIs := []*kubevirtv1.VirtualMachineInstance{
+			{Status: kubevirtv1.VirtualMachineInstanceStatus{
+				Interfaces: []kubevirtv1.VirtualMachineInstanceNetworkInterface{{IP: "10.0.0.1"}},
+			}},
+			{Status: kubevirtv1.VirtualMachineInstanceStatus{
+				Interfaces: []kubevirtv1.VirtualMachineInstanceNetworkInterface{{Name: "default"}, {IP: "10.0.0.2"}},
+			}},
+		}
+
+		Expect(vmCreator.GetVMResults(vms, readyVMIs)).To(Equal([]vmcreator.VMResult{
+			{Name: "my-vm-0", VMIIP: "10.0.0.1"},
+			{Name: "my-vm-1", VMIIP: "10.0.0.2"},
+		}))
+	})
+
+	DescribeTable("GetObjectResults", func(count string, expectedObjects []bundle.ObjectReference) {
+		createVMs(count)
+		Expect(vmCreator.GetObjectResults(vms)).To(Equal(expectedObjects))
+	},
+		Entry("lists the VM and its service", "1", []bundle.ObjectReference{
+			{Kind: "VirtualMachine", Name: "my-vm", Namespace: testNamespace},
+			{Kind: "Service", Name: "my-vm", Namespace: testNamespace},
+		}),
+		Entry("leaves out VMs and services covered by names", "3", []bundle.ObjectReference{}),
+	)
+
+	DescribeTable("ValidateResultsSize", func(results map[string]string, expectedErrMessage string) {
+		err := vmcreator.ValidateResultsSize(results)
+		if expectedErrMessage == "" {
+			Expect(err).ShouldNot(HaveOccurred())
+		} else {
+			Expect(err).Should(MatchError(ContainSubstring(expectedErrMessage)))
+		}
+	},
+		Entry("no results", map[string]string{}, ""),
+		Entry("results within the limit", map[string]string{
+			"name":  "my-vm",
+			"names": `["` + strings.Repeat("a", 2000) + `"]`,
+		}, ""),
+		Entry("too large results", map[string]string{
+			"names": strings.Repeat("a", 3100),
+		}, "results of 3107 bytes are too large to be recorded, only 3072 bytes are allowed"),
+		Entry("results too large after escaping", map[string]string{
+			"names": strings.Repeat("<", 1000),
+		}, "results of 6007 bytes are too large to be recorded"),
+	)
+})
@@ -0,0 +1,27 @@
+package vmi
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	kubevirtcliv1 "kubevirt.io/client-go/kubecli"
+)
+
+type virtualMachineInstanceProvider struct {
+	client kubevirtcliv1.KubevirtClient
+}
+
+type VirtualMachineInstanceProvider interface {
+	Get(namespace, name string) (*kubevirtv1.VirtualMachineInstance, error)
+}
+
+func NewVirtualMachineInstanceProvider(client kubevirtcliv1.KubevirtClient) VirtualMachineInstanceProvider {
+	return &virtualMachineInstanceProvider{
+		client: client,
+	}
+}
+
+func (v *virtualMachineInstanceProvider) Get(namespace, name string) (*kubevirtv1.VirtualMachineInstance, error) {
+	return v.client.VirtualMachineInstance(namespace).Get(context.Background(), name, &metav1.GetOptions{})
+}
@@ -0,0 +1,90 @@
+package vmi
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/constants"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/log"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zerrors"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+// WaitForReady waits until the VMI is running, its guest agent is connected and it reports an IP address.
+// Timeout of 0 or less waits for the default timeout.
+func WaitForReady(provider VirtualMachineInstanceProvider, namespace, name string, timeout time.Duration) (*kubevirtv1.VirtualMachineInstance, error) {
+	var readyVMI *kubevirtv1.VirtualMachineInstance
+	logFields := []zap.Field{zap.String("name", name), zap.String("namespace", namespace)}
+
+	conditionFn := func() (bool, error) {
+		vmInstance, err := provider.Get(namespace, name)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				log.Logger().Debug("waiting for a VMI to be created", logFields...)
+				return false, nil
+			}
+			return false, err
+		}
+
+		switch vmInstance.Status.Phase {
+		case kubevirtv1.Succeeded, kubevirtv1.Failed:
+			return false, zerrors.NewSoftError("VMI %v/%v is in %v phase", namespace, name, vmInstance.Status.Phase)
+		case kubevirtv1.Running:
+			if !IsAgentConnected(vmInstance) {
+				log.Logger().Debug("waiting for a guest agent to connect", logFields...)
+				return false, nil
+			}
+			if GetIPAddress(vmInstance) == "" {
+				log.Logger().Debug("waiting for a VMI to report an ip address", logFields...)
+				return false, nil
+			}
+			readyVMI = vmInstance
+			return true, nil
+		default:
+			log.Logger().Debug("waiting for a VMI to start", logFields...)
+			return false, nil
+		}
+	}
+
+	if timeout <= 0 {
+		timeout = constants.DefaultWaitTimeout
+	}
+
+	err := wait.PollImmediate(constants.PollVMIInterval, timeout, conditionFn)
+	if err == wait.ErrWaitTimeout {
+		return nil, zerrors.NewSoftError("timed out waiting for VMI %v/%v to be ready", namespace, name)
+	}
+
+	return readyVMI, err
+}
+
+func IsAgentConnected(vmInstance *kubevirtv1.VirtualMachineInstance) bool {
+	for _, condition := range vmInstance.Status.Conditions {
+		if condition.Type == kubevirtv1.VirtualMachineInstanceAgentConnected {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// GetIPAddress returns the first IP address reported by an interface of the VMI
+func GetIPAddress(vmInstance *kubevirtv1.VirtualMachineInstance) string {
+	for _, iface := range vmInstance.Status.Interfaces {
+		if iface.IP != "" {
+			return iface.IP
+		}
+	}
+	return ""
+}
+
+func GetGuestOSInfo(vmInstance *kubevirtv1.VirtualMachineInstance) (string, error) {
+	guestOSInfo, err := json.Marshal(vmInstance.Status.GuestOSInfo)
+	if err != nil {
+		return "", err
+	}
+	return string(guestOSInfo), nil
+}
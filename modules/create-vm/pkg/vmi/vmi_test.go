@@ -0,0 +1,79 @@
+package vmi_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/create-vm/pkg/vmi"
+)
+
+type fakeVMIProvider struct {
+	vmi *kubevirtv1.VirtualMachineInstance
+	err error
+}
+
+func (f *fakeVMIProvider) Get(_, _ string) (*kubevirtv1.VirtualMachineInstance, error) {
+	return f.vmi, f.err
+}
+
+func newVMI(phase kubevirtv1.VirtualMachineInstancePhase, agentConnected bool, ip string) *kubevirtv1.VirtualMachineInstance {
+	vmInstance := &kubevirtv1.VirtualMachineInstance{
+		Status: kubevirtv1.VirtualMachineInstanceStatus{
+			Phase:    phase,
+			NodeName: "node01",
+			GuestOSInfo: kubevirtv1.VirtualMachineInstanceGuestOSInfo{
+				Name:    "Fedora Linux",
+				Version: "38",
+			},
+			Interfaces: []kubevirtv1.VirtualMachineInstanceNetworkInterface{
+				{Name: "secondary"},
+				{Name: "default", IP: ip},
+			},
+		},
+	}
+	if agentConnected {
+		vmInstance.Status.Conditions = []kubevirtv1.VirtualMachineInstanceCondition{
+			{Type: kubevirtv1.VirtualMachineInstanceAgentConnected, Status: corev1.ConditionTrue},
+		}
+	}
+	return vmInstance
+}
+
+var _ = Describe("VMI", func() {
+	It("returns ready VMI", func() {
+		readyVMI := newVMI(kubevirtv1.Running, true, "10.0.0.5")
+		result, err := vmi.WaitForReady(&fakeVMIProvider{vmi: readyVMI}, "default", "test", 0)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(result).To(Equal(readyVMI))
+		Expect(vmi.GetIPAddress(result)).To(Equal("10.0.0.5"))
+	})
+
+	DescribeTable("times out when VMI is not ready", func(provider vmi.VirtualMachineInstanceProvider) {
+		_, err := vmi.WaitForReady(provider, "default", "test", time.Millisecond)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(Equal("timed out waiting for VMI default/test to be ready"))
+	},
+		Entry("not found", &fakeVMIProvider{err: errors.NewNotFound(schema.GroupResource{Resource: "virtualmachineinstances"}, "test")}),
+		Entry("scheduling", &fakeVMIProvider{vmi: newVMI(kubevirtv1.Scheduling, false, "")}),
+		Entry("agent not connected", &fakeVMIProvider{vmi: newVMI(kubevirtv1.Running, false, "10.0.0.5")}),
+		Entry("no ip address", &fakeVMIProvider{vmi: newVMI(kubevirtv1.Running, true, "")}),
+	)
+
+	It("fails when VMI failed", func() {
+		_, err := vmi.WaitForReady(&fakeVMIProvider{vmi: newVMI(kubevirtv1.Failed, false, "")}, "default", "test", time.Minute)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(Equal("VMI default/test is in Failed phase"))
+	})
+
+	It("returns guest os info", func() {
+		guestOSInfo, err := vmi.GetGuestOSInfo(newVMI(kubevirtv1.Running, true, "10.0.0.5"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(guestOSInfo).To(Equal(`{"name":"Fedora Linux","version":"38"}`))
+	})
+})
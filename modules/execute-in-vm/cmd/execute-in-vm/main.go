@@ -1,17 +1,17 @@
 package main
 
 import (
+	"os"
+
 	goarg "github.com/alexflint/go-arg"
 	. "github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/constants"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/execute"
-	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/utils"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/fanout"
 	log "github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/utils/log"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/utils/parse"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/exit"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zerrors"
 	"go.uber.org/zap"
-	"k8s.io/apimachinery/pkg/util/wait"
-	"time"
 )
 
 func main() {
@@ -28,73 +28,17 @@ func main() {
 		exit.ExitOrDieFromError(InvalidArguments, err)
 	}
 
+	if cliOptions.GetVirtualMachineSelector() != "" {
+		executeInFleet(cliOptions)
+		return
+	}
+
 	executor, executorErr := execute.NewExecutor(cliOptions, ConnectionSecretPath)
 	if executorErr != nil {
 		exit.ExitOrDieFromError(ExecutorInitialization, executorErr)
 	}
 
-	multiError := zerrors.NewMultiError()
-	var exitError *exit.Exit
-
-	registerError := func(name string, err error) {
-		if err != nil {
-			if exitErr, ok := err.(exit.Exit); ok {
-				exitError = &exitErr
-			} else if err == wait.ErrWaitTimeout {
-				exitError = &exit.Exit{
-					Code: CommandTimeout,
-					Msg:  "command timed out",
-					Soft: true,
-				}
-			} else {
-				multiError.Add(name, err)
-			}
-		}
-
-	}
-
-	if cliOptions.GetScript() != "" {
-		runWithTimeout := utils.WithTimeout(cliOptions.GetScriptTimeout())
-
-		runWithTimeout(func(timeout time.Duration, finished bool) {
-			if multiError.IsEmpty() && !finished {
-				err := executor.EnsureVMRunning(timeout)
-				registerError("EnsureVMRunning", err)
-			}
-		})
-
-		runWithTimeout(func(timeout time.Duration, finished bool) {
-			if multiError.IsEmpty() && !finished {
-				err := executor.SetupConnection(timeout)
-				registerError("SetupConnection", err)
-			}
-		})
-
-		runWithTimeout(func(timeout time.Duration, finished bool) {
-			if multiError.IsEmpty() {
-				if !finished {
-					err := executor.RemoteExecute(timeout)
-					registerError("RemoteExecute", err)
-				} else {
-					registerError("RemoteExecute", wait.ErrWaitTimeout)
-				}
-
-			}
-		})
-
-	}
-
-	if cliOptions.ShouldStop() {
-		if err := executor.EnsureVMStopped(); err != nil {
-			multiError.Add("VM Stop", err)
-		}
-	}
-
-	if cliOptions.ShouldDelete() {
-		if err := executor.EnsureVMDeleted(); err != nil {
-			multiError.Add("VM Delete", err)
-		}
-	}
+	exitError, multiError := executor.Run()
 
 	if !multiError.IsEmpty() {
 		if exitError != nil {
@@ -109,3 +53,28 @@ func main() {
 		exit.ExitOrDieFromError(exitError.Code, exitError)
 	}
 }
+
+func executeInFleet(cliOptions *parse.CLIOptions) {
+	fleetExecutor, executorErr := execute.NewFleetExecutor(cliOptions, ConnectionSecretPath)
+	if executorErr != nil {
+		exit.ExitOrDieFromError(ExecutorInitialization, executorErr)
+	}
+
+	vmNames, listErr := fleetExecutor.ListVirtualMachines()
+	if listErr != nil {
+		exit.ExitOrDieFromError(ExecutorInitialization, listErr)
+	}
+
+	results := fleetExecutor.Run(vmNames)
+	if err := fanout.WriteSummary(os.Stdout, results); err != nil {
+		log.Logger().Debug("could not write the summary", zap.Error(err))
+	}
+
+	failed := fanout.CountFailed(results)
+	tolerance := cliOptions.GetFailureTolerance(len(results))
+	log.Logger().Debug("finished", zap.Int("failed", failed), zap.Int("tolerance", tolerance))
+
+	if failed > tolerance {
+		exit.ExitOrDieFromError(ExecutorActionsFailed, zerrors.NewSoftError("%v of %v VMs failed, %v failures are tolerated", failed, len(results), tolerance))
+	}
+}
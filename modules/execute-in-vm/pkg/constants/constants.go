@@ -17,5 +17,39 @@ const CheckSSHConnectionTimeout = 3 * time.Second
 const PollVMtoDeleteInterval = 1 * time.Second
 const PollVMItoStopInterval = 1 * time.Second
 const SetupConnectionDelay = 2 * time.Second
+const SSHHandshakeTimeout = 30 * time.Second
+const SSHKeepAliveInterval = 15 * time.Second
+const SSHKeepAliveCountMax = 3
+const SerialConsoleConnectionTimeout = 10 * time.Second
+const SerialConsoleLoginRetryInterval = 5 * time.Second
+const SerialConsoleLoginTimeout = 30 * time.Second
+
+// WinRMRequestTimeout should be longer than the operation timeout of WinRM requests (60s), which is used to wait for command output
+const WinRMRequestTimeout = 90 * time.Second
 
 const EmptyConnectionSecretName = "__empty__"
+
+type ConnectionMode string
+
+const (
+	// PodNetworkConnectionMode connects to the IP address of the VMI on the pod network
+	PodNetworkConnectionMode ConnectionMode = "pod-network"
+	// PortForwardConnectionMode tunnels connections through the portforward subresource of the VMI
+	PortForwardConnectionMode ConnectionMode = "port-forward"
+)
+
+// Results of the task
+const (
+	ExitCodeResultName      = "exitCode"
+	StdoutTailResultName    = "stdoutTail"
+	ScriptResultsResultName = "scriptResults"
+)
+
+// MaxResultsSize is the budget of all results. Tekton stores results in the termination message of the step,
+// which is limited to 4096 bytes including its encoding.
+const MaxResultsSize = 3072
+const DefaultStdoutTailSize = 1024
+const MaxStdoutTailSize = 2048
+
+// DefaultParallelism is the number of VMs matching the vm-selector option which are processed concurrently
+const DefaultParallelism = 5
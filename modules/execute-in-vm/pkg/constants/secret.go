@@ -3,7 +3,28 @@ package constants
 type ExecSecretType string
 
 const (
-	SSHSecretType ExecSecretType = "ssh"
+	SSHSecretType           ExecSecretType = "ssh"
+	SerialConsoleSecretType ExecSecretType = "serial-console"
+	WinRMSecretType         ExecSecretType = "winrm"
+)
+
+type SSHClientType string
+
+const (
+	NativeSSHClient SSHClientType = "native"
+	BinarySSHClient SSHClientType = "binary"
+)
+
+type WinRMShellType string
+
+const (
+	PowerShellWinRMShell WinRMShellType = "powershell"
+	CmdWinRMShell        WinRMShellType = "cmd"
+)
+
+const (
+	WinRMHTTPPort  = 5985
+	WinRMHTTPSPort = 5986
 )
 
 const (
@@ -12,9 +12,11 @@ import (
 )
 
 type attributes struct {
-	secretType constants.ExecSecretType
-	secretPath string
-	ssh        SSHAttributes
+	secretType    constants.ExecSecretType
+	secretPath    string
+	ssh           SSHAttributes
+	serialConsole SerialConsoleAttributes
+	winRM         WinRMAttributes
 }
 
 func NewExecAttributes() ExecAttributes {
@@ -26,6 +28,8 @@ type ExecAttributes interface {
 	Init(execAttributesPath string) error
 	GetType() constants.ExecSecretType
 	GetSSHAttributes() SSHAttributes
+	GetSerialConsoleAttributes() SerialConsoleAttributes
+	GetWinRMAttributes() WinRMAttributes
 }
 
 func (s *attributes) Init(execAttributesPath string) error {
@@ -50,7 +54,7 @@ func (s *attributes) Init(execAttributesPath string) error {
 	secretTypeRaw = strings.TrimSpace(secretTypeRaw)
 
 	switch secretTypeRaw {
-	case string(constants.SSHSecretType):
+	case string(constants.SSHSecretType), string(constants.SerialConsoleSecretType), string(constants.WinRMSecretType):
 		s.secretType = constants.ExecSecretType(secretTypeRaw)
 	default:
 		if sshPrivateKey != "" || sshPrivateKeyAlternativeFormat != "" {
@@ -69,6 +73,16 @@ func (s *attributes) Init(execAttributesPath string) error {
 		if err := s.ssh.initSSH(s.secretPath); err != nil {
 			return err
 		}
+	case constants.SerialConsoleSecretType:
+		s.serialConsole = NewSerialConsoleAttributes()
+		if err := s.serialConsole.initSerialConsole(s.secretPath); err != nil {
+			return err
+		}
+	case constants.WinRMSecretType:
+		s.winRM = NewWinRMAttributes()
+		if err := s.winRM.initWinRM(s.secretPath); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -82,9 +96,27 @@ func (s *attributes) GetSSHAttributes() SSHAttributes {
 	return s.ssh
 }
 
+func (s *attributes) GetSerialConsoleAttributes() SerialConsoleAttributes {
+	return s.serialConsole
+}
+
+func (s *attributes) GetWinRMAttributes() WinRMAttributes {
+	return s.winRM
+}
+
 func (s *attributes) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
 	encoder.AddString("secretType", string(s.secretType))
 	encoder.AddString("secretPath", s.secretPath)
+	if s.serialConsole != nil {
+		if err := encoder.AddObject("serialConsole", s.serialConsole); err != nil {
+			return err
+		}
+	}
+	if s.winRM != nil {
+		if err := encoder.AddObject("winRM", s.winRM); err != nil {
+			return err
+		}
+	}
 	if s.ssh == nil {
 		return encoder.AddReflected("ssh", s.ssh)
 	} else {
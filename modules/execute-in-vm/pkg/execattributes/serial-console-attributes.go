@@ -0,0 +1,95 @@
+package execattributes
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/env/fileoptions"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zconstants/connectionsecret"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zerrors"
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultPrompt matches common shell prompts of bash, sh and zsh which end with $, # or >
+const defaultPrompt = `[$#>]\s*$`
+
+type serialConsoleAttributes struct {
+	user     string
+	password string
+	prompt   *regexp.Regexp
+}
+
+type SerialConsoleAttributes interface {
+	zapcore.ObjectMarshaler
+	initSerialConsole(execSecretPath string) error
+	GetUser() string
+	GetPassword() string
+	GetPrompt() *regexp.Regexp
+}
+
+func NewSerialConsoleAttributes() SerialConsoleAttributes {
+	return &serialConsoleAttributes{}
+}
+
+func (s *serialConsoleAttributes) initSerialConsole(execSecretPath string) error {
+	var prompt string
+
+	stringOptions := map[string]*string{
+		connectionsecret.SerialConsoleConnectionSecretKeys.User:     &s.user,
+		connectionsecret.SerialConsoleConnectionSecretKeys.Password: &s.password,
+		connectionsecret.SerialConsoleConnectionSecretKeys.Prompt:   &prompt,
+	}
+
+	for optionName, output := range stringOptions {
+		if err := fileoptions.ReadFileOption(output, path.Join(execSecretPath, optionName)); err != nil {
+			return err
+		}
+	}
+
+	s.user = strings.TrimSpace(s.user)
+	if s.user == "" {
+		return zerrors.NewMissingRequiredError("%v secret attribute is required", connectionsecret.SerialConsoleConnectionSecretKeys.User)
+	}
+
+	// trailing new lines are usually not part of the password
+	s.password = strings.TrimRight(s.password, "\r\n")
+	if s.password == "" {
+		return zerrors.NewMissingRequiredError("%v secret attribute is required", connectionsecret.SerialConsoleConnectionSecretKeys.Password)
+	}
+
+	prompt = strings.TrimSpace(prompt)
+	if prompt == "" {
+		prompt = defaultPrompt
+	}
+
+	compiledPrompt, err := regexp.Compile(prompt)
+	if err != nil {
+		return zerrors.NewMissingRequiredError("%v is not a valid regular expression: %v", connectionsecret.SerialConsoleConnectionSecretKeys.Prompt, err.Error())
+	}
+	s.prompt = compiledPrompt
+
+	return nil
+}
+
+func (s *serialConsoleAttributes) GetUser() string {
+	return s.user
+}
+
+func (s *serialConsoleAttributes) GetPassword() string {
+	return s.password
+}
+
+// GetPrompt returns a regular expression which matches the end of the shell prompt
+func (s *serialConsoleAttributes) GetPrompt() *regexp.Regexp {
+	return s.prompt
+}
+
+func (s *serialConsoleAttributes) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	// do not print password
+	encoder.AddString("user", s.user)
+	if s.prompt != nil {
+		encoder.AddString("prompt", s.prompt.String())
+	}
+	return nil
+}
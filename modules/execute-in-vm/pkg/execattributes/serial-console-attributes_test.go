@@ -0,0 +1,86 @@
+package execattributes_test
+
+import (
+	"os"
+	"path"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/constants"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/execattributes"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/utils/log"
+	. "github.com/kubevirt/kubevirt-tekton-tasks/modules/sharedtest/testconstants"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("SerialConsoleAttributes", func() {
+	var testSecretPath string
+
+	BeforeEach(func() {
+		testSecretPath = path.Join(testPath, TestRandomName("serial-console-attr-secret"))
+		err := os.MkdirAll(testSecretPath, testDirMode)
+		Expect(err).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		err := os.RemoveAll(testSecretPath)
+		Expect(err).Should(Succeed())
+	})
+
+	DescribeTable("Init fails", func(expectedErrMessage string, secretSetup map[string]string) {
+		secretSetup["type"] = "serial-console"
+
+		PrepareTestSecret(testSecretPath, secretSetup)
+		attributes := execattributes.NewExecAttributes()
+
+		err := attributes.Init(testSecretPath)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(expectedErrMessage))
+		log.Logger().Debug(CurrentSpecReport().FullText(), zap.Object("execAttributes", attributes)) // test MarshalLogObject
+	},
+		Entry("user missing", "user secret attribute is required", map[string]string{
+			"password": "fedora",
+		}),
+		Entry("password missing", "password secret attribute is required", map[string]string{
+			"user": "fedora",
+		}),
+		Entry("empty password", "password secret attribute is required", map[string]string{
+			"user":     "fedora",
+			"password": "\n",
+		}),
+		Entry("invalid prompt", "prompt is not a valid regular expression", map[string]string{
+			"user":     "fedora",
+			"password": "fedora",
+			"prompt":   "[$",
+		}),
+	)
+
+	DescribeTable("test various serialConsoleAttributes", func(secretSetup map[string]string, expectedUser, expectedPassword, expectedPrompt string) {
+		PrepareTestSecret(testSecretPath, secretSetup)
+		attributes := execattributes.NewExecAttributes()
+
+		err := attributes.Init(testSecretPath)
+		Expect(err).Should(Succeed())
+		Expect(attributes.GetType()).To(Equal(constants.SerialConsoleSecretType))
+		Expect(attributes.GetSSHAttributes()).To(BeNil())
+
+		serialConsoleAttributes := attributes.GetSerialConsoleAttributes()
+		Expect(serialConsoleAttributes.GetUser()).To(Equal(expectedUser))
+		Expect(serialConsoleAttributes.GetPassword()).To(Equal(expectedPassword))
+		Expect(serialConsoleAttributes.GetPrompt().String()).To(Equal(expectedPrompt))
+
+		log.Logger().Info(CurrentSpecReport().FullText(), zap.Object("execAttributes", attributes)) // test MarshalLogObject
+	},
+		Entry("minimal setup", map[string]string{
+			"type":     "serial-console",
+			"user":     "fedora",
+			"password": "fedora\n",
+		}, "fedora", "fedora", `[$#>]\s*$`),
+		Entry("custom prompt", map[string]string{
+			"type":     "serial-console",
+			"user":     " root\n",
+			"password": " secret ",
+			"prompt":   `\[root@vm ~\]#\s*$` + "\n",
+		}, "root", " secret ", `\[root@vm ~\]#\s*$`),
+	)
+})
@@ -2,6 +2,7 @@ package execattributes
 
 import (
 	"fmt"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/constants"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/env/fileoptions"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/options"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zconstants/connectionsecret"
@@ -9,11 +10,15 @@ import (
 	"go.uber.org/zap/zapcore"
 	"os/user"
 	"path"
+	"strconv"
 	"strings"
+	"time"
 )
 
 const (
 	sshStrictHostKeyCheckingOption = "StrictHostKeyChecking"
+	sshServerAliveIntervalOption   = "ServerAliveInterval"
+	sshServerAliveCountMaxOption   = "ServerAliveCountMax"
 )
 
 const (
@@ -27,7 +32,7 @@ const (
 	yes       = "yes"
 )
 
-// port is parsed from additionalSSHOptions to be more easier to use
+// port and options used by the native ssh client are parsed from additionalSSHOptions to be more easier to use
 type sshAttributes struct {
 	user                         string
 	port                         int
@@ -35,6 +40,10 @@ type sshAttributes struct {
 	privateKey                   string
 	hostPublicKey                string
 	disableStrictHostKeyChecking bool
+	sshClient                    constants.SSHClientType
+	hostKeyCheckingMode          string
+	serverAliveInterval          time.Duration
+	serverAliveCountMax          int
 }
 
 type SSHAttributes interface {
@@ -48,6 +57,10 @@ type SSHAttributes interface {
 	GetStrictHostKeyCheckingMode() string
 	GetSSHDir() string
 	GetSSHExecutableName() string
+	GetSSHClient() constants.SSHClientType
+	GetHostKeyCheckingMode() string
+	GetServerAliveInterval() time.Duration
+	GetServerAliveCountMax() int
 }
 
 func NewSSHAttributes() SSHAttributes {
@@ -55,7 +68,7 @@ func NewSSHAttributes() SSHAttributes {
 }
 
 func (s *sshAttributes) initSSH(execSecretPath string) error {
-	var privateKeyAlternativeFormat, additionalSSHOptionsString string
+	var privateKeyAlternativeFormat, additionalSSHOptionsString, sshClient string
 
 	stringOptions := map[string]*string{
 		connectionsecret.SSHConnectionSecretKeys.User:                        &s.user,
@@ -63,6 +76,7 @@ func (s *sshAttributes) initSSH(execSecretPath string) error {
 		connectionsecret.SSHConnectionSecretKeys.PrivateKey:                  &s.privateKey,
 		connectionsecret.SSHConnectionSecretKeys.PrivateKeyAlternativeFormat: &privateKeyAlternativeFormat,
 		connectionsecret.SSHConnectionSecretKeys.HostPublicKey:               &s.hostPublicKey,
+		connectionsecret.SSHConnectionSecretKeys.SSHClient:                   &sshClient,
 	}
 	boolOptions := map[string]*bool{
 		connectionsecret.SSHConnectionSecretKeys.DisableStrictHostKeyChecking: &s.disableStrictHostKeyChecking,
@@ -106,6 +120,10 @@ func (s *sshAttributes) initSSH(execSecretPath string) error {
 	}
 	s.port = port
 
+	if err := s.initNativeOptions(additionalSSHOptions, strings.TrimSpace(sshClient)); err != nil {
+		return err
+	}
+
 	if !strings.HasSuffix(s.privateKey, "\n") {
 		s.privateKey += "\n"
 	}
@@ -119,6 +137,60 @@ func (s *sshAttributes) initSSH(execSecretPath string) error {
 	return nil
 }
 
+// initNativeOptions resolves options of the native ssh client. The native client is used only when requested
+// and fails on additional ssh options it does not support, the ssh binary is used otherwise.
+func (s *sshAttributes) initNativeOptions(additionalSSHOptions *options.CommandOptions, sshClient string) error {
+	configOptions, unsupported := parseConfigOptions(additionalSSHOptions)
+
+	switch constants.SSHClientType(sshClient) {
+	case constants.NativeSSHClient:
+		if len(unsupported) > 0 {
+			return zerrors.NewMissingRequiredError("%v %v does not support additional ssh options %v: only -p, %v, %v and %v are allowed, use %v %v for other options",
+				constants.NativeSSHClient, connectionsecret.SSHConnectionSecretKeys.SSHClient, strings.Join(unsupported, ", "),
+				sshStrictHostKeyCheckingOption, sshServerAliveIntervalOption, sshServerAliveCountMaxOption, constants.BinarySSHClient, connectionsecret.SSHConnectionSecretKeys.SSHClient)
+		}
+		s.sshClient = constants.NativeSSHClient
+	case constants.BinarySSHClient, "":
+		s.sshClient = constants.BinarySSHClient
+	default:
+		return zerrors.NewMissingRequiredError("%v is invalid %v: only %v|%v is allowed", sshClient, connectionsecret.SSHConnectionSecretKeys.SSHClient,
+			constants.NativeSSHClient, constants.BinarySSHClient)
+	}
+
+	if mode, ok := configOptions[strings.ToLower(sshStrictHostKeyCheckingOption)]; ok {
+		switch mode {
+		case yes, no, acceptNew:
+			s.hostKeyCheckingMode = mode
+		default:
+			return zerrors.NewMissingRequiredError("unsupported %v option value '%v': only %v|%v|%v is allowed", sshStrictHostKeyCheckingOption, mode, yes, acceptNew, no)
+		}
+	} else if s.disableStrictHostKeyChecking {
+		s.hostKeyCheckingMode = acceptNew
+	} else {
+		s.hostKeyCheckingMode = yes
+	}
+
+	s.serverAliveInterval = constants.SSHKeepAliveInterval
+	if value, ok := configOptions[strings.ToLower(sshServerAliveIntervalOption)]; ok {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds < 0 {
+			return zerrors.NewMissingRequiredError("Bad %v '%v'", sshServerAliveIntervalOption, value)
+		}
+		s.serverAliveInterval = time.Duration(seconds) * time.Second
+	}
+
+	s.serverAliveCountMax = constants.SSHKeepAliveCountMax
+	if value, ok := configOptions[strings.ToLower(sshServerAliveCountMaxOption)]; ok {
+		count, err := strconv.Atoi(value)
+		if err != nil || count < 0 {
+			return zerrors.NewMissingRequiredError("Bad %v '%v'", sshServerAliveCountMaxOption, value)
+		}
+		s.serverAliveCountMax = count
+	}
+
+	return nil
+}
+
 func (s *sshAttributes) GetUser() string {
 	return s.user
 }
@@ -131,6 +203,7 @@ func (s *sshAttributes) GetAdditionalSSHOptions() []string {
 	return s.additionalSSHOptions
 }
 
+// GetStrictHostKeyCheckingMode returns the mode passed to the ssh binary
 func (s *sshAttributes) GetStrictHostKeyCheckingMode() string {
 	if s.disableStrictHostKeyChecking {
 		// TODO change to safer acceptNew once a newer version of ssh which supports this option is available in CI
@@ -139,6 +212,11 @@ func (s *sshAttributes) GetStrictHostKeyCheckingMode() string {
 	return yes
 }
 
+// GetHostKeyCheckingMode returns the mode used by the native ssh client, which supports accept-new
+func (s *sshAttributes) GetHostKeyCheckingMode() string {
+	return s.hostKeyCheckingMode
+}
+
 func (s *sshAttributes) GetPrivateKey() string {
 	return s.privateKey
 }
@@ -161,10 +239,25 @@ func (s *sshAttributes) GetSSHExecutableName() string {
 	return sshExecutableName
 }
 
+func (s *sshAttributes) GetSSHClient() constants.SSHClientType {
+	return s.sshClient
+}
+
+// GetServerAliveInterval returns the interval of keepalives sent by the native ssh client, zero disables keepalives
+func (s *sshAttributes) GetServerAliveInterval() time.Duration {
+	return s.serverAliveInterval
+}
+
+// GetServerAliveCountMax returns the number of unanswered keepalives after which the native ssh client disconnects
+func (s *sshAttributes) GetServerAliveCountMax() int {
+	return s.serverAliveCountMax
+}
+
 func (s *sshAttributes) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
 	// do not print private/public key
 	encoder.AddString("user", s.user)
 	encoder.AddString("additionalSSHOptions", strings.Join(s.additionalSSHOptions, " "))
 	encoder.AddBool("disableStrictHostKeyChecking", s.disableStrictHostKeyChecking)
+	encoder.AddString("sshClient", string(s.sshClient))
 	return nil
 }
@@ -5,7 +5,9 @@ import (
 	"os/user"
 	"path"
 	"reflect"
+	"time"
 
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/constants"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/execattributes"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/utils/log"
 	. "github.com/kubevirt/kubevirt-tekton-tasks/modules/sharedtest/testconstants"
@@ -59,6 +61,44 @@ var _ = Describe("SSHAttributes", func() {
 			"host-public-key":        SSHTestPublicKey,
 			"additional-ssh-options": "-C -p 22.0",
 		}),
+		Entry("invalid ssh client", "ssh is invalid ssh-client: only native|binary is allowed", map[string]string{
+			"user":            "fedora",
+			"ssh-privatekey":  SSHTestPrivateKey,
+			"host-public-key": SSHTestPublicKey,
+			"ssh-client":      "ssh",
+		}),
+		Entry("unsupported options of the native ssh client", "native ssh-client does not support additional ssh options -i, -J, UserKnownHostsFile, HostKeyAlgorithms: only -p, StrictHostKeyChecking, ServerAliveInterval and ServerAliveCountMax are allowed, use binary ssh-client for other options", map[string]string{
+			"user":                   "fedora",
+			"ssh-privatekey":         SSHTestPrivateKey,
+			"host-public-key":        SSHTestPublicKey,
+			"additional-ssh-options": "-p 8022 -i /keys/id_ed25519 -J bastion -o UserKnownHostsFile=/dev/null -oHostKeyAlgorithms=ssh-ed25519 -o ServerAliveInterval=5",
+			"ssh-client":             "native",
+		}),
+		Entry("unsupported flag of the native ssh client", "native ssh-client does not support additional ssh options -C", map[string]string{
+			"user":                   "fedora",
+			"ssh-privatekey":         SSHTestPrivateKey,
+			"host-public-key":        SSHTestPublicKey,
+			"additional-ssh-options": "-C",
+			"ssh-client":             "native",
+		}),
+		Entry("invalid StrictHostKeyChecking", "unsupported StrictHostKeyChecking option value 'ask': only yes|accept-new|no is allowed", map[string]string{
+			"user":                   "fedora",
+			"ssh-privatekey":         SSHTestPrivateKey,
+			"host-public-key":        SSHTestPublicKey,
+			"additional-ssh-options": "-o StrictHostKeyChecking=ask",
+		}),
+		Entry("bad ServerAliveInterval", "Bad ServerAliveInterval '5s'", map[string]string{
+			"user":                   "fedora",
+			"ssh-privatekey":         SSHTestPrivateKey,
+			"host-public-key":        SSHTestPublicKey,
+			"additional-ssh-options": "-o ServerAliveInterval=5s",
+		}),
+		Entry("bad ServerAliveCountMax", "Bad ServerAliveCountMax '-1'", map[string]string{
+			"user":                   "fedora",
+			"ssh-privatekey":         SSHTestPrivateKey,
+			"host-public-key":        SSHTestPublicKey,
+			"additional-ssh-options": "-o ServerAliveCountMax=-1",
+		}),
 	)
 
 	DescribeTable("test various sshAttributes", func(secretSetup map[string]string, expectedAttributes map[string]interface{}) {
@@ -90,6 +130,10 @@ var _ = Describe("SSHAttributes", func() {
 			"GetPrivateKey":                SSHTestPrivateKey,
 			"GetHostPublicKey":             SSHTestPublicKey,
 			"GetStrictHostKeyCheckingMode": "yes",
+			"GetHostKeyCheckingMode":       "yes",
+			"GetSSHClient":                 constants.BinarySSHClient,
+			"GetServerAliveInterval":       15 * time.Second,
+			"GetServerAliveCountMax":       3,
 		}),
 		Entry("minimal setup with alternative private key format", map[string]string{
 			"user":            "fedora",
@@ -118,6 +162,8 @@ var _ = Describe("SSHAttributes", func() {
 			"GetHostPublicKey":        "",
 			// TODO same here
 			"GetStrictHostKeyCheckingMode": "no",
+			"GetHostKeyCheckingMode":       "accept-new",
+			"GetSSHClient":                 constants.BinarySSHClient,
 		}),
 		Entry("invalid disable-strict-host-key-checking value", map[string]string{
 			"type":                             "ssh",
@@ -151,6 +197,40 @@ var _ = Describe("SSHAttributes", func() {
 			"GetPort":                 8022,
 			"GetAdditionalSSHOptions": []string{"-C", "-p8022", "-l=test", "-o", "StrictHostKeyChecking=yes"},
 		}),
+		Entry("native ssh client options", map[string]string{
+			"user":                   "fedora",
+			"ssh-privatekey":         SSHTestPrivateKey,
+			"host-public-key":        SSHTestPublicKey,
+			"additional-ssh-options": "-p 8022 -o StrictHostKeyChecking=accept-new -oServerAliveInterval=5 -o 'ServerAliveCountMax 10'",
+			"ssh-client":             "native",
+		}, map[string]interface{}{
+			"GetPort":                      8022,
+			"GetAdditionalSSHOptions":      []string{"-p", "8022", "-o", "StrictHostKeyChecking=accept-new", "-oServerAliveInterval=5", "-o", "ServerAliveCountMax 10"},
+			"GetStrictHostKeyCheckingMode": "yes",
+			"GetHostKeyCheckingMode":       "accept-new",
+			"GetSSHClient":                 constants.NativeSSHClient,
+			"GetServerAliveInterval":       5 * time.Second,
+			"GetServerAliveCountMax":       10,
+		}),
+		Entry("ssh binary is forced", map[string]string{
+			"user":                   "fedora",
+			"ssh-privatekey":         SSHTestPrivateKey,
+			"host-public-key":        SSHTestPublicKey,
+			"additional-ssh-options": "-o StrictHostKeyChecking=no",
+			"ssh-client":             "binary",
+		}, map[string]interface{}{
+			"GetAdditionalSSHOptions": []string{"-o", "StrictHostKeyChecking=no"},
+			"GetHostKeyCheckingMode":  "no",
+			"GetSSHClient":            constants.BinarySSHClient,
+		}),
+		Entry("ssh binary is the default with options unsupported by the native ssh client", map[string]string{
+			"user":                   "fedora",
+			"ssh-privatekey":         SSHTestPrivateKey,
+			"host-public-key":        SSHTestPublicKey,
+			"additional-ssh-options": "-i /keys/id_ed25519 -o UserKnownHostsFile=/dev/null",
+		}, map[string]interface{}{
+			"GetSSHClient": constants.BinarySSHClient,
+		}),
 	)
 
 	It("does common operations correctly", func() {
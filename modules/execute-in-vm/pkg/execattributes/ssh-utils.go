@@ -4,12 +4,20 @@ import (
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/options"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zerrors"
 	"strconv"
+	"strings"
 )
 
 const (
 	defaultSSHPort = 22
 )
 
+// ssh config options understood by the native ssh client, in lowercase
+var nativeConfigOptions = map[string]bool{
+	"stricthostkeychecking": true,
+	"serveraliveinterval":   true,
+	"serveralivecountmax":   true,
+}
+
 func parsePort(sshOptions *options.CommandOptions) (int, error) {
 	if !sshOptions.IncludesOption("-p") {
 		return defaultSSHPort, nil
@@ -27,3 +35,56 @@ func parsePort(sshOptions *options.CommandOptions) (int, error) {
 	}
 	return port, nil
 }
+
+// parseConfigOptions returns values of -o options by lowercase keys.
+// It also returns options which are not supported by the native ssh client.
+func parseConfigOptions(sshOptions *options.CommandOptions) (map[string]string, []string) {
+	configOptions := map[string]string{}
+	var unsupported []string
+
+	opts := sshOptions.GetAll()
+	for i := 0; i < len(opts); i++ {
+		var configOption string
+		switch opt := opts[i]; {
+		case opt == "-o":
+			if i+1 < len(opts) {
+				i++
+				configOption = opts[i]
+			}
+		case strings.HasPrefix(opt, "-o"):
+			configOption = opt[2:]
+		case opt == "-p":
+			i++
+			continue
+		case strings.HasPrefix(opt, "-p"):
+			continue
+		case strings.HasPrefix(opt, "-"):
+			unsupported = append(unsupported, opt)
+			continue
+		default:
+			// argument of an unsupported option
+			continue
+		}
+
+		key, value := splitConfigOption(configOption)
+		if !nativeConfigOptions[strings.ToLower(key)] {
+			unsupported = append(unsupported, key)
+		}
+		configOptions[strings.ToLower(key)] = value
+	}
+
+	return configOptions, unsupported
+}
+
+// splitConfigOption splits an ssh config option in a Key=Value or Key Value format
+func splitConfigOption(configOption string) (string, string) {
+	if keyValue := strings.SplitN(configOption, "=", 2); len(keyValue) == 2 {
+		return strings.TrimSpace(keyValue[0]), strings.TrimSpace(keyValue[1])
+	}
+
+	if keyValue := strings.Fields(configOption); len(keyValue) == 2 {
+		return keyValue[0], keyValue[1]
+	}
+
+	return strings.TrimSpace(configOption), ""
+}
@@ -0,0 +1,202 @@
+package execattributes
+
+import (
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/constants"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/env/fileoptions"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zconstants/connectionsecret"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zerrors"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	httpProtocol  = "http"
+	httpsProtocol = "https"
+)
+
+// the user authenticates with a password (NTLM or basic auth) or with a client certificate over https
+type winRMAttributes struct {
+	user               string
+	password           string
+	clientCert         string
+	clientKey          string
+	caCert             string
+	https              bool
+	port               int
+	insecureSkipVerify bool
+	tlsServerName      string
+	shell              constants.WinRMShellType
+}
+
+type WinRMAttributes interface {
+	zapcore.ObjectMarshaler
+	initWinRM(execSecretPath string) error
+	GetUser() string
+	GetPassword() string
+	GetClientCert() string
+	GetClientKey() string
+	GetCACert() string
+	UseHTTPS() bool
+	GetPort() int
+	IsInsecureSkipVerify() bool
+	GetTLSServerName() string
+	GetShell() constants.WinRMShellType
+	UseCertificateAuth() bool
+}
+
+func NewWinRMAttributes() WinRMAttributes {
+	return &winRMAttributes{}
+}
+
+func (w *winRMAttributes) initWinRM(execSecretPath string) error {
+	var protocol, port, shell string
+
+	stringOptions := map[string]*string{
+		connectionsecret.WinRMConnectionSecretKeys.User:          &w.user,
+		connectionsecret.WinRMConnectionSecretKeys.Password:      &w.password,
+		connectionsecret.WinRMConnectionSecretKeys.ClientCert:    &w.clientCert,
+		connectionsecret.WinRMConnectionSecretKeys.ClientKey:     &w.clientKey,
+		connectionsecret.WinRMConnectionSecretKeys.CACert:        &w.caCert,
+		connectionsecret.WinRMConnectionSecretKeys.TLSServerName: &w.tlsServerName,
+		connectionsecret.WinRMConnectionSecretKeys.Protocol:      &protocol,
+		connectionsecret.WinRMConnectionSecretKeys.Port:          &port,
+		connectionsecret.WinRMConnectionSecretKeys.Shell:         &shell,
+	}
+	boolOptions := map[string]*bool{
+		connectionsecret.WinRMConnectionSecretKeys.InsecureSkipVerify: &w.insecureSkipVerify,
+	}
+
+	for optionName, output := range stringOptions {
+		if err := fileoptions.ReadFileOption(output, path.Join(execSecretPath, optionName)); err != nil {
+			return err
+		}
+	}
+
+	for optionName, output := range boolOptions {
+		if err := fileoptions.ReadFileOptionBool(output, path.Join(execSecretPath, optionName)); err != nil {
+			return err
+		}
+	}
+
+	w.user = strings.TrimSpace(w.user)
+	w.tlsServerName = strings.TrimSpace(w.tlsServerName)
+	w.password = strings.TrimRight(w.password, "\r\n")
+
+	if strings.TrimSpace(w.clientCert) != "" || strings.TrimSpace(w.clientKey) != "" {
+		if strings.TrimSpace(w.clientCert) == "" || strings.TrimSpace(w.clientKey) == "" {
+			return zerrors.NewMissingRequiredError("%v and %v secret attributes are required for certificate authentication",
+				connectionsecret.WinRMConnectionSecretKeys.ClientCert, connectionsecret.WinRMConnectionSecretKeys.ClientKey)
+		}
+	} else {
+		if w.user == "" {
+			return zerrors.NewMissingRequiredError("%v secret attribute is required", connectionsecret.WinRMConnectionSecretKeys.User)
+		}
+		if w.password == "" {
+			return zerrors.NewMissingRequiredError("%v or %v secret attribute is required", connectionsecret.WinRMConnectionSecretKeys.Password,
+				connectionsecret.WinRMConnectionSecretKeys.ClientCert)
+		}
+	}
+
+	switch protocol = strings.TrimSpace(protocol); protocol {
+	case "":
+		// client certificates are sent only over https
+		w.https = w.UseCertificateAuth()
+	case httpProtocol, httpsProtocol:
+		w.https = protocol == httpsProtocol
+	default:
+		return zerrors.NewMissingRequiredError("%v is invalid %v: only %v|%v is allowed", protocol, connectionsecret.WinRMConnectionSecretKeys.Protocol,
+			httpProtocol, httpsProtocol)
+	}
+
+	if w.UseCertificateAuth() && !w.https {
+		return zerrors.NewMissingRequiredError("certificate authentication requires %v %v", httpsProtocol, connectionsecret.WinRMConnectionSecretKeys.Protocol)
+	}
+
+	if port = strings.TrimSpace(port); port == "" {
+		if w.https {
+			w.port = constants.WinRMHTTPSPort
+		} else {
+			w.port = constants.WinRMHTTPPort
+		}
+	} else {
+		parsedPort, err := strconv.Atoi(port)
+		if err != nil || parsedPort <= 0 {
+			return zerrors.NewMissingRequiredError("Bad %v '%v'", connectionsecret.WinRMConnectionSecretKeys.Port, port)
+		}
+		w.port = parsedPort
+	}
+
+	switch shellType := constants.WinRMShellType(strings.TrimSpace(shell)); shellType {
+	case "":
+		w.shell = constants.PowerShellWinRMShell
+	case constants.PowerShellWinRMShell, constants.CmdWinRMShell:
+		w.shell = shellType
+	default:
+		return zerrors.NewMissingRequiredError("%v is invalid %v: only %v|%v is allowed", shellType, connectionsecret.WinRMConnectionSecretKeys.Shell,
+			constants.PowerShellWinRMShell, constants.CmdWinRMShell)
+	}
+
+	return nil
+}
+
+func (w *winRMAttributes) GetUser() string {
+	return w.user
+}
+
+func (w *winRMAttributes) GetPassword() string {
+	return w.password
+}
+
+func (w *winRMAttributes) GetClientCert() string {
+	return w.clientCert
+}
+
+func (w *winRMAttributes) GetClientKey() string {
+	return w.clientKey
+}
+
+func (w *winRMAttributes) GetCACert() string {
+	return w.caCert
+}
+
+func (w *winRMAttributes) UseHTTPS() bool {
+	return w.https
+}
+
+func (w *winRMAttributes) GetPort() int {
+	return w.port
+}
+
+func (w *winRMAttributes) IsInsecureSkipVerify() bool {
+	return w.insecureSkipVerify
+}
+
+// GetTLSServerName returns the name to verify the certificate of the WinRM service against instead of the host
+func (w *winRMAttributes) GetTLSServerName() string {
+	return w.tlsServerName
+}
+
+// GetShell returns the shell which interprets the script
+func (w *winRMAttributes) GetShell() constants.WinRMShellType {
+	return w.shell
+}
+
+// UseCertificateAuth reports whether the client certificate is used instead of the password
+func (w *winRMAttributes) UseCertificateAuth() bool {
+	return strings.TrimSpace(w.clientCert) != ""
+}
+
+func (w *winRMAttributes) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	// do not print password and keys
+	encoder.AddString("user", w.user)
+	encoder.AddBool("https", w.https)
+	encoder.AddInt("port", w.port)
+	encoder.AddBool("insecureSkipVerify", w.insecureSkipVerify)
+	encoder.AddString("tlsServerName", w.tlsServerName)
+	encoder.AddBool("certificateAuth", w.UseCertificateAuth())
+	encoder.AddString("shell", string(w.shell))
+	return nil
+}
@@ -0,0 +1,134 @@
+package execattributes_test
+
+import (
+	"os"
+	"path"
+	"strings"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/constants"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/execattributes"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/utils/log"
+	. "github.com/kubevirt/kubevirt-tekton-tasks/modules/sharedtest/testconstants"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("WinRMAttributes", func() {
+	var testSecretPath string
+
+	BeforeEach(func() {
+		testSecretPath = path.Join(testPath, TestRandomName("winrm-attr-secret"))
+		err := os.MkdirAll(testSecretPath, testDirMode)
+		Expect(err).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		err := os.RemoveAll(testSecretPath)
+		Expect(err).Should(Succeed())
+	})
+
+	DescribeTable("Init fails", func(expectedErrMessage string, secretSetup map[string]string) {
+		secretSetup["type"] = "winrm"
+
+		PrepareTestSecret(testSecretPath, secretSetup)
+		attributes := execattributes.NewExecAttributes()
+
+		err := attributes.Init(testSecretPath)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(expectedErrMessage))
+		log.Logger().Debug(CurrentSpecReport().FullText(), zap.Object("execAttributes", attributes)) // test MarshalLogObject
+	},
+		Entry("user missing", "user secret attribute is required", map[string]string{
+			"password": "Passw0rd!",
+		}),
+		Entry("password missing", "password or tls.crt secret attribute is required", map[string]string{
+			"user": "Administrator",
+		}),
+		Entry("client key missing", "tls.crt and tls.key secret attributes are required for certificate authentication", map[string]string{
+			"tls.crt": "cert",
+		}),
+		Entry("client cert missing", "tls.crt and tls.key secret attributes are required for certificate authentication", map[string]string{
+			"tls.key": "key",
+		}),
+		Entry("certificate over http", "certificate authentication requires https protocol", map[string]string{
+			"tls.crt":  "cert",
+			"tls.key":  "key",
+			"protocol": "http",
+		}),
+		Entry("invalid protocol", "ftp is invalid protocol: only http|https is allowed", map[string]string{
+			"user":     "Administrator",
+			"password": "Passw0rd!",
+			"protocol": "ftp",
+		}),
+		Entry("invalid port", "Bad port 'winrm'", map[string]string{
+			"user":     "Administrator",
+			"password": "Passw0rd!",
+			"port":     "winrm",
+		}),
+		Entry("negative port", "Bad port '-1'", map[string]string{
+			"user":     "Administrator",
+			"password": "Passw0rd!",
+			"port":     "-1",
+		}),
+		Entry("invalid shell", "bash is invalid shell: only powershell|cmd is allowed", map[string]string{
+			"user":     "Administrator",
+			"password": "Passw0rd!",
+			"shell":    "bash",
+		}),
+	)
+
+	DescribeTable("test various winRMAttributes", func(secretSetup map[string]string, expectedUser, expectedPassword string, expectedHTTPS bool,
+		expectedPort int, expectedInsecure, expectedCertificateAuth bool, expectedShell constants.WinRMShellType) {
+		secretSetup["type"] = "winrm"
+
+		PrepareTestSecret(testSecretPath, secretSetup)
+		attributes := execattributes.NewExecAttributes()
+
+		err := attributes.Init(testSecretPath)
+		Expect(err).Should(Succeed())
+		Expect(attributes.GetType()).To(Equal(constants.WinRMSecretType))
+		Expect(attributes.GetSSHAttributes()).To(BeNil())
+		Expect(attributes.GetSerialConsoleAttributes()).To(BeNil())
+
+		winRMAttributes := attributes.GetWinRMAttributes()
+		Expect(winRMAttributes.GetUser()).To(Equal(expectedUser))
+		Expect(winRMAttributes.GetPassword()).To(Equal(expectedPassword))
+		Expect(winRMAttributes.UseHTTPS()).To(Equal(expectedHTTPS))
+		Expect(winRMAttributes.GetPort()).To(Equal(expectedPort))
+		Expect(winRMAttributes.IsInsecureSkipVerify()).To(Equal(expectedInsecure))
+		Expect(winRMAttributes.UseCertificateAuth()).To(Equal(expectedCertificateAuth))
+		Expect(winRMAttributes.GetShell()).To(Equal(expectedShell))
+		Expect(winRMAttributes.GetClientCert()).To(Equal(secretSetup["tls.crt"]))
+		Expect(winRMAttributes.GetClientKey()).To(Equal(secretSetup["tls.key"]))
+		Expect(winRMAttributes.GetCACert()).To(Equal(secretSetup["ca.crt"]))
+		Expect(winRMAttributes.GetTLSServerName()).To(Equal(strings.TrimSpace(secretSetup["tls-server-name"])))
+
+		log.Logger().Info(CurrentSpecReport().FullText(), zap.Object("execAttributes", attributes)) // test MarshalLogObject
+	},
+		Entry("minimal setup", map[string]string{
+			"user":     "Administrator",
+			"password": "Passw0rd!\n",
+		}, "Administrator", "Passw0rd!", false, 5985, false, false, constants.PowerShellWinRMShell),
+		Entry("https", map[string]string{
+			"user":                 " Administrator\n",
+			"password":             " Passw0rd! ",
+			"protocol":             "https\n",
+			"insecure-skip-verify": "true",
+			"ca.crt":               "ca",
+			"tls-server-name":      "windows.example.com\n",
+			"shell":                "cmd",
+		}, "Administrator", " Passw0rd! ", true, 5986, true, false, constants.CmdWinRMShell),
+		Entry("custom port", map[string]string{
+			"user":     "Administrator",
+			"password": "Passw0rd!",
+			"port":     "15985",
+			"shell":    "powershell",
+		}, "Administrator", "Passw0rd!", false, 15985, false, false, constants.PowerShellWinRMShell),
+		Entry("certificate authentication", map[string]string{
+			"user":    "Administrator",
+			"tls.crt": "cert",
+			"tls.key": "key",
+		}, "Administrator", "", true, 5986, false, true, constants.PowerShellWinRMShell),
+	)
+})
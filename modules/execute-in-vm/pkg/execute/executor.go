@@ -3,13 +3,21 @@ package execute
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"time"
 
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/constants"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/execattributes"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/portforward"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/scriptoutput"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/transfer"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/utils"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/utils/log"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/utils/parse"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/vmi"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/exit"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/results"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zerrors"
 	"go.uber.org/zap"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -24,16 +32,50 @@ type Executor struct {
 	clioptions     *parse.CLIOptions
 	kubevirtClient kubecli.KubevirtClient
 	executor       RemoteExecutor
+	copier         *transfer.Copier
+	recorder       *scriptoutput.Recorder
+	// exitCode of the script is set once the script finishes or times out
+	exitCode *int
+	// stdout and stderr receive the output of the script
+	stdout io.Writer
+	stderr io.Writer
+	// recordResults is false when the results of several VMs would overwrite each other
+	recordResults bool
 
 	attemptedStart  bool
 	attemptedStop   bool
 	attemptedDelete bool
 	ipAddress       string
+	// requiresIPAddress is false when the VM is not reached over the pod network
+	requiresIPAddress bool
 }
 
 func NewExecutor(clioptions *parse.CLIOptions, connectionSecretPath string) (*Executor, error) {
+	kubevirtClient, err := newKubevirtClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return newExecutor(clioptions, connectionSecretPath, kubevirtClient)
+}
+
+func newKubevirtClient() (kubecli.KubevirtClient, error) {
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	kubevirtClient, err := kubecli.GetKubevirtClientFromRESTConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", "cannot create kubevirt client", err.Error())
+	}
+	return kubevirtClient, nil
+}
+
+func newExecutor(clioptions *parse.CLIOptions, connectionSecretPath string, kubevirtClient kubecli.KubevirtClient) (*Executor, error) {
 	var executor RemoteExecutor
 
+	requiresIPAddress := true
 	executor = newSSHExecutor(clioptions, execattributes.NewExecAttributes())
 	if clioptions.GetScript() != "" {
 		execAttributes := execattributes.NewExecAttributes()
@@ -43,25 +85,129 @@ func NewExecutor(clioptions *parse.CLIOptions, connectionSecretPath string) (*Ex
 		}
 		log.Logger().Debug("retrieved connection secret exec attributes", zap.Object("execAttributes", execAttributes))
 
+		if err := clioptions.ValidateExecSecretType(execAttributes.GetType()); err != nil {
+			return nil, err
+		}
+
 		switch execAttributes.GetType() {
 		case constants.SSHSecretType:
-			executor = newSSHExecutor(clioptions, execAttributes)
+			if execAttributes.GetSSHAttributes().GetSSHClient() == constants.NativeSSHClient {
+				executor = newNativeSSHExecutor(clioptions, execAttributes)
+			} else {
+				executor = newSSHExecutor(clioptions, execAttributes)
+			}
+		case constants.SerialConsoleSecretType:
+			executor = newSerialConsoleExecutor(clioptions, execAttributes, kubevirtClient)
+			requiresIPAddress = false
+		case constants.WinRMSecretType:
+			executor = newWinRMExecutor(clioptions, execAttributes)
 		default:
 			return nil, fmt.Errorf("invalid secret/execution type %v", execAttributes.GetType())
 		}
 	}
 
-	config, err := rest.InClusterConfig()
-	if err != nil {
-		return nil, err
+	return &Executor{
+		clioptions:        clioptions,
+		kubevirtClient:    kubevirtClient,
+		executor:          executor,
+		stdout:            os.Stdout,
+		stderr:            os.Stderr,
+		recordResults:     true,
+		requiresIPAddress: requiresIPAddress,
+	}, nil
+}
+
+// Run executes the script in the VM and applies the stop and delete policy afterwards.
+// The exit of the script is returned separately from the errors of the other actions.
+func (e *Executor) Run() (*exit.Exit, *zerrors.MultiError) {
+	multiError := zerrors.NewMultiError()
+	var exitError *exit.Exit
+
+	registerError := func(name string, err error) {
+		if err != nil {
+			if exitErr, ok := err.(exit.Exit); ok {
+				exitError = &exitErr
+			} else if err == wait.ErrWaitTimeout {
+				exitError = &exit.Exit{
+					Code: constants.CommandTimeout,
+					Msg:  "command timed out",
+					Soft: true,
+				}
+			} else {
+				multiError.Add(name, err)
+			}
+		}
+
 	}
 
-	kubevirtClient, err := kubecli.GetKubevirtClientFromRESTConfig(config)
-	if err != nil {
-		return nil, fmt.Errorf("%v: %v", "cannot create kubevirt client", err.Error())
+	if e.clioptions.GetScript() != "" {
+		runWithTimeout := utils.WithTimeout(e.clioptions.GetScriptTimeout())
+
+		runWithTimeout(func(timeout time.Duration, finished bool) {
+			if multiError.IsEmpty() && !finished {
+				err := e.EnsureVMRunning(timeout)
+				registerError("EnsureVMRunning", err)
+			}
+		})
+
+		connected := false
+		runWithTimeout(func(timeout time.Duration, finished bool) {
+			if multiError.IsEmpty() && !finished {
+				err := e.SetupConnection(timeout)
+				registerError("SetupConnection", err)
+				connected = err == nil
+			}
+		})
+
+		runWithTimeout(func(timeout time.Duration, finished bool) {
+			if multiError.IsEmpty() && !finished {
+				err := e.UploadFiles()
+				registerError("UploadFiles", err)
+			}
+		})
+
+		runWithTimeout(func(timeout time.Duration, finished bool) {
+			if multiError.IsEmpty() {
+				if !finished {
+					err := e.RemoteExecute(timeout)
+					registerError("RemoteExecute", err)
+				} else {
+					registerError("RemoteExecute", wait.ErrWaitTimeout)
+				}
+
+			}
+		})
+
+		if err := e.RecordResults(); err != nil {
+			multiError.Add("RecordResults", err)
+		}
+
+		// download artifacts even if the script failed
+		if connected {
+			if err := e.DownloadFiles(); err != nil {
+				multiError.Add("DownloadFiles", err)
+			}
+		}
+
+		if err := e.CloseConnection(); err != nil {
+			log.Logger().Debug("could not close the connection", zap.Error(err))
+		}
+
 	}
 
-	return &Executor{clioptions: clioptions, kubevirtClient: kubevirtClient, executor: executor}, nil
+	if e.clioptions.ShouldStop() {
+		if err := e.EnsureVMStopped(); err != nil {
+			multiError.Add("VM Stop", err)
+		}
+	}
+
+	if e.clioptions.ShouldDelete() {
+		if err := e.EnsureVMDeleted(); err != nil {
+			multiError.Add("VM Delete", err)
+		}
+	}
+
+	return exitError, multiError
 }
 
 func (e *Executor) EnsureVMRunning(timeout time.Duration) error {
@@ -99,6 +245,11 @@ func (e *Executor) EnsureVMRunning(timeout time.Duration) error {
 			log.Logger().Debug("waiting for a VMI to recover", logFields...)
 			return false, nil
 		case kubevirtv1.Running:
+			if !e.requiresIPAddress || e.clioptions.GetConnectionMode() == constants.PortForwardConnectionMode {
+				// the VMI is reached through one of its subresources
+				return true, nil
+			}
+
 			ipAddress, ipError := vmi.GetPodIPAddress(vmInstance)
 
 			if ipAddress == "" || ipError != nil {
@@ -203,7 +354,12 @@ func (e *Executor) SetupConnection(timeout time.Duration) error {
 		return fmt.Errorf("executor is missing or was not initialized")
 	}
 
-	if err := e.executor.Init(e.ipAddress); err != nil {
+	var portForward *portforward.Dialer
+	if e.clioptions.GetConnectionMode() == constants.PortForwardConnectionMode {
+		portForward = portforward.NewDialer(e.kubevirtClient, e.clioptions.GetVirtualMachineNamespace(), e.clioptions.VirtualMachineName)
+	}
+
+	if err := e.executor.Init(e.ipAddress, portForward); err != nil {
 		return err
 	}
 
@@ -225,7 +381,90 @@ func (e *Executor) RemoteExecute(timeout time.Duration) error {
 	if e.executor == nil {
 		return fmt.Errorf("executor is missing or was not initialized")
 	}
-	return e.executor.RemoteExecute(timeout)
+
+	recorder, err := scriptoutput.NewRecorder(e.clioptions.GetStdoutPath(), e.clioptions.GetStderrPath(), e.clioptions.GetStdoutTailSize())
+	if err != nil {
+		return fmt.Errorf("could not create output files: %v", err.Error())
+	}
+	e.recorder = recorder
+
+	err = e.executor.RemoteExecute(timeout, recorder.Stdout(e.stdout), recorder.Stderr(e.stderr))
+	if exitErr, ok := err.(exit.Exit); ok {
+		e.exitCode = &exitErr.Code
+	}
+	return err
+}
+
+// RecordResults closes the output files and records the exit code and the output of the script as results
+func (e *Executor) RecordResults() error {
+	if e.recorder == nil {
+		return nil
+	}
+
+	multiError := zerrors.NewMultiError()
+	if err := e.recorder.Close(); err != nil {
+		multiError.Add("output files", err)
+	}
+
+	if e.exitCode != nil && e.recordResults {
+		if err := results.RecordResults(e.recorder.Results(*e.exitCode)); err != nil {
+			multiError.Add("results", err)
+		}
+	}
+
+	if !multiError.IsEmpty() {
+		return multiError
+	}
+	return nil
+}
+
+// UploadFiles copies files from the workspace into the VM
+func (e *Executor) UploadFiles() error {
+	for _, upload := range e.clioptions.GetUploads() {
+		log.Logger().Info("uploading file", zap.String("source", upload.Local), zap.String("destination", upload.Remote))
+		if err := e.getCopier().Upload(upload.Local, upload.Remote); err != nil {
+			return fmt.Errorf("could not upload %v: %v", upload.Local, err.Error())
+		}
+	}
+	return nil
+}
+
+// DownloadFiles copies files from the VM into the workspace. All downloads are attempted even if some of them fail.
+func (e *Executor) DownloadFiles() error {
+	multiError := zerrors.NewMultiError()
+	for _, download := range e.clioptions.GetDownloads() {
+		log.Logger().Info("downloading files", zap.String("pattern", download.Remote), zap.String("destination", download.Local))
+		if err := e.getCopier().Download(download.Remote, download.Local); err != nil {
+			multiError.Add(download.Remote, err)
+		}
+	}
+
+	if !multiError.IsEmpty() {
+		return multiError
+	}
+	return nil
+}
+
+// CloseConnection closes the connection to the VM if it is open
+func (e *Executor) CloseConnection() error {
+	if e.executor == nil {
+		return nil
+	}
+
+	if e.copier != nil {
+		if err := e.copier.Close(); err != nil {
+			log.Logger().Debug("could not close file transfer", zap.Error(err))
+		}
+		e.copier = nil
+	}
+	return e.executor.Close()
+}
+
+func (e *Executor) getCopier() *transfer.Copier {
+	if e.copier == nil {
+		e.copier = transfer.NewCopier(e.executor)
+	}
+	return e.copier
 }
 
 func (e *Executor) ensureVMStarted() error {
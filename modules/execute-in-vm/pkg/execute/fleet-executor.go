@@ -0,0 +1,96 @@
+package execute
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/fanout"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/utils/log"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/utils/parse"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zerrors"
+	"go.uber.org/zap"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubevirt.io/client-go/kubecli"
+)
+
+// FleetExecutor executes the actions in all VMs matching the vm-selector option
+type FleetExecutor struct {
+	clioptions           *parse.CLIOptions
+	connectionSecretPath string
+	kubevirtClient       kubecli.KubevirtClient
+	// outputLock keeps the lines of the VMs together in stdout and stderr
+	outputLock sync.Mutex
+}
+
+func NewFleetExecutor(clioptions *parse.CLIOptions, connectionSecretPath string) (*FleetExecutor, error) {
+	kubevirtClient, err := newKubevirtClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return &FleetExecutor{
+		clioptions:           clioptions,
+		connectionSecretPath: connectionSecretPath,
+		kubevirtClient:       kubevirtClient,
+	}, nil
+}
+
+// ListVirtualMachines returns sorted names of the VMs matching the vm-selector option
+func (f *FleetExecutor) ListVirtualMachines() ([]string, error) {
+	vmNamespace := f.clioptions.GetVirtualMachineNamespace()
+	selector := f.clioptions.GetVirtualMachineSelector()
+
+	vms, err := f.kubevirtClient.VirtualMachine(vmNamespace).List(context.TODO(), &v1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("could not list VMs: %v", err.Error())
+	}
+
+	var vmNames []string
+	for _, vm := range vms.Items {
+		vmNames = append(vmNames, vm.Name)
+	}
+	if len(vmNames) == 0 {
+		return nil, zerrors.NewMissingRequiredError("no VMs match selector %v in namespace %v", selector, vmNamespace)
+	}
+
+	sort.Strings(vmNames)
+	return vmNames, nil
+}
+
+// Run executes the actions in the VMs concurrently. Failures of a VM do not interrupt the other VMs.
+func (f *FleetExecutor) Run(vmNames []string) []fanout.Result {
+	log.Logger().Info("executing in VMs", zap.Strings("names", vmNames), zap.Int("parallelism", f.clioptions.GetParallelism()))
+
+	return fanout.Run(vmNames, f.clioptions.GetParallelism(), f.runInVirtualMachine)
+}
+
+func (f *FleetExecutor) runInVirtualMachine(vmName string) (*int, error) {
+	executor, err := newExecutor(f.clioptions.ForVirtualMachine(vmName), f.connectionSecretPath, f.kubevirtClient)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := fmt.Sprintf("[%v] ", vmName)
+	stdout := fanout.NewPrefixWriter(os.Stdout, &f.outputLock, prefix)
+	stderr := fanout.NewPrefixWriter(os.Stderr, &f.outputLock, prefix)
+	executor.stdout, executor.stderr = stdout, stderr
+	executor.recordResults = false
+
+	exitError, multiError := executor.Run()
+	_ = stdout.Flush()
+	_ = stderr.Flush()
+
+	var exitCode *int
+	if exitError != nil {
+		exitCode = &exitError.Code
+	}
+	log.Logger().Debug("finished executing in a VM", zap.String("name", vmName), zap.Reflect("exitCode", exitCode))
+
+	if !multiError.IsEmpty() {
+		return exitCode, multiError
+	}
+	return exitCode, nil
+}
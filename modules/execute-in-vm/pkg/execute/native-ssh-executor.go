@@ -0,0 +1,206 @@
+package execute
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/constants"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/execattributes"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/portforward"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/sshclient"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/transfer"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/utils/log"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/utils/parse"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/exit"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+)
+
+// nativeSSHExecutor executes the script with a go ssh client, so the ssh binary is not needed
+type nativeSSHExecutor struct {
+	clioptions  *parse.CLIOptions
+	ssh         execattributes.SSHAttributes
+	ipAddress   string
+	portForward *portforward.Dialer
+	client      *ssh.Client
+}
+
+func newNativeSSHExecutor(clioptions *parse.CLIOptions, execAttributes execattributes.ExecAttributes) *nativeSSHExecutor {
+	return &nativeSSHExecutor{clioptions: clioptions, ssh: execAttributes.GetSSHAttributes()}
+}
+
+func (e *nativeSSHExecutor) Init(ipAddress string, portForward *portforward.Dialer) error {
+	e.ipAddress = ipAddress
+	e.portForward = portForward
+	// fail fast on invalid keys
+	if _, err := sshclient.NewSigner(e.ssh.GetPrivateKey()); err != nil {
+		return err
+	}
+	_, _, err := sshclient.NewHostKeyCallback(e.ssh.GetHostKeyCheckingMode(), e.ssh.GetHostPublicKey())
+	return err
+}
+
+func (e *nativeSSHExecutor) TestConnection() bool {
+	return testSSHConnection(e.ipAddress, e.ssh.GetPort(), e.portForward)
+}
+
+func (e *nativeSSHExecutor) RemoteExecute(timeout time.Duration, stdout, stderr io.Writer) error {
+	client, err := e.connect()
+	if err != nil {
+		return err
+	}
+
+	session, err := client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	log.Logger().Debug("executing script with native ssh client", zap.String("user", e.ssh.GetUser()), zap.String("ipAddress", e.ipAddress))
+
+	done := make(chan error, 1)
+	go func() { done <- session.Run(e.clioptions.GetScript()) }()
+
+	var timeoutCh <-chan time.Time
+	if timeout > 0 {
+		timeoutCh = time.After(timeout)
+	}
+
+	select {
+	case <-timeoutCh:
+		// servers can ignore the signal, closing the connection makes sure the command is not waited for anymore
+		_ = session.Signal(ssh.SIGKILL)
+		_ = session.Close()
+		_ = e.Close()
+		return exit.Exit{
+			Code: constants.CommandTimeout,
+			Msg:  "command timed out",
+			Soft: true,
+		}
+	case err := <-done:
+		if err != nil {
+			var exitErr *ssh.ExitError
+			if errors.As(err, &exitErr) {
+				return exit.Exit{
+					Code: exitErr.ExitStatus(),
+					Soft: true,
+				}
+			}
+			var exitMissingErr *ssh.ExitMissingError
+			if errors.As(err, &exitMissingErr) {
+				return errors.New("remote command exited without exit status: connection to the VM was lost")
+			}
+			return err
+		}
+	}
+
+	return exit.Exit{
+		Code: 0,
+		Soft: true,
+	}
+}
+
+// connect reuses the connection to the VM if it was already established
+func (e *nativeSSHExecutor) connect() (*ssh.Client, error) {
+	if e.client != nil {
+		return e.client, nil
+	}
+
+	config := &sshclient.Config{
+		User:                e.ssh.GetUser(),
+		PrivateKey:          e.ssh.GetPrivateKey(),
+		HostPublicKey:       e.ssh.GetHostPublicKey(),
+		HostKeyCheckingMode: e.ssh.GetHostKeyCheckingMode(),
+		HandshakeTimeout:    constants.SSHHandshakeTimeout,
+		KeepAliveInterval:   e.ssh.GetServerAliveInterval(),
+		KeepAliveCountMax:   e.ssh.GetServerAliveCountMax(),
+	}
+
+	var client *ssh.Client
+	if e.portForward != nil {
+		conn, err := e.portForward.Dial(e.ssh.GetPort())
+		if err != nil {
+			return nil, err
+		}
+		client, err = sshclient.NewClient(conn, e.portForward.Address(e.ssh.GetPort()), config)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		client, err = sshclient.Dial(net.JoinHostPort(e.ipAddress, strconv.Itoa(e.ssh.GetPort())), config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	e.client = client
+	return client, nil
+}
+
+func (e *nativeSSHExecutor) OpenSFTP() (*transfer.Stream, error) {
+	return e.openStream(func(session *ssh.Session) error {
+		return session.RequestSubsystem("sftp")
+	})
+}
+
+func (e *nativeSSHExecutor) OpenCommand(command string) (*transfer.Stream, error) {
+	return e.openStream(func(session *ssh.Session) error {
+		return session.Start(command)
+	})
+}
+
+func (e *nativeSSHExecutor) Close() error {
+	if e.client != nil {
+		err := e.client.Close()
+		e.client = nil
+		return err
+	}
+	return nil
+}
+
+func (e *nativeSSHExecutor) openStream(start func(session *ssh.Session) error) (*transfer.Stream, error) {
+	client, err := e.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := client.NewSession()
+	if err != nil {
+		return nil, err
+	}
+
+	writer, err := session.StdinPipe()
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+	reader, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+	session.Stderr = os.Stderr
+
+	if err := start(session); err != nil {
+		session.Close()
+		return nil, err
+	}
+
+	return &transfer.Stream{
+		Reader: reader,
+		Writer: writer,
+		Close: func() error {
+			defer session.Close()
+			writer.Close()
+			return session.Wait()
+		},
+	}, nil
+}
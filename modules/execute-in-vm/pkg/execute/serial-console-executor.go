@@ -0,0 +1,124 @@
+package execute
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/constants"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/execattributes"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/portforward"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/serialconsole"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/transfer"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/utils/log"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/utils/parse"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/exit"
+	"go.uber.org/zap"
+	"kubevirt.io/client-go/kubecli"
+)
+
+var errSerialConsoleFileTransfer = errors.New("file transfer is not supported over the serial console")
+
+// serialConsoleExecutor executes the script in a shell of the serial console, so the VM does not need networking or ssh
+type serialConsoleExecutor struct {
+	clioptions     *parse.CLIOptions
+	serialConsole  execattributes.SerialConsoleAttributes
+	kubevirtClient kubecli.KubevirtClient
+	console        *serialconsole.Console
+}
+
+func newSerialConsoleExecutor(clioptions *parse.CLIOptions, execAttributes execattributes.ExecAttributes, kubevirtClient kubecli.KubevirtClient) *serialConsoleExecutor {
+	return &serialConsoleExecutor{
+		clioptions:     clioptions,
+		serialConsole:  execAttributes.GetSerialConsoleAttributes(),
+		kubevirtClient: kubevirtClient,
+	}
+}
+
+// Init does not need the address of the VM, the serial console is reached through the console subresource of the VMI
+func (e *serialConsoleExecutor) Init(_ string, _ *portforward.Dialer) error {
+	return nil
+}
+
+// TestConnection opens the serial console, which is then kept open for the script
+func (e *serialConsoleExecutor) TestConnection() bool {
+	if e.console != nil {
+		return true
+	}
+
+	vmName := e.clioptions.VirtualMachineName
+	stream, err := e.kubevirtClient.VirtualMachineInstance(e.clioptions.GetVirtualMachineNamespace()).SerialConsole(vmName, &kubecli.SerialConsoleOptions{
+		ConnectionTimeout: constants.SerialConsoleConnectionTimeout,
+	})
+	if err != nil {
+		log.Logger().Debug("could not connect to serial console", zap.String("name", vmName), zap.Error(err))
+		return false
+	}
+
+	e.console = serialconsole.NewConsole(stream.AsConn(), e.serialConsole.GetPrompt())
+	return true
+}
+
+// RemoteExecute writes all output to stdout, because the serial console does not separate stderr
+func (e *serialConsoleExecutor) RemoteExecute(timeout time.Duration, stdout, _ io.Writer) error {
+	if e.console == nil {
+		return errors.New("serial console is not connected")
+	}
+
+	start := time.Now()
+	log.Logger().Debug("logging in to serial console", zap.String("user", e.serialConsole.GetUser()))
+	if err := e.console.Login(e.serialConsole.GetUser(), e.serialConsole.GetPassword(), timeout); err != nil {
+		return e.toExitError(err)
+	}
+
+	if timeout > 0 {
+		timeout -= time.Since(start)
+		if timeout <= 0 {
+			return e.toExitError(serialconsole.ErrTimeout)
+		}
+	}
+
+	log.Logger().Debug("executing script in serial console")
+	exitCode, err := e.console.Run(e.clioptions.GetScript(), stdout, timeout)
+	if err != nil {
+		return e.toExitError(err)
+	}
+
+	return exit.Exit{
+		Code: exitCode,
+		Soft: true,
+	}
+}
+
+func (e *serialConsoleExecutor) OpenSFTP() (*transfer.Stream, error) {
+	return nil, errSerialConsoleFileTransfer
+}
+
+func (e *serialConsoleExecutor) OpenCommand(_ string) (*transfer.Stream, error) {
+	return nil, errSerialConsoleFileTransfer
+}
+
+func (e *serialConsoleExecutor) Close() error {
+	if e.console == nil {
+		return nil
+	}
+	err := e.console.Close()
+	e.console = nil
+	return err
+}
+
+// toExitError reports timeouts like the ssh executors, errors of the login keep their message
+func (e *serialConsoleExecutor) toExitError(err error) error {
+	if errors.Is(err, serialconsole.ErrTimeout) {
+		msg := "command timed out"
+		if err != serialconsole.ErrTimeout {
+			msg = err.Error()
+		}
+		return exit.Exit{
+			Code: constants.CommandTimeout,
+			Msg:  msg,
+			Soft: true,
+		}
+	}
+	return err
+}
@@ -5,15 +5,21 @@ import (
 	cmd2 "github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/cmd"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/constants"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/execattributes"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/portforward"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/sshclient"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/transfer"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/utils/log"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/utils/parse"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/options"
+	"go.uber.org/zap"
+	"io"
 	"net"
 	"os"
 	"os/exec"
 	"path"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -27,32 +33,59 @@ const (
 	defaultDirMode  = 0700
 )
 
+// sshFilesLock guards the ssh files, which are shared by executors of VMs running concurrently
+var sshFilesLock sync.Mutex
+
 type sshExecutor struct {
-	clioptions *parse.CLIOptions
-	ssh        execattributes.SSHAttributes
-	ipAddress  string
+	clioptions  *parse.CLIOptions
+	ssh         execattributes.SSHAttributes
+	ipAddress   string
+	portForward *portforward.Dialer
+	// forwarder exposes the port-forwarded ssh port of the VMI to the ssh binary
+	forwarder *portforward.Forwarder
 }
 
 func newSSHExecutor(clioptions *parse.CLIOptions, execAttributes execattributes.ExecAttributes) *sshExecutor {
 	return &sshExecutor{clioptions: clioptions, ssh: execAttributes.GetSSHAttributes()}
 }
 
-func (e *sshExecutor) Init(ipAddress string) error {
+func (e *sshExecutor) Init(ipAddress string, portForward *portforward.Dialer) error {
 	e.ipAddress = ipAddress
+	e.portForward = portForward
+
+	knownHostAddress := ipAddress
+	if portForward != nil {
+		forwarder, err := portforward.NewForwarder(func() (net.Conn, error) {
+			return portForward.Dial(e.ssh.GetPort())
+		})
+		if err != nil {
+			return err
+		}
+		e.forwarder = forwarder
+		// ssh looks up non-default ports in this format
+		knownHostAddress = fmt.Sprintf("[%v]:%v", forwarder.Host(), forwarder.Port())
+	}
 
 	log.Logger().Debug("preparing ssh files")
+	sshFilesLock.Lock()
+	defer sshFilesLock.Unlock()
+
 	if err := os.MkdirAll(e.ssh.GetSSHDir(), defaultDirMode); err != nil {
 		return err
 	}
 
 	if privateKey := e.ssh.GetPrivateKey(); privateKey != "" {
-		if err := writeToUserFile(path.Join(e.ssh.GetSSHDir(), idRSAFilename), privateKey, false); err != nil {
-			return err
+		// do not truncate the key while ssh of another VM may be reading it
+		idRSAPath := path.Join(e.ssh.GetSSHDir(), idRSAFilename)
+		if current, err := os.ReadFile(idRSAPath); err != nil || string(current) != privateKey {
+			if err := writeToUserFile(idRSAPath, privateKey, false); err != nil {
+				return err
+			}
 		}
 	}
 
 	if hostPublicKey := e.ssh.GetHostPublicKey(); hostPublicKey != "" {
-		knownHost := fmt.Sprintf("%v %v", ipAddress, hostPublicKey)
+		knownHost := fmt.Sprintf("%v %v", knownHostAddress, hostPublicKey)
 		if err := writeToUserFile(path.Join(e.ssh.GetSSHDir(), knownHostsFilename), knownHost, true); err != nil {
 			return err
 		}
@@ -63,22 +96,11 @@ func (e *sshExecutor) Init(ipAddress string) error {
 }
 
 func (e *sshExecutor) TestConnection() bool {
-	address := net.JoinHostPort(e.ipAddress, strconv.Itoa(e.ssh.GetPort()))
-	conn, err := net.DialTimeout("tcp", address, constants.CheckSSHConnectionTimeout)
-	if conn != nil {
-		defer conn.Close()
-	} else {
-		log.Logger().Debug("connection not found: " + address)
-	}
-
-	return conn != nil && err == nil
+	return testSSHConnection(e.ipAddress, e.ssh.GetPort(), e.portForward)
 }
 
-func (e *sshExecutor) RemoteExecute(timeout time.Duration) error {
-	opts := options.NewCommandOptionsFromArray(e.ssh.GetAdditionalSSHOptions())
-
-	destination := e.ssh.GetUser() + "@" + e.ipAddress
-	opts.AddValue(destination)
+func (e *sshExecutor) RemoteExecute(timeout time.Duration, stdout, stderr io.Writer) error {
+	opts := e.getOptions()
 
 	log.Logger().Debug("executing ssh command with options: " + strings.Join(opts.GetAll(), " "))
 
@@ -87,12 +109,126 @@ func (e *sshExecutor) RemoteExecute(timeout time.Duration) error {
 	opts.AddValue(e.clioptions.GetScript())
 
 	cmd := exec.Command(e.ssh.GetSSHExecutableName(), opts.GetAll()...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 
 	return cmd2.RunCmdWithTimeout(timeout, cmd)
 }
 
+func (e *sshExecutor) OpenSFTP() (*transfer.Stream, error) {
+	opts := e.getOptions("-s")
+	opts.AddValue("sftp")
+
+	return e.openStream(opts)
+}
+
+func (e *sshExecutor) OpenCommand(command string) (*transfer.Stream, error) {
+	opts := e.getOptions()
+	opts.AddValue("--")
+	opts.AddValue(command)
+
+	return e.openStream(opts)
+}
+
+func (e *sshExecutor) Close() error {
+	if e.forwarder != nil {
+		return e.forwarder.Close()
+	}
+	return nil
+}
+
+// getOptions returns ssh options followed by the flags and the destination
+func (e *sshExecutor) getOptions(flags ...string) *options.CommandOptions {
+	host := e.ipAddress
+	opts := options.NewCommandOptionsFromArray(e.ssh.GetAdditionalSSHOptions())
+
+	if e.forwarder != nil {
+		// connect to the local port of the forwarder instead of the port of the VM
+		host = e.forwarder.Host()
+		opts = options.NewCommandOptionsFromArray(withoutPortOption(e.ssh.GetAdditionalSSHOptions()))
+		opts.AddOption("-p", strconv.Itoa(e.forwarder.Port()))
+	}
+
+	for _, flag := range flags {
+		opts.AddFlag(flag)
+	}
+
+	destination := e.ssh.GetUser() + "@" + host
+	opts.AddValue(destination)
+
+	return opts
+}
+
+func (e *sshExecutor) openStream(opts *options.CommandOptions) (*transfer.Stream, error) {
+	cmd := exec.Command(e.ssh.GetSSHExecutableName(), opts.GetAll()...)
+	cmd.Stderr = os.Stderr
+
+	writer, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	reader, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	return &transfer.Stream{
+		Reader: reader,
+		Writer: writer,
+		Close: func() error {
+			writer.Close()
+			return cmd.Wait()
+		},
+	}, nil
+}
+
+func testSSHConnection(ipAddress string, port int, portForward *portforward.Dialer) bool {
+	if portForward != nil {
+		// the portforward subresource connects to the VMI even if nothing listens on the port
+		conn, err := portForward.Dial(port)
+		if err != nil {
+			log.Logger().Debug("connection not found: "+portForward.Address(port), zap.Error(err))
+			return false
+		}
+		defer conn.Close()
+
+		if err := sshclient.WaitForBanner(conn, constants.CheckSSHConnectionTimeout); err != nil {
+			log.Logger().Debug("ssh server not found: "+portForward.Address(port), zap.Error(err))
+			return false
+		}
+		return true
+	}
+
+	address := net.JoinHostPort(ipAddress, strconv.Itoa(port))
+	conn, err := net.DialTimeout("tcp", address, constants.CheckSSHConnectionTimeout)
+	if conn != nil {
+		defer conn.Close()
+	} else {
+		log.Logger().Debug("connection not found: " + address)
+	}
+
+	return conn != nil && err == nil
+}
+
+// withoutPortOption removes -p options from ssh options
+func withoutPortOption(sshOptions []string) []string {
+	var result []string
+	for i := 0; i < len(sshOptions); i++ {
+		switch opt := sshOptions[i]; {
+		case opt == "-p":
+			i++
+		case strings.HasPrefix(opt, "-p"):
+		default:
+			result = append(result, opt)
+		}
+	}
+	return result
+}
+
 func writeToUserFile(filename string, content string, append bool) error {
 	flags := os.O_CREATE | os.O_WRONLY
 
@@ -1,9 +1,19 @@
 package execute
 
-import "time"
+import (
+	"io"
+	"time"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/portforward"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/transfer"
+)
 
 type RemoteExecutor interface {
-	Init(ipAddress string) error
+	transfer.Channel
+	// Init prepares the connection to the VM, which is reached through the portforward subresource when portForward is set
+	Init(ipAddress string, portForward *portforward.Dialer) error
 	TestConnection() bool
-	RemoteExecute(timeout time.Duration) error
+	// RemoteExecute executes the script and writes its output to stdout and stderr
+	RemoteExecute(timeout time.Duration, stdout, stderr io.Writer) error
+	Close() error
 }
@@ -0,0 +1,99 @@
+package execute
+
+import (
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/constants"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/execattributes"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/portforward"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/transfer"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/utils/log"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/utils/parse"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/winrmclient"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/exit"
+	"github.com/masterzen/winrm"
+	"go.uber.org/zap"
+)
+
+var errWinRMFileTransfer = errors.New("file transfer is not supported over winrm")
+
+// winRMExecutor executes the script in a PowerShell or cmd shell of a Windows VM
+type winRMExecutor struct {
+	clioptions *parse.CLIOptions
+	winRM      execattributes.WinRMAttributes
+	client     *winrm.Client
+}
+
+func newWinRMExecutor(clioptions *parse.CLIOptions, execAttributes execattributes.ExecAttributes) *winRMExecutor {
+	return &winRMExecutor{clioptions: clioptions, winRM: execAttributes.GetWinRMAttributes()}
+}
+
+func (e *winRMExecutor) Init(ipAddress string, portForward *portforward.Dialer) error {
+	config := &winrmclient.Config{
+		Host:          ipAddress,
+		Port:          e.winRM.GetPort(),
+		HTTPS:         e.winRM.UseHTTPS(),
+		Insecure:      e.winRM.IsInsecureSkipVerify(),
+		CACert:        e.winRM.GetCACert(),
+		TLSServerName: e.winRM.GetTLSServerName(),
+		ClientCert:    e.winRM.GetClientCert(),
+		ClientKey:     e.winRM.GetClientKey(),
+		User:          e.winRM.GetUser(),
+		Password:      e.winRM.GetPassword(),
+	}
+
+	if portForward != nil {
+		// the host is used in the url of the requests and the certificate is verified against it unless the tls server name is set
+		config.Host = e.clioptions.VirtualMachineName
+		config.Dial = func(_, _ string) (net.Conn, error) {
+			return portForward.Dial(e.winRM.GetPort())
+		}
+	}
+
+	client, err := winrmclient.NewClient(config)
+	if err != nil {
+		return err
+	}
+	e.client = client
+	return nil
+}
+
+func (e *winRMExecutor) TestConnection() bool {
+	return winrmclient.IsReachable(e.client)
+}
+
+func (e *winRMExecutor) RemoteExecute(timeout time.Duration, stdout, stderr io.Writer) error {
+	log.Logger().Debug("executing script over winrm", zap.String("user", e.winRM.GetUser()), zap.String("shell", string(e.winRM.GetShell())))
+
+	exitCode, err := winrmclient.Run(e.client, e.clioptions.GetScript(), e.winRM.GetShell(), stdout, stderr, timeout)
+	if err != nil {
+		if errors.Is(err, winrmclient.ErrTimeout) {
+			return exit.Exit{
+				Code: constants.CommandTimeout,
+				Msg:  "command timed out",
+				Soft: true,
+			}
+		}
+		return err
+	}
+
+	return exit.Exit{
+		Code: exitCode,
+		Soft: true,
+	}
+}
+
+func (e *winRMExecutor) OpenSFTP() (*transfer.Stream, error) {
+	return nil, errWinRMFileTransfer
+}
+
+func (e *winRMExecutor) OpenCommand(_ string) (*transfer.Stream, error) {
+	return nil, errWinRMFileTransfer
+}
+
+func (e *winRMExecutor) Close() error {
+	return nil
+}
@@ -0,0 +1,90 @@
+package fanout
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Result is the outcome of the actions executed in a single VM
+type Result struct {
+	VirtualMachineName string
+	// ExitCode of the script is nil when the script was not executed
+	ExitCode *int
+	Duration time.Duration
+	Err      error
+}
+
+// Failed is true when the actions failed or the script exited with a non-zero exit code
+func (r *Result) Failed() bool {
+	return r.Err != nil || (r.ExitCode != nil && *r.ExitCode != 0)
+}
+
+// Run calls run for each of the VMs, at most parallelism calls are running at the same time.
+// The results are returned in the order of vmNames.
+func Run(vmNames []string, parallelism int, run func(vmName string) (*int, error)) []Result {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	results := make([]Result, len(vmNames))
+	semaphore := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+
+	for idx, vmName := range vmNames {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(idx int, vmName string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			start := time.Now()
+			exitCode, err := run(vmName)
+			results[idx] = Result{
+				VirtualMachineName: vmName,
+				ExitCode:           exitCode,
+				Duration:           time.Since(start).Round(time.Millisecond),
+				Err:                err,
+			}
+		}(idx, vmName)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// CountFailed returns the number of failed results
+func CountFailed(results []Result) int {
+	failed := 0
+	for _, result := range results {
+		if result.Failed() {
+			failed++
+		}
+	}
+	return failed
+}
+
+// WriteSummary writes a table with the exit code, duration and error of each VM
+func WriteSummary(out io.Writer, results []Result) error {
+	writer := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(writer, "VM\tEXIT CODE\tDURATION\tERROR")
+
+	for _, result := range results {
+		exitCode := "-"
+		if result.ExitCode != nil {
+			exitCode = strconv.Itoa(*result.ExitCode)
+		}
+		errMsg := ""
+		if result.Err != nil {
+			// keep each VM on a single row
+			errMsg = strings.ReplaceAll(strings.TrimSpace(result.Err.Error()), "\n", "; ")
+		}
+		fmt.Fprintf(writer, "%v\t%v\t%v\t%v\n", result.VirtualMachineName, exitCode, result.Duration, errMsg)
+	}
+
+	return writer.Flush()
+}
@@ -0,0 +1,99 @@
+package fanout_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/fanout"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func exitCode(code int) *int {
+	return &code
+}
+
+var _ = Describe("FanOut", func() {
+	vmNames := []string{"vm-1", "vm-2", "vm-3", "vm-4", "vm-5"}
+
+	DescribeTable("Run respects the parallelism", func(parallelism, expectedMaxRunning int) {
+		var running, maxRunning int32
+		results := fanout.Run(vmNames, parallelism, func(vmName string) (*int, error) {
+			current := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				max := atomic.LoadInt32(&maxRunning)
+				if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+					break
+				}
+			}
+			time.Sleep(50 * time.Millisecond)
+			return exitCode(0), nil
+		})
+
+		Expect(results).To(HaveLen(len(vmNames)))
+		Expect(maxRunning).To(BeNumerically("<=", expectedMaxRunning))
+		Expect(maxRunning).To(BeNumerically(">=", 1))
+	},
+		Entry("sequentially", 1, 1),
+		Entry("two at a time", 2, 2),
+		Entry("all at once", 10, len(vmNames)),
+		Entry("invalid parallelism", 0, 1),
+	)
+
+	It("Run returns results in the order of the VMs", func() {
+		var lock sync.Mutex
+		var called []string
+		results := fanout.Run(vmNames, 3, func(vmName string) (*int, error) {
+			lock.Lock()
+			called = append(called, vmName)
+			lock.Unlock()
+
+			if vmName == "vm-2" {
+				return nil, errors.New("could not connect")
+			}
+			time.Sleep(10 * time.Millisecond)
+			return exitCode(len(vmName)), nil
+		})
+
+		Expect(called).To(ConsistOf(vmNames))
+		for idx, result := range results {
+			Expect(result.VirtualMachineName).To(Equal(vmNames[idx]))
+			Expect(result.Duration).To(BeNumerically(">=", 0))
+		}
+		Expect(results[1].ExitCode).To(BeNil())
+		Expect(results[1].Err).To(MatchError("could not connect"))
+		Expect(*results[0].ExitCode).To(Equal(4))
+		Expect(results[0].Err).To(BeNil())
+	})
+
+	DescribeTable("CountFailed", func(results []fanout.Result, expectedFailed int) {
+		Expect(fanout.CountFailed(results)).To(Equal(expectedFailed))
+	},
+		Entry("no results", nil, 0),
+		Entry("succeeded", []fanout.Result{{ExitCode: exitCode(0)}, {}}, 0),
+		Entry("non-zero exit codes", []fanout.Result{{ExitCode: exitCode(1)}, {ExitCode: exitCode(-4)}, {ExitCode: exitCode(0)}}, 2),
+		Entry("errors", []fanout.Result{{Err: errors.New("failed")}, {ExitCode: exitCode(0), Err: errors.New("could not stop")}}, 2),
+	)
+
+	It("WriteSummary writes a row for each VM", func() {
+		var out bytes.Buffer
+		Expect(fanout.WriteSummary(&out, []fanout.Result{
+			{VirtualMachineName: "vm-1", ExitCode: exitCode(0), Duration: 90 * time.Second},
+			{VirtualMachineName: "vm-with-long-name", ExitCode: exitCode(-4), Duration: time.Minute},
+			{VirtualMachineName: "vm-3", Duration: 1500 * time.Millisecond, Err: errors.New("could not connect\nVMI failed\n")},
+		})).To(Succeed())
+
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		Expect(lines).To(Equal([]string{
+			"VM                  EXIT CODE   DURATION   ERROR",
+			"vm-1                0           1m30s      ",
+			"vm-with-long-name   -4          1m0s       ",
+			"vm-3                -           1.5s       could not connect; VMI failed",
+		}))
+	})
+})
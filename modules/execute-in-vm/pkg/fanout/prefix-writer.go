@@ -0,0 +1,57 @@
+package fanout
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// PrefixWriter prefixes each line of the output of a VM, so the output of VMs
+// running concurrently can be told apart. Writers sharing the same out should share the lock,
+// which keeps whole lines together.
+type PrefixWriter struct {
+	out    io.Writer
+	lock   *sync.Mutex
+	prefix []byte
+	line   []byte
+}
+
+func NewPrefixWriter(out io.Writer, lock *sync.Mutex, prefix string) *PrefixWriter {
+	return &PrefixWriter{out: out, lock: lock, prefix: []byte(prefix)}
+}
+
+// Write passes complete lines to out and buffers the rest until the next newline or Flush
+func (w *PrefixWriter) Write(data []byte) (int, error) {
+	for remaining := data; len(remaining) > 0; {
+		end := bytes.IndexByte(remaining, '\n')
+		if end < 0 {
+			w.line = append(w.line, remaining...)
+			break
+		}
+		w.line = append(w.line, remaining[:end+1]...)
+		remaining = remaining[end+1:]
+		if err := w.writeLine(); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+// Flush writes the last incomplete line followed by a newline
+func (w *PrefixWriter) Flush() error {
+	if len(w.line) == 0 {
+		return nil
+	}
+	w.line = append(w.line, '\n')
+	return w.writeLine()
+}
+
+func (w *PrefixWriter) writeLine() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	line := append(append([]byte{}, w.prefix...), w.line...)
+	w.line = w.line[:0]
+	_, err := w.out.Write(line)
+	return err
+}
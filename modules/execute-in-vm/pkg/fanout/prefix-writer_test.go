@@ -0,0 +1,60 @@
+package fanout_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/fanout"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PrefixWriter", func() {
+	DescribeTable("prefixes lines", func(chunks []string, expectedOutput string) {
+		var out bytes.Buffer
+		writer := fanout.NewPrefixWriter(&out, &sync.Mutex{}, "[vm] ")
+		for _, chunk := range chunks {
+			n, err := writer.Write([]byte(chunk))
+			Expect(err).Should(Succeed())
+			Expect(n).To(Equal(len(chunk)))
+		}
+		Expect(writer.Flush()).To(Succeed())
+		Expect(out.String()).To(Equal(expectedOutput))
+	},
+		Entry("no output", []string{}, ""),
+		Entry("single line", []string{"hello\n"}, "[vm] hello\n"),
+		Entry("lines split into chunks", []string{"hel", "lo\nwor", "ld\n"}, "[vm] hello\n[vm] world\n"),
+		Entry("empty lines", []string{"\n\n"}, "[vm] \n[vm] \n"),
+		Entry("incomplete last line", []string{"hello\nworld"}, "[vm] hello\n[vm] world\n"),
+	)
+
+	It("keeps lines of concurrent writers together", func() {
+		var out bytes.Buffer
+		var lock sync.Mutex
+		var wg sync.WaitGroup
+
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				writer := fanout.NewPrefixWriter(&out, &lock, fmt.Sprintf("[vm-%v] ", i))
+				for j := 0; j < 100; j++ {
+					_, err := writer.Write([]byte("first half "))
+					Expect(err).Should(Succeed())
+					_, err = writer.Write([]byte("second half\n"))
+					Expect(err).Should(Succeed())
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		Expect(lines).To(HaveLen(500))
+		for _, line := range lines {
+			Expect(line).To(MatchRegexp(`^\[vm-\d\] first half second half$`))
+		}
+	})
+})
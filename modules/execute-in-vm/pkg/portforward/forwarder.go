@@ -0,0 +1,75 @@
+package portforward
+
+import (
+	"io"
+	"net"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/utils/log"
+	"go.uber.org/zap"
+)
+
+const localhost = "127.0.0.1"
+
+// Forwarder listens on a local port and forwards each accepted connection to a new connection returned by dial.
+// It allows programs which can only connect to a host and port, like the ssh binary, to reach the VMI.
+type Forwarder struct {
+	listener net.Listener
+	dial     func() (net.Conn, error)
+}
+
+func NewForwarder(dial func() (net.Conn, error)) (*Forwarder, error) {
+	listener, err := net.Listen("tcp", net.JoinHostPort(localhost, "0"))
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Forwarder{listener: listener, dial: dial}
+	go f.serve()
+	return f, nil
+}
+
+func (f *Forwarder) Host() string {
+	return localhost
+}
+
+func (f *Forwarder) Port() int {
+	return f.listener.Addr().(*net.TCPAddr).Port
+}
+
+// Close stops accepting new connections, open connections are closed once one of their sides closes
+func (f *Forwarder) Close() error {
+	return f.listener.Close()
+}
+
+func (f *Forwarder) serve() {
+	for {
+		local, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go f.forward(local)
+	}
+}
+
+func (f *Forwarder) forward(local net.Conn) {
+	defer local.Close()
+
+	remote, err := f.dial()
+	if err != nil {
+		log.Logger().Debug("could not forward connection", zap.Error(err))
+		return
+	}
+	defer remote.Close()
+
+	done := make(chan struct{}, 2)
+	pipe := func(dst, src net.Conn) {
+		io.Copy(dst, src)
+		done <- struct{}{}
+	}
+	go pipe(remote, local)
+	go pipe(local, remote)
+
+	// close both sides when one of them closes
+	<-done
+}
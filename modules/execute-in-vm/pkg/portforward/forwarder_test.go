@@ -0,0 +1,93 @@
+package portforward_test
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/portforward"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Forwarder", func() {
+	var remote net.Listener
+
+	BeforeEach(func() {
+		var err error
+		remote, err = net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).Should(Succeed())
+
+		// echo server
+		go func() {
+			for {
+				conn, err := remote.Accept()
+				if err != nil {
+					return
+				}
+				go func() {
+					defer conn.Close()
+					io.Copy(conn, conn)
+				}()
+			}
+		}()
+	})
+
+	AfterEach(func() {
+		remote.Close()
+	})
+
+	connect := func(forwarder *portforward.Forwarder) net.Conn {
+		conn, err := net.Dial("tcp", net.JoinHostPort(forwarder.Host(), strconv.Itoa(forwarder.Port())))
+		Expect(err).Should(Succeed())
+		return conn
+	}
+
+	It("forwards connections", func() {
+		dials := 0
+		forwarder, err := portforward.NewForwarder(func() (net.Conn, error) {
+			dials++
+			return net.Dial("tcp", remote.Addr().String())
+		})
+		Expect(err).Should(Succeed())
+		defer forwarder.Close()
+
+		Expect(forwarder.Host()).To(Equal("127.0.0.1"))
+
+		for _, message := range []string{"first\n", "second\n"} {
+			conn := connect(forwarder)
+			_, err = conn.Write([]byte(message))
+			Expect(err).Should(Succeed())
+
+			reply, err := bufio.NewReader(conn).ReadString('\n')
+			Expect(err).Should(Succeed())
+			Expect(reply).To(Equal(message))
+			Expect(conn.Close()).To(Succeed())
+		}
+		Expect(dials).To(Equal(2))
+	})
+
+	It("closes connections which could not be forwarded", func() {
+		forwarder, err := portforward.NewForwarder(func() (net.Conn, error) {
+			return nil, errors.New("portforward is forbidden")
+		})
+		Expect(err).Should(Succeed())
+		defer forwarder.Close()
+
+		_, err = io.ReadAll(connect(forwarder))
+		Expect(err).Should(Succeed())
+	})
+
+	It("stops listening when closed", func() {
+		forwarder, err := portforward.NewForwarder(func() (net.Conn, error) {
+			return net.Dial("tcp", remote.Addr().String())
+		})
+		Expect(err).Should(Succeed())
+		Expect(forwarder.Close()).To(Succeed())
+
+		_, err = net.Dial("tcp", net.JoinHostPort(forwarder.Host(), strconv.Itoa(forwarder.Port())))
+		Expect(err).Should(HaveOccurred())
+	})
+})
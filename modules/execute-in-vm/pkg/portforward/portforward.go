@@ -0,0 +1,38 @@
+package portforward
+
+import (
+	"fmt"
+	"net"
+
+	"kubevirt.io/client-go/kubecli"
+)
+
+const tcpProtocol = "tcp"
+
+// Dialer opens connections to ports of a VMI through the portforward subresource, so the pod network of the VMI does not have to be reachable
+type Dialer struct {
+	client    kubecli.KubevirtClient
+	namespace string
+	name      string
+}
+
+func NewDialer(client kubecli.KubevirtClient, namespace, name string) *Dialer {
+	return &Dialer{
+		client:    client,
+		namespace: namespace,
+		name:      name,
+	}
+}
+
+func (d *Dialer) Dial(port int) (net.Conn, error) {
+	stream, err := d.client.VirtualMachineInstance(d.namespace).PortForward(d.name, port, tcpProtocol)
+	if err != nil {
+		return nil, fmt.Errorf("could not port-forward to port %v of VMI %v: %v", port, d.name, err.Error())
+	}
+	return stream.AsConn(), nil
+}
+
+// Address identifies the VMI port in logs and host key verification errors
+func (d *Dialer) Address(port int) string {
+	return fmt.Sprintf("vmi/%v.%v:%v", d.name, d.namespace, port)
+}
@@ -0,0 +1,18 @@
+package portforward_test
+
+import (
+	"testing"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/utilstest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPortForward(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "PortForward Suite")
+}
+
+var _ = BeforeSuite(utilstest.SetupTestSuite)
+var _ = AfterSuite(utilstest.TearDownSuite)
@@ -0,0 +1,283 @@
+package scriptoutput
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/constants"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/utils/log"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zerrors"
+	"go.uber.org/zap"
+)
+
+const (
+	// ResultPrefix starts the lines of stdout which set named results in a "::result name=value" format
+	ResultPrefix = "::result "
+
+	defaultFileMode = 0644
+	defaultDirMode  = 0755
+)
+
+// resultNamePattern follows the restrictions of Tekton result names
+var resultNamePattern = regexp.MustCompile(`^[a-zA-Z0-9]([-_.a-zA-Z0-9]*[a-zA-Z0-9])?$`)
+
+// Recorder captures the output of a script: stdout and stderr are copied into files,
+// the tail of stdout is kept and named results are parsed from the lines of stdout
+type Recorder struct {
+	// lock guards the recorder against concurrent writes to stdout and stderr
+	lock       sync.Mutex
+	stdoutFile *os.File
+	stderrFile *os.File
+	// fileErr is the first error of writing into the files, which does not interrupt the script
+	fileErr error
+
+	tailSize int
+	tail     []byte
+
+	line []byte
+	// skipLine is set when the current line is too long to be a result
+	skipLine bool
+
+	results     map[string]string
+	resultNames []string
+}
+
+// NewRecorder creates the stdout and stderr files when their paths are not empty
+func NewRecorder(stdoutPath, stderrPath string, tailSize int) (*Recorder, error) {
+	r := &Recorder{
+		tailSize: tailSize,
+		results:  map[string]string{},
+	}
+
+	var err error
+	if r.stdoutFile, err = createFile(stdoutPath); err != nil {
+		return nil, err
+	}
+	if r.stderrFile, err = createFile(stderrPath); err != nil {
+		r.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// Stdout returns a writer which passes the output to stdout and records it
+func (r *Recorder) Stdout(stdout io.Writer) io.Writer {
+	return &recordingWriter{
+		out: stdout,
+		record: func(data []byte) {
+			r.lock.Lock()
+			defer r.lock.Unlock()
+			r.writeFile(r.stdoutFile, data)
+			r.appendTail(data)
+			r.parseLines(data)
+		},
+	}
+}
+
+// Stderr returns a writer which passes the output to stderr and records it
+func (r *Recorder) Stderr(stderr io.Writer) io.Writer {
+	return &recordingWriter{
+		out: stderr,
+		record: func(data []byte) {
+			r.lock.Lock()
+			defer r.lock.Unlock()
+			r.writeFile(r.stderrFile, data)
+		},
+	}
+}
+
+// Results returns the exit code, the tail of stdout and the named results encoded as a JSON object.
+// The sizes of the results are measured in the JSON encoding of the termination message. The named results
+// which do not fit into constants.MaxResultsSize are left out and the beginning of the tail is trimmed to fit.
+func (r *Recorder) Results(exitCode int) map[string]string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.parseLine()
+
+	exitCodeResult := strconv.Itoa(exitCode)
+	// reserve the size of an empty tail
+	size := encodedSize(constants.ExitCodeResultName, exitCodeResult) + encodedSize(constants.StdoutTailResultName, "")
+
+	scriptResults := map[string]string{}
+	scriptResultsResult := "{}"
+	for _, name := range r.resultNames {
+		value := r.results[name]
+		scriptResults[name] = value
+		encoded, _ := json.Marshal(scriptResults)
+		if size+encodedSize(constants.ScriptResultsResultName, string(encoded)) > constants.MaxResultsSize {
+			log.Logger().Warn("result is too large to be recorded", zap.String("name", name), zap.Int("size", len(value)))
+			delete(scriptResults, name)
+			continue
+		}
+		scriptResultsResult = string(encoded)
+	}
+	size += encodedSize(constants.ScriptResultsResultName, scriptResultsResult) - encodedSize(constants.StdoutTailResultName, "")
+
+	return map[string]string{
+		constants.ExitCodeResultName:      exitCodeResult,
+		constants.StdoutTailResultName:    trimTail(r.getTail(), constants.MaxResultsSize-size),
+		constants.ScriptResultsResultName: scriptResultsResult,
+	}
+}
+
+// Close closes the files and returns the first error which occurred while writing them
+func (r *Recorder) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	multiError := zerrors.NewMultiError()
+	if r.fileErr != nil {
+		multiError.Add("write", r.fileErr)
+	}
+	for _, file := range []*os.File{r.stdoutFile, r.stderrFile} {
+		if file != nil {
+			if err := file.Close(); err != nil {
+				multiError.Add(file.Name(), err)
+			}
+		}
+	}
+	r.stdoutFile, r.stderrFile = nil, nil
+
+	if !multiError.IsEmpty() {
+		return multiError
+	}
+	return nil
+}
+
+func (r *Recorder) writeFile(file *os.File, data []byte) {
+	if file == nil || r.fileErr != nil {
+		return
+	}
+	if _, err := file.Write(data); err != nil {
+		log.Logger().Warn("could not write output", zap.String("file", file.Name()), zap.Error(err))
+		r.fileErr = err
+	}
+}
+
+func (r *Recorder) appendTail(data []byte) {
+	if r.tailSize <= 0 {
+		return
+	}
+	if len(data) >= r.tailSize {
+		r.tail = append(r.tail[:0], data[len(data)-r.tailSize:]...)
+		return
+	}
+	r.tail = append(r.tail, data...)
+	if len(r.tail) > r.tailSize {
+		r.tail = append(r.tail[:0], r.tail[len(r.tail)-r.tailSize:]...)
+	}
+}
+
+func (r *Recorder) getTail() string {
+	return string(dropPartialRune(r.tail))
+}
+
+// dropPartialRune drops the beginning of a multi byte character, which was cut
+func dropPartialRune(data []byte) []byte {
+	for i := 0; i < utf8.UTFMax && len(data) > 0 && !utf8.RuneStart(data[0]); i++ {
+		data = data[1:]
+	}
+	return data
+}
+
+// trimTail keeps the longest end of the tail which fits into the budget when encoded
+func trimTail(tail string, budget int) string {
+	size := encodedSize(constants.StdoutTailResultName, "")
+	start := len(tail)
+	for start > 0 {
+		_, runeSize := utf8.DecodeLastRuneInString(tail[:start])
+		// characters are escaped one by one, the quotes are already counted
+		encoded, _ := json.Marshal(tail[start-runeSize : start])
+		if size+len(encoded)-2 > budget {
+			break
+		}
+		size += len(encoded) - 2
+		start -= runeSize
+	}
+	return tail[start:]
+}
+
+// encodedSize returns the size of the result in the JSON encoding of the termination message
+func encodedSize(name, value string) int {
+	encoded, _ := json.Marshal(value)
+	return len(name) + len(encoded)
+}
+
+func (r *Recorder) parseLines(data []byte) {
+	for len(data) > 0 {
+		end := bytes.IndexByte(data, '\n')
+		if end < 0 {
+			r.bufferLine(data)
+			return
+		}
+		r.bufferLine(data[:end])
+		r.parseLine()
+		data = data[end+1:]
+	}
+}
+
+func (r *Recorder) bufferLine(data []byte) {
+	if r.skipLine {
+		return
+	}
+	if len(r.line)+len(data) > constants.MaxResultsSize+len(ResultPrefix) {
+		r.line = r.line[:0]
+		r.skipLine = true
+		return
+	}
+	r.line = append(r.line, data...)
+}
+
+// parseLine records the result of the buffered line and resets the buffer
+func (r *Recorder) parseLine() {
+	line := strings.TrimSuffix(string(r.line), "\r")
+	r.line = r.line[:0]
+	r.skipLine = false
+
+	if !strings.HasPrefix(line, ResultPrefix) {
+		return
+	}
+
+	name, value, found := strings.Cut(strings.TrimPrefix(line, ResultPrefix), "=")
+	name = strings.TrimSpace(name)
+	if !found || !resultNamePattern.MatchString(name) {
+		log.Logger().Warn("ignoring invalid result", zap.String("line", line))
+		return
+	}
+
+	if _, exists := r.results[name]; !exists {
+		r.resultNames = append(r.resultNames, name)
+	}
+	r.results[name] = value
+}
+
+// recordingWriter records the data which was passed to out
+type recordingWriter struct {
+	out    io.Writer
+	record func(data []byte)
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	n, err := w.out.Write(data)
+	w.record(data[:n])
+	return n, err
+}
+
+func createFile(path string) (*os.File, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), defaultDirMode); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, defaultFileMode)
+}
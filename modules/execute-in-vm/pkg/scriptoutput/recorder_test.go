@@ -0,0 +1,163 @@
+package scriptoutput_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/constants"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/scriptoutput"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Recorder", func() {
+	var tempDir string
+
+	BeforeEach(func() {
+		tempDir = GinkgoT().TempDir()
+	})
+
+	// write passes the chunks to the writer one by one, like streams of a remote command
+	write := func(writer io.Writer, chunks ...string) {
+		for _, chunk := range chunks {
+			n, err := writer.Write([]byte(chunk))
+			Expect(err).Should(Succeed())
+			Expect(n).To(Equal(len(chunk)))
+		}
+	}
+
+	It("copies output into files and to the streams", func() {
+		stdoutPath := filepath.Join(tempDir, "logs", "stdout.txt")
+		stderrPath := filepath.Join(tempDir, "logs", "stderr.txt")
+		recorder, err := scriptoutput.NewRecorder(stdoutPath, stderrPath, constants.DefaultStdoutTailSize)
+		Expect(err).Should(Succeed())
+
+		var stdout, stderr bytes.Buffer
+		write(recorder.Stdout(&stdout), "hello ", "world\n")
+		write(recorder.Stderr(&stderr), "warning\n")
+		Expect(recorder.Close()).To(Succeed())
+
+		Expect(stdout.String()).To(Equal("hello world\n"))
+		Expect(stderr.String()).To(Equal("warning\n"))
+		Expect(os.ReadFile(stdoutPath)).To(BeEquivalentTo("hello world\n"))
+		Expect(os.ReadFile(stderrPath)).To(BeEquivalentTo("warning\n"))
+	})
+
+	It("does not create files without paths", func() {
+		recorder, err := scriptoutput.NewRecorder("", "", constants.DefaultStdoutTailSize)
+		Expect(err).Should(Succeed())
+
+		write(recorder.Stdout(io.Discard), "hello\n")
+		write(recorder.Stderr(io.Discard), "warning\n")
+		Expect(recorder.Close()).To(Succeed())
+		Expect(recorder.Results(0)).To(Equal(map[string]string{
+			constants.ExitCodeResultName:      "0",
+			constants.StdoutTailResultName:    "hello\n",
+			constants.ScriptResultsResultName: "{}",
+		}))
+	})
+
+	It("fails when a file cannot be created", func() {
+		Expect(os.WriteFile(filepath.Join(tempDir, "file"), nil, 0644)).To(Succeed())
+		_, err := scriptoutput.NewRecorder(filepath.Join(tempDir, "file", "stdout.txt"), "", constants.DefaultStdoutTailSize)
+		Expect(err).Should(HaveOccurred())
+	})
+
+	DescribeTable("records the tail of stdout", func(tailSize int, chunks []string, expectedTail string) {
+		recorder, err := scriptoutput.NewRecorder("", "", tailSize)
+		Expect(err).Should(Succeed())
+
+		write(recorder.Stdout(io.Discard), chunks...)
+		write(recorder.Stderr(io.Discard), "stderr is not recorded")
+		Expect(recorder.Results(0)[constants.StdoutTailResultName]).To(Equal(expectedTail))
+	},
+		Entry("short output", 10, []string{"abc"}, "abc"),
+		Entry("long chunk", 5, []string{"0123456789"}, "56789"),
+		Entry("many chunks", 5, []string{"012", "345", "678", "9"}, "56789"),
+		Entry("disabled", 0, []string{"0123456789"}, ""),
+		Entry("cut multi byte character", 5, []string{"abžluť"}, "luť"),
+	)
+
+	DescribeTable("records named results", func(chunks []string, expectedResults string) {
+		recorder, err := scriptoutput.NewRecorder("", "", constants.DefaultStdoutTailSize)
+		Expect(err).Should(Succeed())
+
+		var stdout bytes.Buffer
+		write(recorder.Stdout(&stdout), chunks...)
+		Expect(stdout.String()).To(Equal(strings.Join(chunks, "")))
+		Expect(recorder.Results(0)[constants.ScriptResultsResultName]).To(Equal(expectedResults))
+	},
+		Entry("no results", []string{"hello\n"}, "{}"),
+		Entry("single result", []string{"::result version=1.2.3\n"}, `{"version":"1.2.3"}`),
+		Entry("results split into chunks", []string{"output\n::res", "ult ver", "sion=1.2.3\n::result status=", "passed"}, `{"status":"passed","version":"1.2.3"}`),
+		Entry("windows line endings", []string{"::result version=1.2.3\r\n"}, `{"version":"1.2.3"}`),
+		Entry("value with special characters", []string{"::result message= a=b \"quoted\"\n"}, `{"message":" a=b \"quoted\""}`),
+		Entry("empty value", []string{"::result empty=\n"}, `{"empty":""}`),
+		Entry("last value wins", []string{"::result status=running\n::result status=passed\n"}, `{"status":"passed"}`),
+		Entry("invalid names", []string{"::result =value\n::result -name=value\n::result with space=value\n::result novalue\n"}, "{}"),
+		Entry("prefix in the middle of a line", []string{"echo ::result version=1.2.3\n"}, "{}"),
+		Entry("too long line", []string{"::result long=" + strings.Repeat("a", constants.MaxResultsSize) + "\n::result short=value\n"}, `{"short":"value"}`),
+	)
+
+	It("records the exit code", func() {
+		recorder, err := scriptoutput.NewRecorder("", "", constants.DefaultStdoutTailSize)
+		Expect(err).Should(Succeed())
+		Expect(recorder.Results(constants.CommandTimeout)[constants.ExitCodeResultName]).To(Equal("-4"))
+	})
+
+	// encodedSize measures the results in the JSON encoding of the termination message
+	encodedSize := func(results map[string]string) int {
+		size := 0
+		for name, value := range results {
+			encoded, err := json.Marshal(value)
+			Expect(err).Should(Succeed())
+			size += len(name) + len(encoded)
+		}
+		return size
+	}
+
+	It("respects the size limit of results", func() {
+		recorder, err := scriptoutput.NewRecorder("", "", constants.MaxStdoutTailSize)
+		Expect(err).Should(Succeed())
+
+		write(recorder.Stdout(io.Discard),
+			"::result first="+strings.Repeat("a", 500)+"\n",
+			"::result second="+strings.Repeat("b", 2600)+"\n",
+			"::result third=c\n",
+			strings.Repeat("x", constants.MaxStdoutTailSize),
+		)
+
+		results := recorder.Results(0)
+		Expect(results[constants.ScriptResultsResultName]).To(Equal(`{"first":"` + strings.Repeat("a", 500) + `","third":"c"}`))
+		Expect(results[constants.StdoutTailResultName]).ToNot(BeEmpty())
+		Expect(encodedSize(results)).To(BeNumerically("<=", constants.MaxResultsSize))
+	})
+
+	DescribeTable("trims the tail of stdout to fit into the size limit", func(output string, expectedTailSuffix string) {
+		recorder, err := scriptoutput.NewRecorder("", "", constants.MaxStdoutTailSize)
+		Expect(err).Should(Succeed())
+
+		write(recorder.Stdout(io.Discard),
+			"::result quoted="+strings.Repeat(`"`, 500)+"\n",
+			output,
+		)
+
+		results := recorder.Results(0)
+		// quotes are escaped in the encoded script results and in the encoded result
+		Expect(results[constants.ScriptResultsResultName]).To(Equal(`{"quoted":"` + strings.Repeat(`\"`, 500) + `"}`))
+		Expect(encodedSize(results)).To(BeNumerically("<=", constants.MaxResultsSize))
+		Expect(encodedSize(results)).To(BeNumerically(">", constants.MaxResultsSize-10))
+		Expect(output).To(HaveSuffix(results[constants.StdoutTailResultName]))
+		Expect(results[constants.StdoutTailResultName]).To(HaveSuffix(expectedTailSuffix))
+		Expect(utf8.ValidString(results[constants.StdoutTailResultName])).To(BeTrue())
+	},
+		Entry("plain output", strings.Repeat("x", constants.MaxStdoutTailSize-3)+"end", "end"),
+		Entry("escaped output", strings.Repeat("\t<", constants.MaxStdoutTailSize/2-2)+"end", "end"),
+		Entry("multi byte characters", strings.Repeat("ž", constants.MaxStdoutTailSize/2-2)+"end", "end"),
+	)
+})
@@ -0,0 +1,313 @@
+package serialconsole
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/constants"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/utils/log"
+	"go.uber.org/zap"
+)
+
+const (
+	startMarker = "EXECUTE_IN_VM_START"
+	endMarker   = "EXECUTE_IN_VM_END"
+	// interrupt is sent as ctrl+c to stop the script
+	interrupt = "\x03"
+	// maxExitCodeLength is the length of ":255\n" which follows the end marker
+	maxExitCodeLength = 5
+	// maxBufferSize limits the output kept while waiting for a prompt, e.g. during a boot of the VM
+	maxBufferSize  = 64 * 1024
+	readBufferSize = 4096
+)
+
+var (
+	loginPrompt    = regexp.MustCompile(`(?i)login:\s*$`)
+	passwordPrompt = regexp.MustCompile(`(?i)password:\s*$`)
+	loginIncorrect = regexp.MustCompile(`(?i)login incorrect`)
+)
+
+// ErrTimeout is returned when the expected output does not appear on the console in time
+var ErrTimeout = errors.New("timed out waiting for the serial console")
+
+// Console drives a shell on a serial console. The output of the console is read only by the Console,
+// and carriage returns are removed from it.
+type Console struct {
+	conn   io.ReadWriteCloser
+	prompt *regexp.Regexp
+
+	chunks chan []byte
+	// readErr is set before chunks is closed
+	readErr  error
+	buffer   []byte
+	loggedIn bool
+}
+
+func NewConsole(conn io.ReadWriteCloser, prompt *regexp.Regexp) *Console {
+	c := &Console{
+		conn:   conn,
+		prompt: prompt,
+		chunks: make(chan []byte, 16),
+	}
+	go c.read()
+	return c
+}
+
+// Login logs in with the user and password unless the console already shows a shell prompt.
+// A new line is sent repeatedly to make the console print its prompt again, because the VM may still be booting.
+func (c *Console) Login(user, password string, timeout time.Duration) error {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+
+	for {
+		retryTimeout, ok := timeoutUntil(deadline, constants.SerialConsoleLoginRetryInterval)
+		if !ok {
+			return fmt.Errorf("could not find a login prompt or a shell prompt: %w", ErrTimeout)
+		}
+
+		if err := c.write("\n"); err != nil {
+			return err
+		}
+
+		matched, err := c.expect(retryTimeout, loginPrompt, c.prompt)
+		if errors.Is(err, ErrTimeout) {
+			log.Logger().Debug("waiting for a login prompt")
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if matched == 1 {
+			log.Logger().Debug("serial console is already logged in")
+			c.loggedIn = true
+			return nil
+		}
+		break
+	}
+
+	stepTimeout, ok := timeoutUntil(deadline, constants.SerialConsoleLoginTimeout)
+	if !ok {
+		return fmt.Errorf("could not log in as %v: %w", user, ErrTimeout)
+	}
+
+	log.Logger().Debug("logging in to serial console", zap.String("user", user))
+	if err := c.write(user + "\n"); err != nil {
+		return err
+	}
+
+	matched, err := c.expect(stepTimeout, passwordPrompt, c.prompt)
+	if err != nil {
+		return fmt.Errorf("could not log in as %v: %w", user, err)
+	}
+
+	if matched == 0 {
+		if err := c.write(password + "\n"); err != nil {
+			return err
+		}
+
+		matched, err = c.expect(stepTimeout, loginIncorrect, c.prompt)
+		if err != nil {
+			return fmt.Errorf("could not log in as %v: %w", user, err)
+		}
+		if matched == 0 {
+			return fmt.Errorf("could not log in as %v: login incorrect", user)
+		}
+	}
+
+	c.loggedIn = true
+	return nil
+}
+
+// Run executes the script with the shell of the user and writes its output to stdout.
+// The script is stored in a temporary file in the VM and its output and exit code are
+// found by unique markers, so the echo of the typed commands and prompts are not part of the output.
+// When the timeout expires, the script is interrupted and ErrTimeout is returned.
+func (c *Console) Run(script string, stdout io.Writer, timeout time.Duration) (int, error) {
+	token, err := newToken()
+	if err != nil {
+		return -1, err
+	}
+
+	if !strings.HasSuffix(script, "\n") {
+		script += "\n"
+	}
+
+	scriptFile := fmt.Sprintf("/tmp/execute-in-vm-%v.sh", token)
+	eof := "EOF_" + token
+
+	// markers are printed in two parts, so the echoed command does not match them
+	command := fmt.Sprintf("cat > %v <<'%v'\n%v%v\n", scriptFile, eof, script, eof) +
+		fmt.Sprintf("printf '%%s_%%s\\n' %v %v; ${SHELL:-sh} %v; execute_in_vm_rc=$?; rm -f %v; printf '%%s_%%s:%%s\\n' %v %v \"$execute_in_vm_rc\"\n",
+			startMarker, token, scriptFile, scriptFile, endMarker, token)
+
+	start := regexp.MustCompile(regexp.QuoteMeta(startMarker+"_"+token) + `\n`)
+	end := regexp.MustCompile(regexp.QuoteMeta(endMarker+"_"+token) + `:(\d+)\n`)
+
+	var timeoutCh <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutCh = timer.C
+	}
+
+	if err := c.write(command); err != nil {
+		return -1, err
+	}
+
+	if _, err := c.expectUntil(timeoutCh, start); err != nil {
+		return -1, c.interruptOnTimeout(err)
+	}
+
+	// keeps a possible beginning of the end marker in the buffer
+	keep := len(endMarker) + 1 + len(token) + maxExitCodeLength
+	for {
+		if loc := end.FindSubmatchIndex(c.buffer); loc != nil {
+			if _, err := stdout.Write(c.buffer[:loc[0]]); err != nil {
+				return -1, err
+			}
+			exitCode, err := strconv.Atoi(string(c.buffer[loc[2]:loc[3]]))
+			if err != nil {
+				return -1, err
+			}
+			c.buffer = c.buffer[loc[1]:]
+			return exitCode, nil
+		}
+
+		if len(c.buffer) > keep {
+			if _, err := stdout.Write(c.buffer[:len(c.buffer)-keep]); err != nil {
+				return -1, err
+			}
+			c.buffer = append([]byte(nil), c.buffer[len(c.buffer)-keep:]...)
+		}
+
+		if err := c.receive(timeoutCh); err != nil {
+			// the end marker will not come anymore
+			stdout.Write(c.buffer)
+			c.buffer = nil
+			return -1, c.interruptOnTimeout(err)
+		}
+	}
+}
+
+// Close logs out of the shell and closes the connection
+func (c *Console) Close() error {
+	if c.loggedIn {
+		if err := c.write("exit\n"); err != nil {
+			log.Logger().Debug("could not log out of serial console", zap.Error(err))
+		}
+		c.loggedIn = false
+	}
+
+	err := c.conn.Close()
+	// unblock the reader
+	go func() {
+		for range c.chunks {
+		}
+	}()
+	return err
+}
+
+func (c *Console) interruptOnTimeout(err error) error {
+	if errors.Is(err, ErrTimeout) {
+		if writeErr := c.write(interrupt); writeErr != nil {
+			log.Logger().Debug("could not interrupt the script", zap.Error(writeErr))
+		}
+	}
+	return err
+}
+
+func (c *Console) write(data string) error {
+	_, err := io.WriteString(c.conn, data)
+	return err
+}
+
+func (c *Console) read() {
+	for {
+		chunk := make([]byte, readBufferSize)
+		n, err := c.conn.Read(chunk)
+		if n > 0 {
+			c.chunks <- chunk[:n]
+		}
+		if err != nil {
+			c.readErr = err
+			close(c.chunks)
+			return
+		}
+	}
+}
+
+// receive appends the next output of the console to the buffer
+func (c *Console) receive(timeoutCh <-chan time.Time) error {
+	select {
+	case chunk, ok := <-c.chunks:
+		if !ok {
+			return fmt.Errorf("serial console was closed: %v", c.readErr)
+		}
+		c.buffer = append(c.buffer, bytes.ReplaceAll(chunk, []byte("\r"), nil)...)
+		return nil
+	case <-timeoutCh:
+		return ErrTimeout
+	}
+}
+
+func (c *Console) expect(timeout time.Duration, patterns ...*regexp.Regexp) (int, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	return c.expectUntil(timer.C, patterns...)
+}
+
+// expectUntil waits for output matching one of the patterns and returns the index of the first matching pattern.
+// The buffer is consumed up to the end of the match.
+func (c *Console) expectUntil(timeoutCh <-chan time.Time, patterns ...*regexp.Regexp) (int, error) {
+	for {
+		for i, pattern := range patterns {
+			if loc := pattern.FindIndex(c.buffer); loc != nil {
+				c.buffer = c.buffer[loc[1]:]
+				return i, nil
+			}
+		}
+
+		if len(c.buffer) > maxBufferSize {
+			c.buffer = append([]byte(nil), c.buffer[len(c.buffer)-maxBufferSize:]...)
+		}
+
+		if err := c.receive(timeoutCh); err != nil {
+			return -1, err
+		}
+	}
+}
+
+// timeoutUntil returns the time left until the deadline, which is at most maxTimeout
+func timeoutUntil(deadline time.Time, maxTimeout time.Duration) (time.Duration, bool) {
+	if deadline.IsZero() {
+		return maxTimeout, true
+	}
+
+	left := time.Until(deadline)
+	if left <= 0 {
+		return 0, false
+	}
+	if left < maxTimeout {
+		return left, true
+	}
+	return maxTimeout, true
+}
+
+func newToken() (string, error) {
+	token := make([]byte, 8)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
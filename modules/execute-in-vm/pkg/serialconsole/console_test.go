@@ -0,0 +1,130 @@
+package serialconsole_test
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/serialconsole"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const defaultPrompt = `[$#>]\s*$`
+
+var _ = Describe("Console", func() {
+	var vm *fakeConsole
+
+	BeforeEach(func() {
+		vm = &fakeConsole{
+			user:     "fedora",
+			password: "fedora-password",
+			prompt:   "[fedora@vm ~]$ ",
+			homeDir:  GinkgoT().TempDir(),
+		}
+	})
+
+	newConsole := func(prompt string) *serialconsole.Console {
+		return serialconsole.NewConsole(vm.start(), regexp.MustCompile(prompt))
+	}
+
+	DescribeTable("runs scripts", func(script, expectedOutput string, expectedExitCode int) {
+		console := newConsole(defaultPrompt)
+		defer console.Close()
+
+		Expect(console.Login("fedora", "fedora-password", 10*time.Second)).To(Succeed())
+
+		var output bytes.Buffer
+		exitCode, err := console.Run(script, &output, 10*time.Second)
+		Expect(err).Should(Succeed())
+		Expect(exitCode).To(Equal(expectedExitCode))
+		Expect(output.String()).To(Equal(expectedOutput))
+	},
+		Entry("simple command", "echo hello world", "hello world\n", 0),
+		Entry("multi line script", "#!/bin/sh\nfor i in 1 2 3; do\n  echo \"line $i\"\ndone\n", "line 1\nline 2\nline 3\n", 0),
+		Entry("output without a new line", "printf done", "done", 0),
+		Entry("stderr and exit code", "echo failed >&2\nexit 3", "failed\n", 3),
+		Entry("output which looks like a marker", "echo EXECUTE_IN_VM_END_0:0", "EXECUTE_IN_VM_END_0:0\n", 0),
+		Entry("quotes and heredoc", "cat <<'EOF'\n'$HOME' \"quoted\"\nEOF", "'$HOME' \"quoted\"\n", 0),
+	)
+
+	It("runs multiple scripts in one session", func() {
+		console := newConsole(defaultPrompt)
+		defer console.Close()
+
+		Expect(console.Login("fedora", "fedora-password", 10*time.Second)).To(Succeed())
+
+		for _, script := range []string{"touch created", "ls created"} {
+			var output bytes.Buffer
+			exitCode, err := console.Run(script, &output, 10*time.Second)
+			Expect(err).Should(Succeed())
+			Expect(exitCode).To(Equal(0))
+		}
+	})
+
+	It("does not log in when the shell is already logged in", func() {
+		vm.loggedIn = true
+		console := newConsole(defaultPrompt)
+		defer console.Close()
+
+		Expect(console.Login("root", "wrong", 10*time.Second)).To(Succeed())
+
+		var output bytes.Buffer
+		_, err := console.Run("echo logged in", &output, 10*time.Second)
+		Expect(err).Should(Succeed())
+		Expect(output.String()).To(Equal("logged in\n"))
+	})
+
+	It("detects a custom prompt", func() {
+		vm.prompt = "vm % "
+		console := newConsole(`%\s*$`)
+		defer console.Close()
+
+		Expect(console.Login("fedora", "fedora-password", 10*time.Second)).To(Succeed())
+	})
+
+	It("fails with a wrong password", func() {
+		console := newConsole(defaultPrompt)
+		defer console.Close()
+
+		err := console.Login("fedora", "wrong", 10*time.Second)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(Equal("could not log in as fedora: login incorrect"))
+	})
+
+	It("fails when the prompt is not found", func() {
+		vm.prompt = "vm % "
+		console := newConsole(defaultPrompt)
+		defer console.Close()
+
+		err := console.Login("fedora", "fedora-password", time.Second)
+		Expect(err).Should(HaveOccurred())
+		Expect(errors.Is(err, serialconsole.ErrTimeout)).To(BeTrue())
+	})
+
+	It("times out a long running script", func() {
+		console := newConsole(defaultPrompt)
+		defer console.Close()
+
+		Expect(console.Login("fedora", "fedora-password", 10*time.Second)).To(Succeed())
+
+		var output bytes.Buffer
+		_, err := console.Run("echo started\nsleep 3", &output, time.Second)
+		Expect(err).To(Equal(serialconsole.ErrTimeout))
+		Expect(output.String()).To(ContainSubstring("started"))
+	})
+
+	It("fails when the console is closed", func() {
+		console := newConsole(defaultPrompt)
+		defer console.Close()
+
+		Expect(console.Login("fedora", "fedora-password", 10*time.Second)).To(Succeed())
+
+		// kills the login shell
+		var output bytes.Buffer
+		_, err := console.Run("kill -9 $PPID", &output, 10*time.Second)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("serial console was closed"))
+	})
+})
@@ -0,0 +1,95 @@
+package serialconsole_test
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// fakeConsole simulates a serial console of a VM with a login prompt followed by a local interactive shell
+type fakeConsole struct {
+	user     string
+	password string
+	prompt   string
+	homeDir  string
+	loggedIn bool
+}
+
+// crlfWriter converts new lines like a terminal does
+type crlfWriter struct {
+	writer io.Writer
+}
+
+func (c *crlfWriter) Write(data []byte) (int, error) {
+	if _, err := io.WriteString(c.writer, strings.ReplaceAll(string(data), "\n", "\r\n")); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// start returns the client side of the console
+func (f *fakeConsole) start() net.Conn {
+	client, server := net.Pipe()
+	go f.serve(server)
+	return client
+}
+
+func (f *fakeConsole) serve(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	out := &crlfWriter{writer: conn}
+
+	if _, err := io.WriteString(out, "[    0.000000] Linux version 6.0.7-301.fc37.x86_64\n"); err != nil {
+		return
+	}
+
+	for !f.loggedIn {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		user := strings.TrimSpace(line)
+		if user == "" {
+			io.WriteString(out, "\nvm login: ")
+			continue
+		}
+
+		io.WriteString(out, user+"\nPassword: ")
+		password, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		if user == f.user && strings.TrimSpace(password) == f.password {
+			f.loggedIn = true
+			io.WriteString(out, "\nLast login: Tue Oct 18 10:00:00 on ttyS0\n")
+		} else {
+			io.WriteString(out, "\nLogin incorrect\nvm login: ")
+		}
+	}
+
+	cmd := exec.Command("sh", "-i")
+	cmd.Dir = f.homeDir
+	cmd.Env = []string{"PS1=" + f.prompt, "SHELL=sh", "HOME=" + f.homeDir, "PATH=" + os.Getenv("PATH")}
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	// the console is closed when the shell exits, even if the stdin is still open
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return
+	}
+	go func() {
+		io.Copy(stdin, reader)
+		stdin.Close()
+	}()
+
+	if err := cmd.Start(); err != nil {
+		return
+	}
+	cmd.Wait()
+}
@@ -0,0 +1,18 @@
+package serialconsole_test
+
+import (
+	"testing"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/utilstest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSerialConsole(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "SerialConsole Suite")
+}
+
+var _ = BeforeSuite(utilstest.SetupTestSuite)
+var _ = AfterSuite(utilstest.TearDownSuite)
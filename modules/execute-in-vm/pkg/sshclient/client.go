@@ -0,0 +1,150 @@
+package sshclient
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/utils/log"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	keepAliveRequest = "keepalive@openssh.com"
+	bannerPrefix     = "SSH-"
+)
+
+type Config struct {
+	User                string
+	PrivateKey          string
+	HostPublicKey       string
+	HostKeyCheckingMode string
+	HandshakeTimeout    time.Duration
+	// KeepAliveInterval of zero disables keepalives
+	KeepAliveInterval time.Duration
+	KeepAliveCountMax int
+}
+
+// Dial connects to the ssh server and authenticates with the private key.
+// The connection is closed when the server does not answer KeepAliveCountMax keepalives in a row.
+func Dial(address string, config *Config) (*ssh.Client, error) {
+	conn, err := net.DialTimeout("tcp", address, config.HandshakeTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(conn, address, config)
+}
+
+// NewClient establishes an ssh connection over an already opened connection, which is closed on failure
+func NewClient(conn net.Conn, address string, config *Config) (*ssh.Client, error) {
+	signer, err := NewSigner(config.PrivateKey)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	hostKeyCallback, hostKeyAlgorithms, err := NewHostKeyCallback(config.HostKeyCheckingMode, config.HostPublicKey)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	clientConfig := &ssh.ClientConfig{
+		User:              config.User,
+		Auth:              []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback:   hostKeyCallback,
+		HostKeyAlgorithms: hostKeyAlgorithms,
+		Timeout:           config.HandshakeTimeout,
+	}
+
+	// ssh.ClientConfig.Timeout applies only to the tcp connection
+	if config.HandshakeTimeout > 0 {
+		if err := conn.SetDeadline(time.Now().Add(config.HandshakeTimeout)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	clientConn, channels, requests, err := ssh.NewClientConn(conn, address, clientConfig)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		clientConn.Close()
+		return nil, err
+	}
+
+	client := ssh.NewClient(clientConn, channels, requests)
+	if config.KeepAliveInterval > 0 {
+		go keepAlive(client, config.KeepAliveInterval, config.KeepAliveCountMax)
+	}
+
+	return client, nil
+}
+
+// WaitForBanner checks that an ssh server is listening on the other side of the connection by reading its identification string
+func WaitForBanner(conn net.Conn, timeout time.Duration) error {
+	if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+
+	banner := make([]byte, len(bannerPrefix))
+	if _, err := io.ReadFull(conn, banner); err != nil {
+		return err
+	}
+	if string(banner) != bannerPrefix {
+		return fmt.Errorf("unexpected ssh banner %q", banner)
+	}
+	return nil
+}
+
+func keepAlive(client *ssh.Client, interval time.Duration, countMax int) {
+	if countMax < 1 {
+		countMax = 1
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		client.Wait()
+		close(closed)
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	missed := 0
+	for {
+		select {
+		case <-closed:
+			return
+		case <-ticker.C:
+		}
+
+		reply := make(chan error, 1)
+		go func() {
+			_, _, err := client.SendRequest(keepAliveRequest, true, nil)
+			reply <- err
+		}()
+
+		select {
+		case <-closed:
+			return
+		case err := <-reply:
+			if err != nil {
+				return
+			}
+			missed = 0
+		case <-time.After(interval):
+			missed++
+			if missed >= countMax {
+				log.Logger().Debug("ssh server is not responding, closing the connection", zap.Int("missedKeepAlives", missed))
+				client.Close()
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package sshclient
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	HostKeyCheckingYes       = "yes"
+	HostKeyCheckingAcceptNew = "accept-new"
+	HostKeyCheckingNo        = "no"
+
+	revokedMarker       = "revoked"
+	certAuthorityMarker = "cert-authority"
+)
+
+type hostKeyVerifier struct {
+	mode        string
+	knownKeys   []ssh.PublicKey
+	revokedKeys []ssh.PublicKey
+
+	lock sync.Mutex
+}
+
+// NewHostKeyCallback returns a callback verifying host keys in the given mode.
+// hostPublicKeys can contain keys in an authorized_keys or known_hosts format, one per line.
+// Host patterns of known_hosts lines are ignored, because the address of the VM is not known in advance.
+// The callback also returns host key algorithms the client should negotiate to receive one of the known keys.
+func NewHostKeyCallback(mode, hostPublicKeys string) (ssh.HostKeyCallback, []string, error) {
+	switch mode {
+	case HostKeyCheckingYes, HostKeyCheckingAcceptNew, HostKeyCheckingNo:
+	default:
+		return nil, nil, fmt.Errorf("unsupported host key checking mode %v", mode)
+	}
+
+	verifier := &hostKeyVerifier{mode: mode}
+	if err := verifier.parseKeys(hostPublicKeys); err != nil {
+		return nil, nil, err
+	}
+
+	if mode == HostKeyCheckingYes && len(verifier.knownKeys) == 0 {
+		return nil, nil, errors.New("host key verification requires at least one host public key")
+	}
+
+	return verifier.verify, verifier.hostKeyAlgorithms(), nil
+}
+
+func (v *hostKeyVerifier) parseKeys(hostPublicKeys string) error {
+	for _, line := range strings.Split(hostPublicKeys, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(line)); err == nil {
+			v.knownKeys = append(v.knownKeys, key)
+			continue
+		}
+
+		marker, _, key, _, _, err := ssh.ParseKnownHosts([]byte(line))
+		if err != nil {
+			return fmt.Errorf("could not parse host public key: %v", err.Error())
+		}
+
+		switch marker {
+		case revokedMarker:
+			v.revokedKeys = append(v.revokedKeys, key)
+		case certAuthorityMarker:
+			return errors.New("host certificate authorities are not supported")
+		default:
+			v.knownKeys = append(v.knownKeys, key)
+		}
+	}
+	return nil
+}
+
+func (v *hostKeyVerifier) verify(hostname string, _ net.Addr, key ssh.PublicKey) error {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if containsKey(v.revokedKeys, key) {
+		return fmt.Errorf("host key %v of %v is revoked", ssh.FingerprintSHA256(key), hostname)
+	}
+
+	if containsKey(v.knownKeys, key) {
+		return nil
+	}
+
+	switch v.mode {
+	case HostKeyCheckingNo:
+		return nil
+	case HostKeyCheckingAcceptNew:
+		if len(v.knownKeys) == 0 {
+			// pin the first key so reconnections are verified
+			v.knownKeys = append(v.knownKeys, key)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("host key verification failed: unknown host key %v of %v", ssh.FingerprintSHA256(key), hostname)
+}
+
+func (v *hostKeyVerifier) hostKeyAlgorithms() []string {
+	if len(v.knownKeys) == 0 || v.mode == HostKeyCheckingNo {
+		// use defaults
+		return nil
+	}
+
+	var algorithms []string
+	seen := map[string]bool{}
+	for _, key := range v.knownKeys {
+		for _, algorithm := range keyAlgorithms(key.Type()) {
+			if !seen[algorithm] {
+				seen[algorithm] = true
+				algorithms = append(algorithms, algorithm)
+			}
+		}
+	}
+	return algorithms
+}
+
+// keyAlgorithms returns algorithms of a key type in the order of preference
+func keyAlgorithms(keyType string) []string {
+	switch keyType {
+	case ssh.KeyAlgoRSA:
+		return []string{ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA}
+	case ssh.CertAlgoRSAv01:
+		return []string{ssh.CertAlgoRSASHA512v01, ssh.CertAlgoRSASHA256v01, ssh.CertAlgoRSAv01}
+	default:
+		return []string{keyType}
+	}
+}
+
+func containsKey(keys []ssh.PublicKey, key ssh.PublicKey) bool {
+	for _, k := range keys {
+		if k.Type() == key.Type() && bytes.Equal(k.Marshal(), key.Marshal()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,21 @@
+package sshclient
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// NewSigner parses a private key in PEM or OpenSSH format
+func NewSigner(privateKey string) (ssh.Signer, error) {
+	signer, err := ssh.ParsePrivateKey([]byte(privateKey))
+	if err != nil {
+		var passphraseMissingErr *ssh.PassphraseMissingError
+		if errors.As(err, &passphraseMissingErr) {
+			return nil, errors.New("could not parse private key: private keys protected by a passphrase are not supported")
+		}
+		return nil, fmt.Errorf("could not parse private key: %v", err.Error())
+	}
+	return signer, nil
+}
@@ -0,0 +1,211 @@
+package sshclient_test
+
+import (
+	"bytes"
+	"net"
+	"time"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/sshclient"
+	. "github.com/kubevirt/kubevirt-tekton-tasks/modules/sharedtest/testconstants"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/ssh"
+)
+
+var _ = Describe("SSHClient", func() {
+	parsePublicKey := func(publicKey string) ssh.PublicKey {
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey))
+		Expect(err).Should(Succeed())
+		return key
+	}
+
+	DescribeTable("NewSigner", func(privateKey, expectedErrMessage string) {
+		signer, err := sshclient.NewSigner(privateKey)
+		if expectedErrMessage == "" {
+			Expect(err).Should(Succeed())
+			Expect(signer.PublicKey().Marshal()).To(Equal(parsePublicKey(SSHTestPublicKey).Marshal()))
+		} else {
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(expectedErrMessage))
+		}
+	},
+		Entry("valid key", SSHTestPrivateKey, ""),
+		Entry("valid key without last new line", SSHTestPrivateKeyWithoutLastNewLine, ""),
+		Entry("invalid key", "invalid", "could not parse private key"),
+	)
+
+	DescribeTable("NewHostKeyCallback fails", func(mode, hostPublicKeys, expectedErrMessage string) {
+		_, _, err := sshclient.NewHostKeyCallback(mode, hostPublicKeys)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(expectedErrMessage))
+	},
+		Entry("invalid mode", "ask", SSHTestPublicKey, "unsupported host key checking mode ask"),
+		Entry("yes without keys", "yes", "", "host key verification requires at least one host public key"),
+		Entry("invalid key", "yes", "ssh-rsa invalid", "could not parse host public key"),
+		Entry("cert authority", "yes", "@cert-authority * "+SSHTestPublicKey, "host certificate authorities are not supported"),
+	)
+
+	DescribeTable("host key verification", func(mode, hostPublicKeys string, presentedKeys []string, expectedErrMessage string, expectedAlgorithms []string) {
+		callback, algorithms, err := sshclient.NewHostKeyCallback(mode, hostPublicKeys)
+		Expect(err).Should(Succeed())
+		Expect(algorithms).To(Equal(expectedAlgorithms))
+
+		for i, presentedKey := range presentedKeys {
+			err = callback("10.0.0.1:22", nil, parsePublicKey(presentedKey))
+			if i < len(presentedKeys)-1 {
+				Expect(err).Should(Succeed())
+			}
+		}
+
+		if expectedErrMessage == "" {
+			Expect(err).Should(Succeed())
+		} else {
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(expectedErrMessage))
+		}
+	},
+		Entry("yes with known key", "yes", SSHTestPublicKey, []string{SSHTestPublicKey}, "",
+			[]string{"rsa-sha2-512", "rsa-sha2-256", "ssh-rsa"}),
+		Entry("yes with known key in known_hosts format", "yes", "10.0.0.1 "+SSHTestPublicKey, []string{SSHTestPublicKey}, "",
+			[]string{"rsa-sha2-512", "rsa-sha2-256", "ssh-rsa"}),
+		Entry("yes with one of known keys", "yes", SSHTestPublicKey2+"\n# comment\n"+SSHTestPublicKey, []string{SSHTestPublicKey}, "",
+			[]string{"rsa-sha2-512", "rsa-sha2-256", "ssh-rsa"}),
+		Entry("yes with unknown key", "yes", SSHTestPublicKey, []string{SSHTestPublicKey2}, "host key verification failed: unknown host key",
+			[]string{"rsa-sha2-512", "rsa-sha2-256", "ssh-rsa"}),
+		Entry("accept-new without keys", "accept-new", "", []string{SSHTestPublicKey}, "", nil),
+		Entry("accept-new pins the first key", "accept-new", "", []string{SSHTestPublicKey, SSHTestPublicKey}, "", nil),
+		Entry("accept-new rejects changed key", "accept-new", "", []string{SSHTestPublicKey, SSHTestPublicKey2}, "host key verification failed: unknown host key", nil),
+		Entry("accept-new with unknown key", "accept-new", SSHTestPublicKey, []string{SSHTestPublicKey2}, "host key verification failed: unknown host key",
+			[]string{"rsa-sha2-512", "rsa-sha2-256", "ssh-rsa"}),
+		Entry("no with unknown key", "no", SSHTestPublicKey, []string{SSHTestPublicKey2}, "", nil),
+		Entry("no with revoked key", "no", "@revoked * "+SSHTestPublicKey2, []string{SSHTestPublicKey2}, "is revoked", nil),
+	)
+
+	Describe("Dial", func() {
+		var listener net.Listener
+
+		BeforeEach(func() {
+			var err error
+			listener, err = net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).Should(Succeed())
+			go serveSSH(listener, SSHTestPrivateKey2, SSHTestPublicKey)
+		})
+
+		AfterEach(func() {
+			listener.Close()
+		})
+
+		DescribeTable("connects to the server", func(config *sshclient.Config, expectedErrMessage string) {
+			config.User = "fedora"
+			config.HandshakeTimeout = 10 * time.Second
+
+			client, err := sshclient.Dial(listener.Addr().String(), config)
+			if expectedErrMessage == "" {
+				Expect(err).Should(Succeed())
+				Expect(client.User()).To(Equal("fedora"))
+				Expect(client.Close()).To(Succeed())
+			} else {
+				Expect(err).Should(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(expectedErrMessage))
+			}
+		},
+			Entry("with known host key", &sshclient.Config{
+				PrivateKey:          SSHTestPrivateKey,
+				HostPublicKey:       SSHTestPublicKey2,
+				HostKeyCheckingMode: "yes",
+				KeepAliveInterval:   time.Second,
+				KeepAliveCountMax:   3,
+			}, ""),
+			Entry("with accept-new", &sshclient.Config{
+				PrivateKey:          SSHTestPrivateKey,
+				HostKeyCheckingMode: "accept-new",
+			}, ""),
+			Entry("with unknown host key", &sshclient.Config{
+				PrivateKey:          SSHTestPrivateKey,
+				HostPublicKey:       SSHTestPublicKey,
+				HostKeyCheckingMode: "yes",
+			}, "handshake failed"),
+			Entry("with unauthorized key", &sshclient.Config{
+				PrivateKey:          SSHTestPrivateKey2,
+				HostPublicKey:       SSHTestPublicKey2,
+				HostKeyCheckingMode: "yes",
+			}, "unable to authenticate"),
+		)
+
+		It("connects over an opened connection", func() {
+			conn, err := net.Dial("tcp", listener.Addr().String())
+			Expect(err).Should(Succeed())
+			client, err := sshclient.NewClient(conn, "vmi/vm.default:22", &sshclient.Config{
+				User:                "fedora",
+				PrivateKey:          SSHTestPrivateKey,
+				HostPublicKey:       SSHTestPublicKey2,
+				HostKeyCheckingMode: "yes",
+				HandshakeTimeout:    10 * time.Second,
+			})
+			Expect(err).Should(Succeed())
+			Expect(client.Close()).To(Succeed())
+		})
+	})
+
+	DescribeTable("WaitForBanner", func(banner string, expectedErrMessage string) {
+		server, client := net.Pipe()
+		defer client.Close()
+		go func() {
+			server.Write([]byte(banner))
+			server.Close()
+		}()
+
+		err := sshclient.WaitForBanner(client, time.Second)
+		if expectedErrMessage == "" {
+			Expect(err).Should(Succeed())
+		} else {
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(expectedErrMessage))
+		}
+	},
+		Entry("ssh server", "SSH-2.0-OpenSSH_8.7\r\n", ""),
+		Entry("other server", "HTTP/1.1 400 Bad Request\r\n", "unexpected ssh banner"),
+		Entry("closed connection", "", "EOF"),
+	)
+})
+
+// serveSSH accepts connections authenticated with the authorized key and rejects all channels
+func serveSSH(listener net.Listener, hostPrivateKey, authorizedKey string) {
+	hostKey, err := ssh.ParsePrivateKey([]byte(hostPrivateKey))
+	if err != nil {
+		panic(err)
+	}
+	authorized, _, _, _, err := ssh.ParseAuthorizedKey([]byte(authorizedKey))
+	if err != nil {
+		panic(err)
+	}
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if bytes.Equal(key.Marshal(), authorized.Marshal()) {
+				return nil, nil
+			}
+			return nil, ssh.ErrNoAuth
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			serverConn, channels, requests, err := ssh.NewServerConn(conn, config)
+			if err != nil {
+				conn.Close()
+				return
+			}
+			defer serverConn.Close()
+			go ssh.DiscardRequests(requests)
+			for channel := range channels {
+				channel.Reject(ssh.Prohibited, "channels are not supported")
+			}
+		}()
+	}
+}
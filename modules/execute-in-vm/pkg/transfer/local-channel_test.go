@@ -0,0 +1,87 @@
+package transfer_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/transfer"
+	"github.com/pkg/sftp"
+)
+
+// localChannel runs commands and an sftp server in a local directory which simulates the home directory of a VM
+type localChannel struct {
+	homeDir string
+	sftp    bool
+}
+
+func (l *localChannel) OpenSFTP() (*transfer.Stream, error) {
+	if !l.sftp {
+		return nil, errors.New("subsystem request failed")
+	}
+
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	server, err := sftp.NewServer(&pipeConn{serverReader, serverWriter}, sftp.WithServerWorkingDirectory(l.homeDir))
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		err := server.Serve()
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+		done <- err
+		server.Close()
+	}()
+
+	return &transfer.Stream{
+		Reader: clientReader,
+		Writer: clientWriter,
+		Close: func() error {
+			clientWriter.Close()
+			return <-done
+		},
+	}, nil
+}
+
+func (l *localChannel) OpenCommand(command string) (*transfer.Stream, error) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = l.homeDir
+	cmd.Stderr = os.Stderr
+
+	writer, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	reader, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	return &transfer.Stream{
+		Reader: reader,
+		Writer: writer,
+		Close: func() error {
+			writer.Close()
+			return cmd.Wait()
+		},
+	}, nil
+}
+
+type pipeConn struct {
+	*io.PipeReader
+	*io.PipeWriter
+}
+
+func (p *pipeConn) Close() error {
+	p.PipeReader.Close()
+	return p.PipeWriter.Close()
+}
@@ -0,0 +1,215 @@
+package transfer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// the legacy scp protocol talks to a remote scp command in a sink (-t) or a source (-f) mode
+
+const (
+	scpOK      = 0
+	scpWarning = 1
+	scpError   = 2
+)
+
+// scpSend sends a local file or directory to the remote scp sink under the name
+func scpSend(reader *bufio.Reader, writer io.Writer, localPath, name string) error {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return err
+	}
+	if strings.ContainsAny(name, "\n/") {
+		return fmt.Errorf("scp: unsupported file name %q", name)
+	}
+
+	if info.IsDir() {
+		if _, err := fmt.Fprintf(writer, "D%04o 0 %v\n", info.Mode().Perm(), name); err != nil {
+			return err
+		}
+		if err := scpReadAck(reader); err != nil {
+			return err
+		}
+
+		entries, err := os.ReadDir(localPath)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := scpSend(reader, writer, filepath.Join(localPath, entry.Name()), entry.Name()); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprint(writer, "E\n"); err != nil {
+			return err
+		}
+		return scpReadAck(reader)
+	}
+
+	if !info.Mode().IsRegular() {
+		// skip sockets, devices, etc.
+		return nil
+	}
+
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := fmt.Fprintf(writer, "C%04o %v %v\n", info.Mode().Perm(), info.Size(), name); err != nil {
+		return err
+	}
+	if err := scpReadAck(reader); err != nil {
+		return err
+	}
+	if _, err := io.CopyN(writer, file, info.Size()); err != nil {
+		return err
+	}
+	if _, err := writer.Write([]byte{scpOK}); err != nil {
+		return err
+	}
+	return scpReadAck(reader)
+}
+
+// scpReceive receives files from the remote scp source into the local directory
+func scpReceive(reader *bufio.Reader, writer io.Writer, localDir string) error {
+	dirs := []string{localDir}
+	var warnings []string
+
+	ack := func() error {
+		_, err := writer.Write([]byte{scpOK})
+		return err
+	}
+
+	if err := ack(); err != nil {
+		return err
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err == io.EOF && line == "" {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return errors.New("scp: unexpected empty message")
+		}
+
+		switch line[0] {
+		case scpWarning:
+			warnings = append(warnings, line[1:])
+			continue
+		case scpError:
+			return errors.New(line[1:])
+		case 'T':
+			// modification times are not preserved
+		case 'E':
+			if len(dirs) == 1 {
+				return errors.New("scp: unexpected end of directory")
+			}
+			dirs = dirs[:len(dirs)-1]
+		case 'C', 'D':
+			perm, size, name, err := parseSCPRecord(line[1:])
+			if err != nil {
+				return err
+			}
+			target := filepath.Join(dirs[len(dirs)-1], name)
+
+			if line[0] == 'D' {
+				if err := os.MkdirAll(target, perm|0700); err != nil {
+					return err
+				}
+				dirs = append(dirs, target)
+				break
+			}
+
+			if err := ack(); err != nil {
+				return err
+			}
+			if err := scpReceiveFile(reader, target, perm, size); err != nil {
+				return err
+			}
+			if err := scpReadAck(reader); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("scp: unexpected message %q", line)
+		}
+
+		if err := ack(); err != nil {
+			return err
+		}
+	}
+
+	if len(warnings) > 0 {
+		return errors.New(strings.Join(warnings, "; "))
+	}
+	return nil
+}
+
+func scpReceiveFile(reader io.Reader, target string, perm os.FileMode, size int64) error {
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm|0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := io.CopyN(file, reader, size); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// parseSCPRecord parses a "<mode> <size> <name>" record of C and D messages
+func parseSCPRecord(record string) (os.FileMode, int64, string, error) {
+	fields := strings.SplitN(record, " ", 3)
+	if len(fields) != 3 {
+		return 0, 0, "", fmt.Errorf("scp: invalid record %q", record)
+	}
+
+	mode, err := strconv.ParseUint(fields[0], 8, 32)
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("scp: invalid mode in record %q", record)
+	}
+
+	size, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil || size < 0 {
+		return 0, 0, "", fmt.Errorf("scp: invalid size in record %q", record)
+	}
+
+	name := fields[2]
+	if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+		return 0, 0, "", fmt.Errorf("scp: invalid file name %q", name)
+	}
+
+	return os.FileMode(mode).Perm(), size, name, nil
+}
+
+func scpReadAck(reader *bufio.Reader) error {
+	code, err := reader.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	switch code {
+	case scpOK:
+		return nil
+	case scpWarning, scpError:
+		message, _ := reader.ReadString('\n')
+		return errors.New(strings.TrimSpace(message))
+	default:
+		return fmt.Errorf("scp: unexpected response %v", code)
+	}
+}
@@ -0,0 +1,344 @@
+package transfer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/utils/log"
+	"github.com/pkg/sftp"
+	"go.uber.org/zap"
+)
+
+const homeDirPrefix = "~/"
+
+// Stream is a connection to a remote command or subsystem
+type Stream struct {
+	Reader io.Reader
+	Writer io.WriteCloser
+	// Close closes the stream and waits for the remote side to finish
+	Close func() error
+}
+
+// Channel opens streams to a VM over the same connection secret
+type Channel interface {
+	OpenSFTP() (*Stream, error)
+	OpenCommand(command string) (*Stream, error)
+}
+
+// Copier copies files to and from a VM with SFTP. SCP is used when the SFTP subsystem is not available.
+// Relative remote paths are resolved against the home directory of the user.
+type Copier struct {
+	channel Channel
+
+	sftpStream      *Stream
+	sftp            *sftp.Client
+	sftpUnavailable bool
+}
+
+func NewCopier(channel Channel) *Copier {
+	return &Copier{channel: channel}
+}
+
+// Upload copies a local file or directory to the destination. The source is copied
+// into the destination when the destination ends with a slash or is empty.
+func (c *Copier) Upload(source, destination string) error {
+	if _, err := os.Stat(source); err != nil {
+		return err
+	}
+
+	destination = strings.TrimPrefix(destination, homeDirPrefix)
+	dir, name := path.Dir(destination), path.Base(destination)
+	if destination == "" || strings.HasSuffix(destination, "/") {
+		dir, name = path.Clean(destination+"."), filepath.Base(source)
+	}
+
+	log.Logger().Debug("uploading", zap.String("source", source), zap.String("dir", dir), zap.String("name", name))
+
+	if client := c.getSFTP(); client != nil {
+		if err := client.MkdirAll(dir); err != nil {
+			return err
+		}
+		return sftpUpload(client, source, path.Join(dir, name))
+	}
+
+	command := fmt.Sprintf("mkdir -p -- %v && scp -r -d -t -- %v", shellQuote(dir), shellQuote(dir))
+	return c.runSCP(command, func(reader *bufio.Reader, writer io.Writer) error {
+		if err := scpReadAck(reader); err != nil {
+			return err
+		}
+		return scpSend(reader, writer, source, name)
+	})
+}
+
+// Download copies files and directories matching the glob pattern into the destination directory
+func (c *Copier) Download(pattern, destination string) error {
+	if err := os.MkdirAll(destination, 0755); err != nil {
+		return err
+	}
+
+	pattern = strings.TrimPrefix(pattern, homeDirPrefix)
+	log.Logger().Debug("downloading", zap.String("pattern", pattern), zap.String("destination", destination))
+
+	if client := c.getSFTP(); client != nil {
+		matches, err := sftpGlob(client, pattern)
+		if err != nil {
+			return err
+		}
+		if len(matches) == 0 {
+			return fmt.Errorf("no files match %v", pattern)
+		}
+
+		for _, match := range matches {
+			if err := sftpDownload(client, match, filepath.Join(destination, path.Base(match.path))); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// the pattern is not quoted to be expanded by the remote shell
+	return c.runSCP("scp -r -f -- "+pattern, func(reader *bufio.Reader, writer io.Writer) error {
+		return scpReceive(reader, writer, destination)
+	})
+}
+
+func (c *Copier) Close() error {
+	if c.sftpStream != nil {
+		// closing the client ends the sftp session, so the stream can be waited for
+		_ = c.sftp.Close()
+		err := c.sftpStream.Close()
+		c.sftpStream, c.sftp = nil, nil
+		return err
+	}
+	return nil
+}
+
+func (c *Copier) getSFTP() *sftp.Client {
+	if c.sftp != nil || c.sftpUnavailable {
+		return c.sftp
+	}
+
+	stream, err := c.channel.OpenSFTP()
+	if err == nil {
+		c.sftp, err = sftp.NewClientPipe(stream.Reader, stream.Writer)
+		if err != nil {
+			stream.Close()
+		}
+	}
+
+	if err != nil {
+		log.Logger().Debug("sftp is not available, falling back to scp", zap.Error(err))
+		c.sftpUnavailable = true
+		return nil
+	}
+
+	c.sftpStream = stream
+	return c.sftp
+}
+
+func (c *Copier) runSCP(command string, transfer func(reader *bufio.Reader, writer io.Writer) error) error {
+	stream, err := c.channel.OpenCommand(command)
+	if err != nil {
+		return err
+	}
+
+	transferErr := transfer(bufio.NewReader(stream.Reader), stream.Writer)
+	stream.Writer.Close()
+	closeErr := stream.Close()
+
+	if transferErr != nil {
+		return fmt.Errorf("scp failed: %v", transferErr.Error())
+	}
+	if closeErr != nil {
+		return fmt.Errorf("scp failed: %v", closeErr.Error())
+	}
+	return nil
+}
+
+func sftpUpload(client *sftp.Client, source, target string) error {
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		if err := client.MkdirAll(target); err != nil {
+			return err
+		}
+
+		entries, err := os.ReadDir(source)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := sftpUpload(client, filepath.Join(source, entry.Name()), path.Join(target, entry.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if !info.Mode().IsRegular() {
+		// skip sockets, devices, etc.
+		return nil
+	}
+
+	file, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	remoteFile, err := client.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return fmt.Errorf("could not create %v: %v", target, err.Error())
+	}
+	defer remoteFile.Close()
+
+	if err := remoteFile.Chmod(info.Mode().Perm()); err != nil {
+		return fmt.Errorf("could not change mode of %v: %v", target, err.Error())
+	}
+	if _, err := remoteFile.ReadFrom(file); err != nil {
+		return fmt.Errorf("could not write %v: %v", target, err.Error())
+	}
+
+	return remoteFile.Close()
+}
+
+func sftpDownload(client *sftp.Client, source *remoteFileInfo, target string) error {
+	if source.IsDir() {
+		if err := os.MkdirAll(target, source.Mode().Perm()|0700); err != nil {
+			return err
+		}
+
+		entries, err := client.ReadDir(source.path)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			entryPath := path.Join(source.path, entry.Name())
+			if err := sftpDownload(client, &remoteFileInfo{entry, entryPath}, filepath.Join(target, entry.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if !source.Mode().IsRegular() {
+		// skip symlinks to directories, sockets, etc.
+		return nil
+	}
+
+	remoteFile, err := client.Open(source.path)
+	if err != nil {
+		return fmt.Errorf("could not open %v: %v", source.path, err.Error())
+	}
+	defer remoteFile.Close()
+
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, source.Mode().Perm()|0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := remoteFile.WriteTo(file); err != nil {
+		return fmt.Errorf("could not read %v: %v", source.path, err.Error())
+	}
+
+	return file.Sync()
+}
+
+// remoteFileInfo is a remote file with its path
+type remoteFileInfo struct {
+	os.FileInfo
+	path string
+}
+
+// sftpGlob returns files matching the pattern. Hidden files are matched only explicitly, as in a shell.
+func sftpGlob(client *sftp.Client, pattern string) ([]*remoteFileInfo, error) {
+	if !hasGlobMeta(pattern) {
+		info, err := client.Stat(pattern)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return []*remoteFileInfo{{info, pattern}}, nil
+	}
+
+	dir, filePattern := path.Split(pattern)
+	if _, err := path.Match(filePattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid pattern %v: %v", pattern, err.Error())
+	}
+
+	dir = cleanGlobDir(dir)
+	var dirs []string
+	if hasGlobMeta(dir) {
+		dirMatches, err := sftpGlob(client, dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, dirMatch := range dirMatches {
+			if dirMatch.IsDir() {
+				dirs = append(dirs, dirMatch.path)
+			}
+		}
+	} else {
+		dirs = []string{dir}
+	}
+
+	var matches []*remoteFileInfo
+	for _, d := range dirs {
+		entries, err := client.ReadDir(d)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), ".") && !strings.HasPrefix(filePattern, ".") {
+				continue
+			}
+			if matched, _ := path.Match(filePattern, entry.Name()); !matched {
+				continue
+			}
+
+			name := path.Join(d, entry.Name())
+			// follow symlinks
+			info, err := client.Stat(name)
+			if err != nil {
+				return nil, err
+			}
+			matches = append(matches, &remoteFileInfo{info, name})
+		}
+	}
+
+	return matches, nil
+}
+
+func cleanGlobDir(dir string) string {
+	switch {
+	case dir == "":
+		return "."
+	case dir == "/":
+		return dir
+	default:
+		return strings.TrimSuffix(dir, "/")
+	}
+}
+
+func hasGlobMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, `*?[\`)
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
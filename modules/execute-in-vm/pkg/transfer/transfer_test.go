@@ -0,0 +1,143 @@
+package transfer_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/transfer"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Copier", func() {
+	var workspaceDir, homeDir string
+
+	writeFiles := func(baseDir string, files map[string]string) {
+		for name, content := range files {
+			filename := filepath.Join(baseDir, name)
+			Expect(os.MkdirAll(filepath.Dir(filename), 0755)).To(Succeed())
+			Expect(os.WriteFile(filename, []byte(content), 0644)).To(Succeed())
+		}
+	}
+
+	expectFiles := func(baseDir string, files map[string]string) {
+		var found []string
+		Expect(filepath.Walk(baseDir, func(filename string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() {
+				relative, _ := filepath.Rel(baseDir, filename)
+				found = append(found, relative)
+			}
+			return err
+		})).To(Succeed())
+
+		var expected []string
+		for name, content := range files {
+			expected = append(expected, name)
+			data, err := os.ReadFile(filepath.Join(baseDir, name))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal(content))
+		}
+		Expect(found).To(ConsistOf(expected))
+	}
+
+	newCopier := func(sftp bool) *transfer.Copier {
+		if !sftp {
+			if _, err := exec.LookPath("scp"); err != nil {
+				Skip("scp is not available")
+			}
+		}
+		return transfer.NewCopier(&localChannel{homeDir: homeDir, sftp: sftp})
+	}
+
+	BeforeEach(func() {
+		workspaceDir = GinkgoT().TempDir()
+		homeDir = GinkgoT().TempDir()
+	})
+
+	for _, sftp := range []bool{true, false} {
+		sftp := sftp
+		protocol := "scp"
+		if sftp {
+			protocol = "sftp"
+		}
+
+		Context("with "+protocol, func() {
+			DescribeTable("Upload", func(source, destination string, expectedFiles map[string]string) {
+				writeFiles(workspaceDir, map[string]string{
+					"src/main.go":      "package main",
+					"src/pkg/utils.go": "package pkg",
+					"README.md":        "readme",
+				})
+
+				copier := newCopier(sftp)
+				Expect(copier.Upload(filepath.Join(workspaceDir, source), destination)).To(Succeed())
+				Expect(copier.Close()).To(Succeed())
+
+				expectFiles(homeDir, expectedFiles)
+			},
+				Entry("file to a path", "README.md", "docs/info.md", map[string]string{
+					"docs/info.md": "readme",
+				}),
+				Entry("file into a directory", "README.md", "docs/", map[string]string{
+					"docs/README.md": "readme",
+				}),
+				Entry("file into the home directory", "README.md", "", map[string]string{
+					"README.md": "readme",
+				}),
+				Entry("directory to a path", "src", "~/project/source", map[string]string{
+					"project/source/main.go":      "package main",
+					"project/source/pkg/utils.go": "package pkg",
+				}),
+				Entry("directory into a directory", "src", "project/", map[string]string{
+					"project/src/main.go":      "package main",
+					"project/src/pkg/utils.go": "package pkg",
+				}),
+			)
+
+			It("Upload fails for missing source", func() {
+				err := newCopier(sftp).Upload(filepath.Join(workspaceDir, "missing"), "")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("no such file or directory"))
+			})
+
+			DescribeTable("Download", func(pattern string, expectedFiles map[string]string) {
+				writeFiles(homeDir, map[string]string{
+					"reports/junit-a.xml":       "a",
+					"reports/junit-b.xml":       "b",
+					"reports/.junit-hidden.xml": "hidden",
+					"reports/coverage/out.txt":  "coverage",
+					"build.log":                 "log",
+				})
+
+				copier := newCopier(sftp)
+				Expect(copier.Download(pattern, filepath.Join(workspaceDir, "artifacts"))).To(Succeed())
+				Expect(copier.Close()).To(Succeed())
+
+				expectFiles(filepath.Join(workspaceDir, "artifacts"), expectedFiles)
+			},
+				Entry("file", "build.log", map[string]string{
+					"build.log": "log",
+				}),
+				Entry("glob pattern", "reports/*.xml", map[string]string{
+					"junit-a.xml": "a",
+					"junit-b.xml": "b",
+				}),
+				Entry("glob pattern in a directory", "~/*/coverage", map[string]string{
+					"coverage/out.txt": "coverage",
+				}),
+				Entry("directory", "reports", map[string]string{
+					"reports/junit-a.xml":       "a",
+					"reports/junit-b.xml":       "b",
+					"reports/.junit-hidden.xml": "hidden",
+					"reports/coverage/out.txt":  "coverage",
+				}),
+			)
+
+			It("Download fails when no files match", func() {
+				err := newCopier(sftp).Download("reports/*.xml", workspaceDir)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	}
+})
@@ -1,31 +1,68 @@
 package parse
 
 import (
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/constants"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zutils"
 	"go.uber.org/zap/zapcore"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 )
 
 const (
 	vmNameOptionName      = "vm-name"
+	vmSelectorOptionName  = "vm-selector"
+	parallelismOptionName = "parallelism"
+	toleranceOptionName   = "failure-tolerance"
 	vmNamespaceOptionName = "vm-namespace"
 	stopOptionName        = "stop"
 	deleteOptionName      = "delete"
 	commandOptionName     = "command"
 	commandArgsOptionName = "command-args"
 	scriptOptionName      = "script"
+	uploadOptionName      = "upload"
+	downloadOptionName    = "download"
+	workspaceOptionName   = "workspace-path"
+	connectionModeName    = "connection-mode"
+	stdoutPathOptionName  = "stdout-path"
+	stderrPathOptionName  = "stderr-path"
+	stdoutTailSizeName    = "stdout-tail-size"
 )
 
+// FileTransfer describes a file copied between the workspace and a VM
+type FileTransfer struct {
+	// Local is a path in the workspace: the source of an upload or the destination directory of a download
+	Local string
+	// Remote is a path in the VM: the destination of an upload or the glob pattern of a download
+	Remote string
+}
+
 type CLIOptions struct {
-	VirtualMachineName      string   `arg:"--vm-name,env:VM_NAME,required" placeholder:"NAME" help:"Name of a VM to execute the action in"`
+	VirtualMachineName      string   `arg:"--vm-name,env:VM_NAME" placeholder:"NAME" help:"Name of a VM to execute the action in"`
+	VirtualMachineSelector  string   `arg:"--vm-selector,env:VM_SELECTOR" placeholder:"SELECTOR" help:"Label selector of VMs to execute the action in (instead of vm-name)"`
+	Parallelism             string   `arg:"--parallelism,env:PARALLELISM" placeholder:"COUNT" help:"Maximum number of VMs matching vm-selector to execute the action in concurrently"`
+	FailureTolerance        string   `arg:"--failure-tolerance,env:FAILURE_TOLERANCE" placeholder:"COUNT|PERCENT%" help:"Number or percentage of VMs matching vm-selector which are allowed to fail"`
 	VirtualMachineNamespace string   `arg:"--vm-namespace,env:VM_NAMESPACE" placeholder:"NAMESPACE" help:"Namespace of a VM to execute the action in"`
 	Stop                    string   `arg:"--stop" placeholder:"true|false" help:"Stops the VM after executing the action"`
 	Delete                  string   `arg:"--delete" placeholder:"true|false" help:"Deletes the VM after executing the action"`
 	Timeout                 string   `arg:"--timeout" help:"Timeout for the command/script (includes potential VM start). The VM will be stoped or deleted accordingly once the timout expires. Should be in a 3h2m1s format."`
 	Script                  string   `arg:"--script,env:EXECUTE_SCRIPT" placeholder:"SCRIPT" help:"Script to execute in a VM (can be set by EXECUTE_SCRIPT env variable)"`
 	ConnectionSecretName    string   `arg:"--connectionSecretName,env:CONNECTION_SECRET_NAME" placeholder:"NAME" help:"Name of the connection secret (used only for validation)"`
+	ConnectionMode          string   `arg:"--connection-mode,env:CONNECTION_MODE" placeholder:"pod-network|port-forward" help:"How to reach a VM: over the pod network (default) or through the portforward subresource of the VMI"`
 	Debug                   bool     `arg:"--debug" help:"Sets DEBUG log level"`
+	Upload                  []string `arg:"--upload" placeholder:"SOURCE:DESTINATION" help:"Files or directories to copy from the workspace into a VM before executing the command/script"`
+	Download                []string `arg:"--download" placeholder:"PATTERN:DESTINATION" help:"Glob patterns of files in a VM to copy into the workspace after executing the command/script"`
+	WorkspacePath           string   `arg:"--workspace-path,env:WORKSPACE_PATH" placeholder:"PATH" help:"Path of the workspace which relative local paths of uploaded and downloaded files are resolved against"`
+	StdoutPath              string   `arg:"--stdout-path,env:STDOUT_PATH" placeholder:"PATH" help:"File in the workspace to copy stdout of the command/script into"`
+	StderrPath              string   `arg:"--stderr-path,env:STDERR_PATH" placeholder:"PATH" help:"File in the workspace to copy stderr of the command/script into"`
+	StdoutTailSize          string   `arg:"--stdout-tail-size,env:STDOUT_TAIL_SIZE" placeholder:"BYTES" help:"Number of bytes from the end of stdout of the command/script to record as a result"`
 	Command                 []string `arg:"positional" placeholder:"COMMAND" help:"Command to execute in a VM"`
+
+	uploads    []FileTransfer
+	downloads  []FileTransfer
+	stdoutPath string
+	stderrPath string
 }
 
 func (c *CLIOptions) GetDebugLevel() zapcore.Level {
@@ -39,6 +76,55 @@ func (c *CLIOptions) GetVirtualMachineNamespace() string {
 	return c.VirtualMachineNamespace
 }
 
+func (c *CLIOptions) GetVirtualMachineSelector() string {
+	return c.VirtualMachineSelector
+}
+
+func (c *CLIOptions) GetParallelism() int {
+	if c.Parallelism != "" {
+		parallelism, err := strconv.Atoi(c.Parallelism)
+		if err == nil {
+			return parallelism
+		}
+	}
+
+	return constants.DefaultParallelism
+}
+
+// GetFailureTolerance returns how many of vmCount VMs matching the vm-selector option are allowed to fail.
+// Percentages are rounded down.
+func (c *CLIOptions) GetFailureTolerance(vmCount int) int {
+	if percentage, isPercentage := strings.CutSuffix(c.FailureTolerance, "%"); isPercentage {
+		percent, err := strconv.Atoi(percentage)
+		if err == nil {
+			return vmCount * percent / 100
+		}
+		return 0
+	}
+
+	tolerance, err := strconv.Atoi(c.FailureTolerance)
+	if err == nil {
+		return tolerance
+	}
+	return 0
+}
+
+// ForVirtualMachine returns a copy of the options which targets a single VM matching the vm-selector option.
+// Output files and downloads of the VM are placed into a directory named after the VM.
+func (c *CLIOptions) ForVirtualMachine(vmName string) *CLIOptions {
+	options := *c
+	options.VirtualMachineName = vmName
+	options.VirtualMachineSelector = ""
+	options.stdoutPath = perVirtualMachinePath(c.stdoutPath, vmName)
+	options.stderrPath = perVirtualMachinePath(c.stderrPath, vmName)
+
+	options.downloads = nil
+	for _, download := range c.downloads {
+		options.downloads = append(options.downloads, FileTransfer{Local: filepath.Join(download.Local, vmName), Remote: download.Remote})
+	}
+	return &options
+}
+
 func (c *CLIOptions) GetScript() string {
 	return c.Script
 }
@@ -54,6 +140,42 @@ func (c *CLIOptions) GetScriptTimeout() time.Duration {
 	return 0
 }
 
+func (c *CLIOptions) GetConnectionMode() constants.ConnectionMode {
+	if c.ConnectionMode == "" {
+		return constants.PodNetworkConnectionMode
+	}
+	return constants.ConnectionMode(c.ConnectionMode)
+}
+
+func (c *CLIOptions) GetUploads() []FileTransfer {
+	return c.uploads
+}
+
+func (c *CLIOptions) GetDownloads() []FileTransfer {
+	return c.downloads
+}
+
+// GetStdoutPath returns the resolved path of the stdout file or an empty string
+func (c *CLIOptions) GetStdoutPath() string {
+	return c.stdoutPath
+}
+
+// GetStderrPath returns the resolved path of the stderr file or an empty string
+func (c *CLIOptions) GetStderrPath() string {
+	return c.stderrPath
+}
+
+func (c *CLIOptions) GetStdoutTailSize() int {
+	if c.StdoutTailSize != "" {
+		size, err := strconv.Atoi(c.StdoutTailSize)
+		if err == nil {
+			return size
+		}
+	}
+
+	return constants.DefaultStdoutTailSize
+}
+
 func (c *CLIOptions) ShouldStop() bool {
 	return zutils.IsTrue(c.Stop)
 }
@@ -89,5 +211,13 @@ func (c *CLIOptions) Init() error {
 		return err
 	}
 
+	if err := c.resolveFileTransfers(); err != nil {
+		return err
+	}
+
+	if err := c.resolveOutputFiles(); err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -3,6 +3,7 @@ package parse_test
 import (
 	"time"
 
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/constants"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/utils/parse"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -29,6 +30,19 @@ var _ = Describe("CLIOptions", func() {
 			VirtualMachineName:      "no dns 1123",
 			VirtualMachineNamespace: defaultNS,
 		}),
+		Entry("vm name and selector", "only one of vm-name|vm-selector options is allowed", &parse.CLIOptions{
+			VirtualMachineName:      "test",
+			VirtualMachineSelector:  "app=web",
+			VirtualMachineNamespace: defaultNS,
+		}),
+		Entry("invalid vm selector", "vm-selector is not a valid label selector", &parse.CLIOptions{
+			VirtualMachineSelector:  "app in (web",
+			VirtualMachineNamespace: defaultNS,
+		}),
+		Entry("selector without script or command", "no action was specified: at least one of the following options is required: command|script|stop|delete", &parse.CLIOptions{
+			VirtualMachineSelector:  "app=web",
+			VirtualMachineNamespace: defaultNS,
+		}),
 		Entry("no script or command", "no action was specified: at least one of the following options is required: command|script|stop|delete", &parse.CLIOptions{
 			VirtualMachineName:      "test",
 			VirtualMachineNamespace: defaultNS,
@@ -77,6 +91,113 @@ var _ = Describe("CLIOptions", func() {
 			Delete:                  "yes",
 			ConnectionSecretName:    "my-secret",
 		}),
+		Entry("invalid connection mode", "invalid option connection-mode service, only pod-network|port-forward is allowed", &parse.CLIOptions{
+			VirtualMachineName:      "test",
+			VirtualMachineNamespace: defaultNS,
+			Script:                  script,
+			ConnectionMode:          "service",
+			ConnectionSecretName:    "my-secret",
+		}),
+		Entry("invalid parallelism", "invalid option parallelism all, only a positive number is allowed", &parse.CLIOptions{
+			VirtualMachineSelector:  "app=web",
+			VirtualMachineNamespace: defaultNS,
+			Script:                  script,
+			ConnectionSecretName:    "my-secret",
+			Parallelism:             "all",
+		}),
+		Entry("zero parallelism", "invalid option parallelism 0, only a positive number is allowed", &parse.CLIOptions{
+			VirtualMachineSelector:  "app=web",
+			VirtualMachineNamespace: defaultNS,
+			Script:                  script,
+			ConnectionSecretName:    "my-secret",
+			Parallelism:             "0",
+		}),
+		Entry("invalid failure tolerance", "invalid option failure-tolerance half, only a number of VMs or a percentage between 0% and 100% is allowed", &parse.CLIOptions{
+			VirtualMachineSelector:  "app=web",
+			VirtualMachineNamespace: defaultNS,
+			Script:                  script,
+			ConnectionSecretName:    "my-secret",
+			FailureTolerance:        "half",
+		}),
+		Entry("negative failure tolerance", "invalid option failure-tolerance -1, only a number of VMs or a percentage between 0% and 100% is allowed", &parse.CLIOptions{
+			VirtualMachineSelector:  "app=web",
+			VirtualMachineNamespace: defaultNS,
+			Script:                  script,
+			ConnectionSecretName:    "my-secret",
+			FailureTolerance:        "-1",
+		}),
+		Entry("too large failure tolerance percentage", "invalid option failure-tolerance 150%, only a number of VMs or a percentage between 0% and 100% is allowed", &parse.CLIOptions{
+			VirtualMachineSelector:  "app=web",
+			VirtualMachineNamespace: defaultNS,
+			Script:                  script,
+			ConnectionSecretName:    "my-secret",
+			FailureTolerance:        "150%",
+		}),
+		Entry("upload without script", "upload|download options require command|script option", &parse.CLIOptions{
+			VirtualMachineName:      "test",
+			VirtualMachineNamespace: defaultNS,
+			Stop:                    "true",
+			Upload:                  []string{"src:src"},
+		}),
+		Entry("upload without source", "invalid upload :/tmp: source is required", &parse.CLIOptions{
+			VirtualMachineName:      "test",
+			VirtualMachineNamespace: defaultNS,
+			Script:                  script,
+			ConnectionSecretName:    "my-secret",
+			WorkspacePath:           "/workspace/data",
+			Upload:                  []string{":/tmp"},
+		}),
+		Entry("download without pattern", "invalid download : pattern is required", &parse.CLIOptions{
+			VirtualMachineName:      "test",
+			VirtualMachineNamespace: defaultNS,
+			Script:                  script,
+			ConnectionSecretName:    "my-secret",
+			WorkspacePath:           "/workspace/data",
+			Download:                []string{""},
+		}),
+		Entry("relative path without workspace", "workspace-path option is required to resolve relative path src", &parse.CLIOptions{
+			VirtualMachineName:      "test",
+			VirtualMachineNamespace: defaultNS,
+			Script:                  script,
+			ConnectionSecretName:    "my-secret",
+			Upload:                  []string{"src:src"},
+		}),
+		Entry("invalid stdout tail size", "invalid option stdout-tail-size 1k, only a number of bytes between 0 and 2048 is allowed", &parse.CLIOptions{
+			VirtualMachineName:      "test",
+			VirtualMachineNamespace: defaultNS,
+			Script:                  script,
+			ConnectionSecretName:    "my-secret",
+			StdoutTailSize:          "1k",
+		}),
+		Entry("too large stdout tail size", "invalid option stdout-tail-size 4096, only a number of bytes between 0 and 2048 is allowed", &parse.CLIOptions{
+			VirtualMachineName:      "test",
+			VirtualMachineNamespace: defaultNS,
+			Script:                  script,
+			ConnectionSecretName:    "my-secret",
+			StdoutTailSize:          "4096",
+		}),
+		Entry("stdout path without script", "stdout-path|stderr-path options require command|script option", &parse.CLIOptions{
+			VirtualMachineName:      "test",
+			VirtualMachineNamespace: defaultNS,
+			Stop:                    "true",
+			StdoutPath:              "/tmp/stdout.txt",
+		}),
+		Entry("relative stderr path without workspace", "workspace-path option is required to resolve relative path stderr.txt", &parse.CLIOptions{
+			VirtualMachineName:      "test",
+			VirtualMachineNamespace: defaultNS,
+			Script:                  script,
+			ConnectionSecretName:    "my-secret",
+			StderrPath:              "stderr.txt",
+		}),
+		Entry("same stdout and stderr path", "stdout-path and stderr-path options should point to different files", &parse.CLIOptions{
+			VirtualMachineName:      "test",
+			VirtualMachineNamespace: defaultNS,
+			Script:                  script,
+			ConnectionSecretName:    "my-secret",
+			WorkspacePath:           "/workspace/data",
+			StdoutPath:              "output.txt",
+			StderrPath:              "/workspace/data/output.txt",
+		}),
 	)
 	//
 	DescribeTable("Parses and returns correct values", func(options *parse.CLIOptions, expectedOptions map[string]interface{}) {
@@ -99,6 +220,23 @@ var _ = Describe("CLIOptions", func() {
 			"GetScriptTimeout":           0 * time.Second,
 			"ShouldStop":                 false,
 			"ShouldDelete":               false,
+			"GetConnectionMode":          constants.PodNetworkConnectionMode,
+			"GetStdoutPath":              "",
+			"GetStderrPath":              "",
+			"GetStdoutTailSize":          constants.DefaultStdoutTailSize,
+			"GetVirtualMachineSelector":  "",
+			"GetParallelism":             constants.DefaultParallelism,
+		}),
+		Entry("handles vm selector", &parse.CLIOptions{
+			VirtualMachineSelector:  " app=web,tier!=db ",
+			VirtualMachineNamespace: defaultNS,
+			Script:                  script,
+			ConnectionSecretName:    "my-secret",
+			Parallelism:             " 10 ",
+			FailureTolerance:        " 10% ",
+		}, map[string]interface{}{
+			"GetVirtualMachineSelector": "app=web,tier!=db",
+			"GetParallelism":            10,
 		}),
 		Entry("handles Script cli arguments", &parse.CLIOptions{
 			VirtualMachineName:      "vm",
@@ -143,6 +281,157 @@ var _ = Describe("CLIOptions", func() {
 			"ShouldStop":                 true,
 			"ShouldDelete":               true,
 		}),
+		Entry("handles file transfers", &parse.CLIOptions{
+			VirtualMachineName:      "vm",
+			VirtualMachineNamespace: defaultNS,
+			Script:                  script,
+			ConnectionSecretName:    "my-secret",
+			WorkspacePath:           " /workspace/data ",
+			Upload:                  []string{"src:/home/fedora/src", "/tmp/config.yaml", " tests/ : tests/ "},
+			Download:                []string{"reports/*.xml:reports", "/var/log/app.log", "coverage.out:/tmp"},
+		}, map[string]interface{}{
+			"GetUploads": []parse.FileTransfer{
+				{Local: "/workspace/data/src", Remote: "/home/fedora/src"},
+				{Local: "/tmp/config.yaml", Remote: ""},
+				{Local: "/workspace/data/tests", Remote: "tests/"},
+			},
+			"GetDownloads": []parse.FileTransfer{
+				{Local: "/workspace/data/reports", Remote: "reports/*.xml"},
+				{Local: "/workspace/data", Remote: "/var/log/app.log"},
+				{Local: "/tmp", Remote: "coverage.out"},
+			},
+		}),
+		Entry("handles connection mode", &parse.CLIOptions{
+			VirtualMachineName:      "vm",
+			VirtualMachineNamespace: defaultNS,
+			Script:                  script,
+			ConnectionSecretName:    "my-secret",
+			ConnectionMode:          " port-forward ",
+		}, map[string]interface{}{
+			"GetConnectionMode": constants.PortForwardConnectionMode,
+		}),
+		Entry("handles output options", &parse.CLIOptions{
+			VirtualMachineName:      "vm",
+			VirtualMachineNamespace: defaultNS,
+			Script:                  script,
+			ConnectionSecretName:    "my-secret",
+			WorkspacePath:           "/workspace/data",
+			StdoutPath:              " logs/stdout.txt ",
+			StderrPath:              "/tmp/stderr.txt",
+			StdoutTailSize:          " 0 ",
+		}, map[string]interface{}{
+			"GetStdoutPath":     "/workspace/data/logs/stdout.txt",
+			"GetStderrPath":     "/tmp/stderr.txt",
+			"GetStdoutTailSize": 0,
+		}),
+		Entry("no file transfers", &parse.CLIOptions{
+			VirtualMachineName:      "vm",
+			VirtualMachineNamespace: defaultNS,
+			Script:                  script,
+			ConnectionSecretName:    "my-secret",
+		}, map[string]interface{}{
+			"GetUploads":   []parse.FileTransfer(nil),
+			"GetDownloads": []parse.FileTransfer(nil),
+		}),
+	)
+
+	DescribeTable("GetFailureTolerance", func(failureTolerance string, vmCount, expectedTolerance int) {
+		options := &parse.CLIOptions{
+			VirtualMachineSelector:  "app=web",
+			VirtualMachineNamespace: defaultNS,
+			Script:                  script,
+			ConnectionSecretName:    "my-secret",
+			FailureTolerance:        failureTolerance,
+		}
+		Expect(options.Init()).Should(Succeed())
+		Expect(options.GetFailureTolerance(vmCount)).To(Equal(expectedTolerance))
+	},
+		Entry("default", "", 10, 0),
+		Entry("number of VMs", "3", 10, 3),
+		Entry("percentage", "20%", 10, 2),
+		Entry("percentage is rounded down", "25%", 10, 2),
+		Entry("all VMs", "100%", 7, 7),
 	)
 
+	DescribeTable("ValidateExecSecretType", func(options *parse.CLIOptions, secretType constants.ExecSecretType, expectedErrMessage string) {
+		Expect(options.Init()).Should(Succeed())
+		err := options.ValidateExecSecretType(secretType)
+		if expectedErrMessage == "" {
+			Expect(err).ShouldNot(HaveOccurred())
+		} else {
+			Expect(err).Should(MatchError(ContainSubstring(expectedErrMessage)))
+		}
+	},
+		Entry("ssh with file transfers", &parse.CLIOptions{
+			VirtualMachineName:      "vm",
+			VirtualMachineNamespace: defaultNS,
+			Script:                  script,
+			ConnectionSecretName:    "my-secret",
+			Upload:                  []string{"/src:/home/fedora/src"},
+			Download:                []string{"reports/*.xml:/reports"},
+		}, constants.SSHSecretType, ""),
+		Entry("serial console without file transfers", &parse.CLIOptions{
+			VirtualMachineName:      "vm",
+			VirtualMachineNamespace: defaultNS,
+			Script:                  script,
+			ConnectionSecretName:    "my-secret",
+		}, constants.SerialConsoleSecretType, ""),
+		Entry("serial console with upload", &parse.CLIOptions{
+			VirtualMachineName:      "vm",
+			VirtualMachineNamespace: defaultNS,
+			Script:                  script,
+			ConnectionSecretName:    "my-secret",
+			Upload:                  []string{"/src:/home/fedora/src"},
+		}, constants.SerialConsoleSecretType, "upload|download options are not supported with serial-console connection type"),
+		Entry("serial console with download", &parse.CLIOptions{
+			VirtualMachineName:      "vm",
+			VirtualMachineNamespace: defaultNS,
+			Script:                  script,
+			ConnectionSecretName:    "my-secret",
+			Download:                []string{"reports/*.xml:/reports"},
+		}, constants.SerialConsoleSecretType, "upload|download options are not supported with serial-console connection type"),
+		Entry("winrm with upload", &parse.CLIOptions{
+			VirtualMachineName:      "vm",
+			VirtualMachineNamespace: defaultNS,
+			Script:                  script,
+			ConnectionSecretName:    "my-secret",
+			Upload:                  []string{"/src:C:\\src"},
+		}, constants.WinRMSecretType, "upload|download options are not supported with winrm connection type"),
+		Entry("winrm with download", &parse.CLIOptions{
+			VirtualMachineName:      "vm",
+			VirtualMachineNamespace: defaultNS,
+			Script:                  script,
+			ConnectionSecretName:    "my-secret",
+			Download:                []string{"reports/*.xml:/reports"},
+		}, constants.WinRMSecretType, "upload|download options are not supported with winrm connection type"),
+	)
+
+	It("ForVirtualMachine places output of each VM into its directory", func() {
+		options := &parse.CLIOptions{
+			VirtualMachineSelector:  "app=web",
+			VirtualMachineNamespace: defaultNS,
+			Script:                  script,
+			ConnectionSecretName:    "my-secret",
+			WorkspacePath:           "/workspace/data",
+			Upload:                  []string{"src:/home/fedora/src"},
+			Download:                []string{"reports/*.xml:reports"},
+			StdoutPath:              "logs/stdout.txt",
+		}
+		Expect(options.Init()).Should(Succeed())
+
+		vmOptions := options.ForVirtualMachine("vm-1")
+		Expect(vmOptions.VirtualMachineName).To(Equal("vm-1"))
+		Expect(vmOptions.GetVirtualMachineSelector()).To(BeEmpty())
+		Expect(vmOptions.GetVirtualMachineNamespace()).To(Equal(defaultNS))
+		Expect(vmOptions.GetScript()).To(Equal(script))
+		Expect(vmOptions.GetStdoutPath()).To(Equal("/workspace/data/logs/vm-1/stdout.txt"))
+		Expect(vmOptions.GetStderrPath()).To(BeEmpty())
+		Expect(vmOptions.GetUploads()).To(Equal([]parse.FileTransfer{{Local: "/workspace/data/src", Remote: "/home/fedora/src"}}))
+		Expect(vmOptions.GetDownloads()).To(Equal([]parse.FileTransfer{{Local: "/workspace/data/reports/vm-1", Remote: "reports/*.xml"}}))
+
+		// the original options are not modified
+		Expect(options.VirtualMachineName).To(BeEmpty())
+		Expect(options.GetStdoutPath()).To(Equal("/workspace/data/logs/stdout.txt"))
+		Expect(options.GetDownloads()).To(Equal([]parse.FileTransfer{{Local: "/workspace/data/reports", Remote: "reports/*.xml"}}))
+	})
 })
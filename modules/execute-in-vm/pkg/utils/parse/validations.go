@@ -5,16 +5,37 @@ import (
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/env"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zconstants"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zerrors"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/validation"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 )
 
 func (c *CLIOptions) trimSpaces() {
 	c.VirtualMachineNamespace = strings.TrimSpace(c.VirtualMachineNamespace)
+	c.VirtualMachineSelector = strings.TrimSpace(c.VirtualMachineSelector)
+	c.Parallelism = strings.TrimSpace(c.Parallelism)
+	c.FailureTolerance = strings.TrimSpace(c.FailureTolerance)
+	c.WorkspacePath = strings.TrimSpace(c.WorkspacePath)
+	c.ConnectionMode = strings.TrimSpace(c.ConnectionMode)
+	c.StdoutPath = strings.TrimSpace(c.StdoutPath)
+	c.StderrPath = strings.TrimSpace(c.StderrPath)
+	c.StdoutTailSize = strings.TrimSpace(c.StdoutTailSize)
 }
 
 func (c *CLIOptions) validateName() error {
+	if c.VirtualMachineSelector != "" {
+		if c.VirtualMachineName != "" {
+			return zerrors.NewMissingRequiredError("only one of %v|%v options is allowed", vmNameOptionName, vmSelectorOptionName)
+		}
+		if _, err := labels.Parse(c.VirtualMachineSelector); err != nil {
+			return zerrors.NewMissingRequiredError("%v is not a valid label selector: %v", vmSelectorOptionName, err.Error())
+		}
+		return nil
+	}
+
 	if c.VirtualMachineName == "" {
 		return zerrors.NewMissingRequiredError("missing value for %v option", vmNameOptionName)
 	}
@@ -105,6 +126,145 @@ func (c *CLIOptions) validateValues() error {
 		return zerrors.NewSoftError("invalid option delete %v, only true|false is allowed", c.Delete)
 	}
 
+	switch constants.ConnectionMode(c.ConnectionMode) {
+	case "", constants.PodNetworkConnectionMode, constants.PortForwardConnectionMode:
+	default:
+		return zerrors.NewSoftError("invalid option %v %v, only %v|%v is allowed", connectionModeName, c.ConnectionMode,
+			constants.PodNetworkConnectionMode, constants.PortForwardConnectionMode)
+	}
+
+	if c.StdoutTailSize != "" {
+		size, err := strconv.Atoi(c.StdoutTailSize)
+		if err != nil || size < 0 || size > constants.MaxStdoutTailSize {
+			return zerrors.NewSoftError("invalid option %v %v, only a number of bytes between 0 and %v is allowed", stdoutTailSizeName, c.StdoutTailSize,
+				constants.MaxStdoutTailSize)
+		}
+	}
+
+	if c.Parallelism != "" {
+		parallelism, err := strconv.Atoi(c.Parallelism)
+		if err != nil || parallelism < 1 {
+			return zerrors.NewSoftError("invalid option %v %v, only a positive number is allowed", parallelismOptionName, c.Parallelism)
+		}
+	}
+
+	if c.FailureTolerance != "" {
+		value, isPercentage := strings.CutSuffix(c.FailureTolerance, "%")
+		tolerance, err := strconv.Atoi(value)
+		if err != nil || tolerance < 0 || (isPercentage && tolerance > 100) {
+			return zerrors.NewSoftError("invalid option %v %v, only a number of VMs or a percentage between 0%% and 100%% is allowed",
+				toleranceOptionName, c.FailureTolerance)
+		}
+	}
+
 	return nil
 
 }
+
+func (c *CLIOptions) resolveFileTransfers() error {
+	if len(c.Upload) == 0 && len(c.Download) == 0 {
+		return nil
+	}
+
+	if c.GetScript() == "" {
+		return zerrors.NewMissingRequiredError("%v|%v options require %v|%v option", uploadOptionName, downloadOptionName, commandOptionName, scriptOptionName)
+	}
+
+	c.uploads = nil
+	for _, upload := range c.Upload {
+		source, destination := splitFileTransfer(upload)
+		if source == "" {
+			return zerrors.NewMissingRequiredError("invalid %v %v: source is required", uploadOptionName, upload)
+		}
+
+		local, err := c.resolveLocalPath(source)
+		if err != nil {
+			return err
+		}
+		c.uploads = append(c.uploads, FileTransfer{Local: local, Remote: destination})
+	}
+
+	c.downloads = nil
+	for _, download := range c.Download {
+		pattern, destination := splitFileTransfer(download)
+		if pattern == "" {
+			return zerrors.NewMissingRequiredError("invalid %v %v: pattern is required", downloadOptionName, download)
+		}
+
+		local, err := c.resolveLocalPath(destination)
+		if err != nil {
+			return err
+		}
+		c.downloads = append(c.downloads, FileTransfer{Local: local, Remote: pattern})
+	}
+
+	return nil
+}
+
+// ValidateExecSecretType rejects options which are not supported by the connection type of the secret
+func (c *CLIOptions) ValidateExecSecretType(secretType constants.ExecSecretType) error {
+	if len(c.Upload) == 0 && len(c.Download) == 0 {
+		return nil
+	}
+
+	switch secretType {
+	case constants.SerialConsoleSecretType, constants.WinRMSecretType:
+		return zerrors.NewMissingRequiredError("%v|%v options are not supported with %v connection type", uploadOptionName, downloadOptionName, secretType)
+	}
+
+	return nil
+}
+
+func (c *CLIOptions) resolveOutputFiles() error {
+	if c.StdoutPath == "" && c.StderrPath == "" {
+		return nil
+	}
+
+	if c.GetScript() == "" {
+		return zerrors.NewMissingRequiredError("%v|%v options require %v|%v option", stdoutPathOptionName, stderrPathOptionName, commandOptionName, scriptOptionName)
+	}
+
+	var err error
+	c.stdoutPath, c.stderrPath = "", ""
+	if c.StdoutPath != "" {
+		if c.stdoutPath, err = c.resolveLocalPath(c.StdoutPath); err != nil {
+			return err
+		}
+	}
+	if c.StderrPath != "" {
+		if c.stderrPath, err = c.resolveLocalPath(c.StderrPath); err != nil {
+			return err
+		}
+	}
+
+	if c.stdoutPath != "" && c.stdoutPath == c.stderrPath {
+		return zerrors.NewMissingRequiredError("%v and %v options should point to different files", stdoutPathOptionName, stderrPathOptionName)
+	}
+
+	return nil
+}
+
+func (c *CLIOptions) resolveLocalPath(localPath string) (string, error) {
+	if filepath.IsAbs(localPath) {
+		return filepath.Clean(localPath), nil
+	}
+
+	if c.WorkspacePath == "" {
+		return "", zerrors.NewMissingRequiredError("%v option is required to resolve relative path %v", workspaceOptionName, localPath)
+	}
+	return filepath.Join(c.WorkspacePath, localPath), nil
+}
+
+// perVirtualMachinePath places the file into a directory named after the VM
+func perVirtualMachinePath(path string, vmName string) string {
+	if path == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(path), vmName, filepath.Base(path))
+}
+
+// splitFileTransfer splits a transfer in a LEFT:RIGHT format
+func splitFileTransfer(value string) (string, string) {
+	left, right, _ := strings.Cut(value, ":")
+	return strings.TrimSpace(left), strings.TrimSpace(right)
+}
@@ -0,0 +1,191 @@
+package winrmclient
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/constants"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/utils/log"
+	"github.com/masterzen/winrm"
+	"go.uber.org/zap"
+)
+
+// httpErrorPrefix starts the errors of the winrm library for requests the server responded to
+const httpErrorPrefix = "http"
+
+// ErrTimeout is returned when the script does not finish in time
+var ErrTimeout = errors.New("timed out waiting for the script")
+
+type Config struct {
+	Host  string
+	Port  int
+	HTTPS bool
+	// Insecure skips the verification of the server certificate
+	Insecure bool
+	CACert   string
+	// TLSServerName is verified in the server certificate instead of the host
+	TLSServerName string
+	// ClientCert and ClientKey are used instead of the user and password
+	ClientCert string
+	ClientKey  string
+	User       string
+	Password   string
+	// Dial opens the connections to the server, a nil Dial connects to the host directly
+	Dial func(network, addr string) (net.Conn, error)
+}
+
+// NewClient creates a client which authenticates with the client certificate, or with the user and password.
+// Passwords are sent with NTLM, or with basic auth when the server does not offer NTLM.
+// No connection is opened until the first request.
+func NewClient(config *Config) (*winrm.Client, error) {
+	endpoint := winrm.NewEndpoint(config.Host, config.Port, config.HTTPS, config.Insecure,
+		toBytes(config.CACert), toBytes(config.ClientCert), toBytes(config.ClientKey), constants.WinRMRequestTimeout)
+	endpoint.TLSServerName = config.TLSServerName
+
+	params := *winrm.DefaultParameters
+	params.Dial = config.Dial
+	if config.ClientCert != "" {
+		params.TransportDecorator = func() winrm.Transporter {
+			return winrm.NewClientAuthRequestWithDial(config.Dial)
+		}
+	} else {
+		params.TransportDecorator = func() winrm.Transporter {
+			return winrm.NewClientNTLMWithDial(config.Dial)
+		}
+	}
+
+	return winrm.NewClientWithParameters(endpoint, config.User, config.Password, &params)
+}
+
+// IsReachable tests whether the WinRM service responds, even when it rejects the request
+func IsReachable(client *winrm.Client) bool {
+	shell, err := client.CreateShell()
+	if err != nil {
+		log.Logger().Debug("could not create a winrm shell", zap.Error(err))
+		return strings.HasPrefix(err.Error(), httpErrorPrefix)
+	}
+	if err := shell.Close(); err != nil {
+		log.Logger().Debug("could not close the winrm shell", zap.Error(err))
+	}
+	return true
+}
+
+// Run executes the script with the shell and writes its output to stdout and stderr.
+// The script is sent over stdin to the bootstrap Command, so its length is not limited
+// by the maximum length of the Windows command line.
+// When the timeout expires, the script is terminated and ErrTimeout is returned.
+func Run(client *winrm.Client, script string, shell constants.WinRMShellType, stdout, stderr io.Writer, timeout time.Duration) (int, error) {
+	winrmShell, err := client.CreateShell()
+	if err != nil {
+		return -1, err
+	}
+	defer func() {
+		if err := winrmShell.Close(); err != nil {
+			log.Logger().Debug("could not close the winrm shell", zap.Error(err))
+		}
+	}()
+
+	cmd, err := winrmShell.Execute(Command(shell))
+	if err != nil {
+		return -1, err
+	}
+	defer func() {
+		if err := cmd.Close(); err != nil {
+			log.Logger().Debug("could not close the winrm command", zap.Error(err))
+		}
+	}()
+
+	copyErrs := make(chan error, 2)
+	go func() {
+		_, err := io.Copy(stdout, cmd.Stdout)
+		copyErrs <- err
+	}()
+	go func() {
+		_, err := io.Copy(stderr, cmd.Stderr)
+		copyErrs <- err
+	}()
+
+	if _, err := cmd.Stdin.Write([]byte(Input(script, shell))); err != nil {
+		return -1, err
+	}
+	if err := cmd.Stdin.Close(); err != nil {
+		return -1, err
+	}
+
+	var timeoutCh <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutCh = timer.C
+	}
+
+	// the streams are closed when the command finishes or its output cannot be received
+	for i := 0; i < cap(copyErrs); i++ {
+		select {
+		case err := <-copyErrs:
+			if err != nil {
+				return -1, err
+			}
+		case <-timeoutCh:
+			return -1, ErrTimeout
+		}
+	}
+
+	cmd.Wait()
+	return cmd.ExitCode(), nil
+}
+
+// Command returns the PowerShell command line which reads the base64 encoded Input from stdin,
+// stores it in a temporary file and executes it with the shell. The exit code of the script is kept.
+func Command(shell constants.WinRMShellType) string {
+	extension, run := ".ps1", "& powershell.exe -NoProfile -NonInteractive -ExecutionPolicy Bypass -File $f"
+	encoding := "(New-Object Text.UTF8Encoding $true)"
+	if shell == constants.CmdWinRMShell {
+		// cmd.exe does not understand the byte order mark
+		extension, run = ".cmd", "& cmd.exe /d /c $f"
+		encoding = "(New-Object Text.UTF8Encoding $false)"
+	}
+
+	bootstrap := strings.Join([]string{
+		"$ProgressPreference = 'SilentlyContinue'",
+		"$ErrorActionPreference = 'Stop'",
+		"$s = [Text.Encoding]::UTF8.GetString([Convert]::FromBase64String([Console]::In.ReadToEnd()))",
+		"$f = Join-Path $env:TEMP ('execute-in-vm-' + [guid]::NewGuid() + '" + extension + "')",
+		"[IO.File]::WriteAllText($f, $s, " + encoding + ")",
+		"try { " + run + "; $rc = $LASTEXITCODE } finally { Remove-Item -Force $f }",
+		"exit $rc",
+	}, "\n")
+
+	return "powershell.exe -NoProfile -NonInteractive -ExecutionPolicy Bypass -EncodedCommand " + encodePowerShell(bootstrap)
+}
+
+// Input returns the script in the form which is sent to the stdin of the Command
+func Input(script string, shell constants.WinRMShellType) string {
+	if shell == constants.CmdWinRMShell {
+		script = strings.ReplaceAll(strings.ReplaceAll(script, "\r\n", "\n"), "\n", "\r\n")
+	}
+	return base64.StdEncoding.EncodeToString([]byte(script))
+}
+
+// encodePowerShell encodes the script as UTF-16LE in base64, which is expected by -EncodedCommand
+func encodePowerShell(script string) string {
+	encoded := utf16.Encode([]rune(script))
+	bytes := make([]byte, len(encoded)*2)
+	for i, char := range encoded {
+		binary.LittleEndian.PutUint16(bytes[i*2:], char)
+	}
+	return base64.StdEncoding.EncodeToString(bytes)
+}
+
+func toBytes(value string) []byte {
+	if value == "" {
+		return nil
+	}
+	return []byte(value)
+}
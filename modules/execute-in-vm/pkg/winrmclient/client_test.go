@@ -0,0 +1,217 @@
+package winrmclient_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"net"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/constants"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/execute-in-vm/pkg/winrmclient"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	testUser     = "Administrator"
+	testPassword = "Passw0rd!"
+	encodedFlag  = "-EncodedCommand "
+)
+
+var _ = Describe("WinRMClient", func() {
+	var server *fakeServer
+	var httpServer *httptest.Server
+
+	echoScript := func(input string) (string, string, int) {
+		script, err := base64.StdEncoding.DecodeString(input)
+		Expect(err).Should(Succeed())
+		return string(script), "stderr output", 3
+	}
+
+	BeforeEach(func() {
+		server = newFakeServer(testUser, testPassword, echoScript)
+	})
+
+	AfterEach(func() {
+		if httpServer != nil {
+			httpServer.Close()
+			httpServer = nil
+		}
+	})
+
+	newConfig := func(https bool) *winrmclient.Config {
+		httpServer = startFakeServer(server, https)
+		serverURL, err := url.Parse(httpServer.URL)
+		Expect(err).Should(Succeed())
+		host, port, err := net.SplitHostPort(serverURL.Host)
+		Expect(err).Should(Succeed())
+		portNumber, err := strconv.Atoi(port)
+		Expect(err).Should(Succeed())
+
+		return &winrmclient.Config{
+			Host:     host,
+			Port:     portNumber,
+			HTTPS:    https,
+			User:     testUser,
+			Password: testPassword,
+		}
+	}
+
+	run := func(config *winrmclient.Config, script string, shell constants.WinRMShellType, timeout time.Duration) (int, string, string, error) {
+		client, err := winrmclient.NewClient(config)
+		Expect(err).Should(Succeed())
+
+		var stdout, stderr bytes.Buffer
+		exitCode, err := winrmclient.Run(client, script, shell, &stdout, &stderr, timeout)
+		return exitCode, stdout.String(), stderr.String(), err
+	}
+
+	decodeCommand := func(command string) string {
+		Expect(command).To(HavePrefix("powershell.exe "))
+		Expect(command).To(ContainSubstring(encodedFlag))
+		encoded, err := base64.StdEncoding.DecodeString(command[strings.Index(command, encodedFlag)+len(encodedFlag):])
+		Expect(err).Should(Succeed())
+		Expect(len(encoded) % 2).To(Equal(0))
+
+		chars := make([]uint16, len(encoded)/2)
+		for i := range chars {
+			chars[i] = binary.LittleEndian.Uint16(encoded[i*2:])
+		}
+		return string(utf16.Decode(chars))
+	}
+
+	DescribeTable("runs scripts", func(shell constants.WinRMShellType, script, expectedScript, expectedRun string) {
+		exitCode, stdout, stderr, err := run(newConfig(false), script, shell, 10*time.Second)
+		Expect(err).Should(Succeed())
+		Expect(exitCode).To(Equal(3))
+		Expect(stdout).To(Equal(expectedScript))
+		Expect(stderr).To(Equal("stderr output"))
+
+		bootstrap := decodeCommand(server.getCommand())
+		Expect(bootstrap).To(ContainSubstring("[Console]::In.ReadToEnd()"))
+		Expect(bootstrap).To(ContainSubstring(expectedRun))
+		Expect(bootstrap).To(ContainSubstring("exit $rc"))
+		Eventually(server.isClosed).Should(BeTrue())
+	},
+		Entry("powershell", constants.PowerShellWinRMShell, "Write-Output 'hello'\nexit 3", "Write-Output 'hello'\nexit 3",
+			"& powershell.exe -NoProfile -NonInteractive -ExecutionPolicy Bypass -File $f"),
+		Entry("powershell with unicode", constants.PowerShellWinRMShell, "Write-Output 'žluťoučký kůň'", "Write-Output 'žluťoučký kůň'",
+			"-File $f"),
+		Entry("cmd", constants.CmdWinRMShell, "@echo off\necho hello\r\nexit /b 3", "@echo off\r\necho hello\r\nexit /b 3",
+			"& cmd.exe /d /c $f"),
+	)
+
+	It("sends long scripts", func() {
+		script := strings.Repeat("Write-Output 'a long line of the script'\n", 2000)
+		_, stdout, _, err := run(newConfig(false), script, constants.PowerShellWinRMShell, 10*time.Second)
+		Expect(err).Should(Succeed())
+		Expect(stdout).To(Equal(script))
+	})
+
+	It("fails with a wrong password", func() {
+		config := newConfig(false)
+		config.Password = "wrong"
+		_, _, _, err := run(config, "Write-Output 'hello'", constants.PowerShellWinRMShell, 10*time.Second)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("401"))
+	})
+
+	It("times out a long running script", func() {
+		server.hang = true
+		_, _, _, err := run(newConfig(false), "Start-Sleep 60", constants.PowerShellWinRMShell, time.Second)
+		Expect(err).To(Equal(winrmclient.ErrTimeout))
+		Eventually(server.isSignalled).Should(BeTrue())
+	})
+
+	It("connects with a custom dial", func() {
+		config := newConfig(false)
+		address := net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+		dialed := false
+		config.Host = "windows-vm"
+		config.Dial = func(network, _ string) (net.Conn, error) {
+			dialed = true
+			return net.Dial(network, address)
+		}
+
+		_, stdout, _, err := run(config, "Write-Output 'hello'", constants.PowerShellWinRMShell, 10*time.Second)
+		Expect(err).Should(Succeed())
+		Expect(stdout).To(Equal("Write-Output 'hello'"))
+		Expect(dialed).To(BeTrue())
+	})
+
+	DescribeTable("connects over https", func(insecure, trustServer bool, expectedErrMessage string) {
+		config := newConfig(true)
+		config.Insecure = insecure
+		if trustServer {
+			config.CACert = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: httpServer.Certificate().Raw}))
+		}
+
+		_, stdout, _, err := run(config, "Write-Output 'hello'", constants.PowerShellWinRMShell, 10*time.Second)
+		if expectedErrMessage == "" {
+			Expect(err).Should(Succeed())
+			Expect(stdout).To(Equal("Write-Output 'hello'"))
+		} else {
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(expectedErrMessage))
+		}
+	},
+		Entry("with a trusted CA", false, true, ""),
+		Entry("skipping verification", true, false, ""),
+		Entry("with an unknown CA", false, false, "certificate"),
+	)
+
+	DescribeTable("verifies the tls server name with a custom dial", func(tlsServerName string, expectedErrMessage string) {
+		config := newConfig(true)
+		config.CACert = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: httpServer.Certificate().Raw}))
+		config.TLSServerName = tlsServerName
+		address := net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+		config.Host = "windows-vm"
+		config.Dial = func(network, _ string) (net.Conn, error) {
+			return net.Dial(network, address)
+		}
+
+		_, stdout, _, err := run(config, "Write-Output 'hello'", constants.PowerShellWinRMShell, 10*time.Second)
+		if expectedErrMessage == "" {
+			Expect(err).Should(Succeed())
+			Expect(stdout).To(Equal("Write-Output 'hello'"))
+		} else {
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(expectedErrMessage))
+		}
+	},
+		// the certificate of the test server is valid for example.com
+		Entry("with a matching tls server name", "example.com", ""),
+		Entry("without a tls server name", "", "windows-vm"),
+	)
+
+	It("fails with an invalid client certificate", func() {
+		config := newConfig(true)
+		config.ClientCert = "invalid"
+		config.ClientKey = "invalid"
+		_, err := winrmclient.NewClient(config)
+		Expect(err).Should(HaveOccurred())
+	})
+
+	DescribeTable("IsReachable", func(password string, stopServer, expectedReachable bool) {
+		config := newConfig(false)
+		config.Password = password
+		if stopServer {
+			httpServer.Close()
+		}
+
+		client, err := winrmclient.NewClient(config)
+		Expect(err).Should(Succeed())
+		Expect(winrmclient.IsReachable(client)).To(Equal(expectedReachable))
+	},
+		Entry("running server", testPassword, false, true),
+		Entry("rejected credentials", "wrong", false, true),
+		Entry("stopped server", testPassword, true, false),
+	)
+})
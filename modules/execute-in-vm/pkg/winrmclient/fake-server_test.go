@@ -0,0 +1,174 @@
+package winrmclient_test
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	shellID   = "67A74734-DD32-4F10-89DE-49A060483810"
+	commandID = "1A6DEE6B-EC68-4DD6-87E9-030C0048ECC4"
+
+	envelopeStart = `<s:Envelope xml:lang="en-US" xmlns:s="http://www.w3.org/2003/05/soap-envelope" xmlns:a="http://schemas.xmlsoap.org/ws/2004/08/addressing" xmlns:x="http://schemas.xmlsoap.org/ws/2004/09/transfer" xmlns:w="http://schemas.dmtf.org/wbem/wsman/1/wsman.xsd" xmlns:rsp="http://schemas.microsoft.com/wbem/wsman/1/windows/shell"><s:Header></s:Header><s:Body>`
+	envelopeEnd   = `</s:Body></s:Envelope>`
+
+	createShellResponse = envelopeStart + `<x:ResourceCreated><a:ReferenceParameters><w:SelectorSet><w:Selector Name="ShellId">` + shellID +
+		`</w:Selector></w:SelectorSet></a:ReferenceParameters></x:ResourceCreated>` + envelopeEnd
+	executeCommandResponse = envelopeStart + `<rsp:CommandResponse><rsp:CommandId>` + commandID + `</rsp:CommandId></rsp:CommandResponse>` + envelopeEnd
+	receiveResponse        = envelopeStart + `<rsp:ReceiveResponse><rsp:Stream Name="stdout" CommandId="` + commandID + `">%v</rsp:Stream>` +
+		`<rsp:Stream Name="stderr" CommandId="` + commandID + `">%v</rsp:Stream><rsp:CommandState CommandId="` + commandID +
+		`" State="http://schemas.microsoft.com/wbem/wsman/1/windows/shell/CommandState/Done"><rsp:ExitCode>%v</rsp:ExitCode></rsp:CommandState></rsp:ReceiveResponse>` + envelopeEnd
+	operationTimeoutResponse = envelopeStart + `<s:Fault><s:Reason><s:Text xml:lang="en-US">The WS-Management service cannot complete the operation within the time specified in OperationTimeout.</s:Text></s:Reason></s:Fault>` + envelopeEnd
+	emptyResponse            = envelopeStart + envelopeEnd
+
+	operationTimeout = 200 * time.Millisecond
+)
+
+var (
+	commandPattern = regexp.MustCompile(`(?s)<rsp:Command><!\[CDATA\[(.*?)\]\]></rsp:Command>`)
+	stdinPattern   = regexp.MustCompile(`(?s)<rsp:Stream[^>]*Name="stdin"[^>]*>([^<]*)</rsp:Stream>`)
+	stdinEndMarker = regexp.MustCompile(`<rsp:Stream[^>]*End="true"`)
+)
+
+// fakeScript computes the output of the script received over stdin
+type fakeScript func(input string) (stdout string, stderr string, exitCode int)
+
+// fakeServer emulates the requests of a WinRM service which are used to run a single command.
+// It offers only basic auth, so the NTLM negotiation falls back to it.
+type fakeServer struct {
+	user     string
+	password string
+	script   fakeScript
+	// hang makes the command run until it is signalled
+	hang bool
+
+	lock      sync.Mutex
+	command   string
+	stdin     strings.Builder
+	stdinDone chan struct{}
+	signalled bool
+	closed    bool
+}
+
+func newFakeServer(user, password string, script fakeScript) *fakeServer {
+	return &fakeServer{
+		user:      user,
+		password:  password,
+		script:    script,
+		stdinDone: make(chan struct{}),
+	}
+}
+
+func (f *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, password, ok := r.BasicAuth()
+	if !ok || user != f.user || password != f.password {
+		w.Header().Set("WWW-Authenticate", `Basic realm="WSMAN"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	request := string(body)
+
+	w.Header().Set("Content-Type", "application/soap+xml;charset=UTF-8")
+	switch {
+	case strings.Contains(request, "transfer/Create"):
+		fmt.Fprint(w, createShellResponse)
+	case strings.Contains(request, "shell/Command"):
+		f.lock.Lock()
+		if match := commandPattern.FindStringSubmatch(request); match != nil {
+			f.command = match[1]
+		}
+		f.lock.Unlock()
+		fmt.Fprint(w, executeCommandResponse)
+	case strings.Contains(request, "shell/Send"):
+		f.lock.Lock()
+		for _, match := range stdinPattern.FindAllStringSubmatch(request, -1) {
+			decoded, _ := base64.StdEncoding.DecodeString(match[1])
+			f.stdin.Write(decoded)
+		}
+		if stdinEndMarker.MatchString(request) {
+			close(f.stdinDone)
+		}
+		f.lock.Unlock()
+		fmt.Fprint(w, emptyResponse)
+	case strings.Contains(request, "shell/Receive"):
+		f.receive(w)
+	case strings.Contains(request, "shell/Signal"):
+		f.lock.Lock()
+		f.signalled = true
+		f.lock.Unlock()
+		fmt.Fprint(w, emptyResponse)
+	case strings.Contains(request, "transfer/Delete"):
+		f.lock.Lock()
+		f.closed = true
+		f.lock.Unlock()
+		fmt.Fprint(w, emptyResponse)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+// receive waits for the whole stdin like a script which reads it, a command without output is reported as an operation timeout
+func (f *fakeServer) receive(w http.ResponseWriter) {
+	finished := false
+	select {
+	case <-f.stdinDone:
+		finished = !f.hang
+	case <-time.After(operationTimeout):
+	}
+
+	if !finished {
+		if f.hang {
+			time.Sleep(operationTimeout)
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, operationTimeoutResponse)
+		return
+	}
+
+	stdout, stderr, exitCode := f.script(f.getStdin())
+	fmt.Fprintf(w, receiveResponse, base64.StdEncoding.EncodeToString([]byte(stdout)), base64.StdEncoding.EncodeToString([]byte(stderr)), exitCode)
+}
+
+func (f *fakeServer) getCommand() string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.command
+}
+
+func (f *fakeServer) getStdin() string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.stdin.String()
+}
+
+func (f *fakeServer) isSignalled() bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.signalled
+}
+
+func (f *fakeServer) isClosed() bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.closed
+}
+
+func startFakeServer(f *fakeServer, https bool) *httptest.Server {
+	if https {
+		return httptest.NewTLSServer(f)
+	}
+	return httptest.NewServer(f)
+}
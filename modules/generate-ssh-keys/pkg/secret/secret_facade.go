@@ -88,8 +88,13 @@ func (s *SecretFacade) CreatePrivateKeySecret() (*corev1.Secret, error) {
 		secret.GenerateName = constants.PrivateKeyGenerateName
 	}
 
+	secret.Namespace = s.clioptions.GetPrivateKeySecretNamespace()
+	if err := s.clioptions.Options.Apply(secret); err != nil {
+		return nil, err
+	}
+
 	log.Logger().Debug("creating private key secret")
-	return s.kubeClient.CoreV1().Secrets(s.clioptions.GetPrivateKeySecretNamespace()).Create(context.TODO(), secret, v1.CreateOptions{})
+	return s.kubeClient.CoreV1().Secrets(secret.Namespace).Create(context.TODO(), secret, v1.CreateOptions{})
 
 }
 
@@ -138,8 +143,13 @@ func (s *SecretFacade) CreatePublicKeySecret() (*corev1.Secret, error) {
 		secret.GenerateName = constants.PublicKeyGenerateName
 	}
 
+	secret.Namespace = s.clioptions.GetPublicKeySecretNamespace()
+	if err := s.clioptions.Options.Apply(secret); err != nil {
+		return nil, err
+	}
+
 	log.Logger().Debug("creating public key secret")
-	return s.kubeClient.CoreV1().Secrets(s.clioptions.GetPublicKeySecretNamespace()).Create(context.TODO(), secret, v1.CreateOptions{})
+	return s.kubeClient.CoreV1().Secrets(secret.Namespace).Create(context.TODO(), secret, v1.CreateOptions{})
 }
 
 func (s *SecretFacade) DeleteSecret(secret *corev1.Secret) error {
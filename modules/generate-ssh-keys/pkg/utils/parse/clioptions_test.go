@@ -4,6 +4,7 @@ import (
 	"reflect"
 
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/generate-ssh-keys/pkg/utils/parse"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/ownerref"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"go.uber.org/zap/zapcore"
@@ -35,6 +36,11 @@ var _ = Describe("CLIOptions", func() {
 		Entry("invalid connection options 2", "invalid private-key connection options: no key found before \":root\"; pair should be in \"KEY:VAL\" format", &parse.CLIOptions{
 			PrivateKeyConnectionOptions: []string{":root"},
 		}),
+		Entry("invalid ttl annotation", "invalid ttl-annotation", &parse.CLIOptions{
+			PublicKeySecretNamespace:  defaultNS,
+			PrivateKeySecretNamespace: defaultNS,
+			Options:                   ownerref.Options{TTL: "1h", TTLAnnotation: "in valid"},
+		}),
 	)
 
 	DescribeTable("Parses and returns correct values", func(options *parse.CLIOptions, expectedOptions map[string]interface{}) {
@@ -26,8 +26,6 @@ const (
 
 // WaitForSuccess
 const (
-	PollInterval                 = 15 * time.Second
-	PollTimeout                  = 3600 * time.Second
-	UnusualRestartCountThreshold = 3
-	ReasonError                  = "Error"
+	PollInterval = 15 * time.Second
+	PollTimeout  = 3600 * time.Second
 )
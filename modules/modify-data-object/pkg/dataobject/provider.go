@@ -5,6 +5,7 @@ import (
 
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/modify-data-object/pkg/constants"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/modify-data-object/pkg/utils/parse"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/dvstatus"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/log"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zerrors"
 	"go.uber.org/zap"
@@ -180,6 +181,10 @@ func (d *DataObjectCreator) CreateDataObject() (*unstructured.Unstructured, erro
 		return nil, zerrors.NewSoftError("unsupported data object kind")
 	}
 
+	if err := d.cliOptions.Options.Apply(&do); err != nil {
+		return nil, err
+	}
+
 	createdDo, err := d.dataObjectProvider.CreateDo(&do, d.cliOptions.GetAllowReplace())
 	if err != nil {
 		return nil, zerrors.NewSoftError("could not create data object: %v", err.Error())
@@ -220,7 +225,7 @@ func (d *DataObjectCreator) waitForSuccessDv(namespace, name string) error {
 			return true, nil
 		}
 
-		if hasDataVolumeFailedToImport(dv) {
+		if dvstatus.HasFailedToImport(dv) {
 			return false, zerrors.NewSoftError("Import of DV failed: %v", dv)
 		}
 
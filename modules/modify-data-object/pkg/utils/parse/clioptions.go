@@ -7,6 +7,7 @@ import (
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/modify-data-object/pkg/constants"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/env"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/output"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/ownerref"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zerrors"
 	"go.uber.org/zap/zapcore"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -30,6 +31,7 @@ type CLIOptions struct {
 	AllowReplace        string            `arg:"--allow-replace,env:ALLOW_REPLACE" placeholder:"false" help:"Allow replacing an already existing data object (same combination name/namespace). Allowed values true/false (can be set by ALLOW_REPLACE env variable)."`
 	Output              output.OutputType `arg:"-o" placeholder:"FORMAT" help:"Output format. One of: yaml|json"`
 	Debug               bool              `arg:"--debug" help:"Sets DEBUG log level"`
+	ownerref.Options
 
 	unstructuredDataObject unstructured.Unstructured
 }
@@ -90,6 +92,10 @@ func (c *CLIOptions) Init() error {
 		return err
 	}
 
+	if err := c.Options.Init(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
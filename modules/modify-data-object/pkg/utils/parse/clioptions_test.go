@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/modify-data-object/pkg/utils/parse"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/ownerref"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/sharedtest/testobjects/datasource"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/sharedtest/testobjects/datavolume"
 	. "github.com/onsi/ginkgo/v2"
@@ -41,6 +42,12 @@ var _ = Describe("CLIOptions", func() {
 					DataObjectManifest: testDvManifest1,
 					Output:             "non-existing",
 				}),
+			Entry("invalid owner kind", "Pod is not a valid owner-kind",
+				&parse.CLIOptions{
+					DataObjectManifest:  testDvManifest1,
+					DataObjectNamespace: testStrDataObjectNamespace1,
+					Options:             ownerref.Options{OwnerKind: "Pod"},
+				}),
 		)
 	})
 
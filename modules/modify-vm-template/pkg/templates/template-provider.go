@@ -6,8 +6,8 @@ import (
 
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/modify-vm-template/pkg/utils/parse"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/log"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/overrides"
 	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zutils"
-	k8sv1 "k8s.io/api/core/v1"
 
 	templatev1 "github.com/openshift/api/template/v1"
 	v1 "github.com/openshift/api/template/v1"
@@ -142,85 +142,26 @@ func appendToMap(a, b map[string]string) map[string]string {
 }
 
 func (t *TemplateUpdator) setValuesToVM(vm *kubevirtv1.VirtualMachine) *kubevirtv1.VirtualMachine {
-	labels := t.cliOptions.GetVMLabels()
-	annotations := t.cliOptions.GetVMAnnotations()
-
-	vm.Labels = appendToMap(vm.Labels, labels)
-
-	vm.Annotations = appendToMap(vm.Annotations, annotations)
-
 	if vm.Spec.Template.Spec.Domain.CPU == nil {
 		vm.Spec.Template.Spec.Domain.CPU = &kubevirtv1.CPU{}
 	}
 
-	if sockets := t.cliOptions.GetCPUSockets(); sockets > 0 {
-		vm.Spec.Template.Spec.Domain.CPU.Sockets = sockets
-	}
-
-	if cores := t.cliOptions.GetCPUCores(); cores > 0 {
-		vm.Spec.Template.Spec.Domain.CPU.Cores = cores
-	}
-
-	if threads := t.cliOptions.GetCPUThreads(); threads > 0 {
-		vm.Spec.Template.Spec.Domain.CPU.Threads = threads
-	}
-
-	if memory := t.cliOptions.GetMemory(); memory != nil {
-		vm.Spec.Template.Spec.Domain.Resources.Requests[k8sv1.ResourceMemory] = *memory
-	}
-
-	if t.cliOptions.GetDeleteDisks() {
-		vm.Spec.Template.Spec.Domain.Devices.Disks = []kubevirtv1.Disk{}
-	}
-
-	if t.cliOptions.GetDeleteVolumes() {
-		vm.Spec.Template.Spec.Volumes = []kubevirtv1.Volume{}
-	}
-
-	if t.cliOptions.GetDeleteDatavolumeTemplate() {
-		deleteDatavolumeTemplateFromVM(vm)
-	}
-
-	for _, datavolumeTemplate := range t.cliOptions.GetDatavolumeTemplates() {
-		replaced := false
-		for i, vmDatavolumeTemplate := range vm.Spec.DataVolumeTemplates {
-			if datavolumeTemplate.Name == vmDatavolumeTemplate.Name {
-				vm.Spec.DataVolumeTemplates[i] = datavolumeTemplate
-				replaced = true
-			}
-		}
-		if !replaced {
-			vm.Spec.DataVolumeTemplates = append(vm.Spec.DataVolumeTemplates, datavolumeTemplate)
-		}
-	}
-
-	for _, disk := range t.cliOptions.GetDisks() {
-		replaced := false
-		for i, vmDisk := range vm.Spec.Template.Spec.Domain.Devices.Disks {
-			if disk.Name == vmDisk.Name {
-				vm.Spec.Template.Spec.Domain.Devices.Disks[i] = disk
-				replaced = true
-			}
-		}
-		if !replaced {
-			vm.Spec.Template.Spec.Domain.Devices.Disks = append(vm.Spec.Template.Spec.Domain.Devices.Disks, disk)
-		}
+	vmOverrides := &overrides.VMOverrides{
+		Labels:                    t.cliOptions.GetVMLabels(),
+		Annotations:               t.cliOptions.GetVMAnnotations(),
+		CPUSockets:                t.cliOptions.GetCPUSockets(),
+		CPUCores:                  t.cliOptions.GetCPUCores(),
+		CPUThreads:                t.cliOptions.GetCPUThreads(),
+		MemoryRequest:             t.cliOptions.GetMemory(),
+		DeleteDisks:               t.cliOptions.GetDeleteDisks(),
+		DeleteVolumes:             t.cliOptions.GetDeleteVolumes(),
+		DeleteDataVolumeTemplates: t.cliOptions.GetDeleteDatavolumeTemplate(),
+		DataVolumeTemplates:       t.cliOptions.GetDatavolumeTemplates(),
+		Disks:                     t.cliOptions.GetDisks(),
+		Volumes:                   t.cliOptions.GetVolumes(),
 	}
 
-	for _, volume := range t.cliOptions.GetVolumes() {
-		replaced := false
-		for i, vmVolume := range vm.Spec.Template.Spec.Volumes {
-			if volume.Name == vmVolume.Name {
-				vm.Spec.Template.Spec.Volumes[i] = volume
-				replaced = true
-			}
-		}
-		if !replaced {
-			vm.Spec.Template.Spec.Volumes = append(vm.Spec.Template.Spec.Volumes, volume)
-		}
-	}
-
-	return vm
+	return vmOverrides.SetValuesToVM(vm)
 }
 
 func EncodeVMToTemplate(template *templatev1.Template, vm *kubevirtv1.VirtualMachine, vmIndex int) (*v1.Template, error) {
@@ -232,35 +173,3 @@ func EncodeVMToTemplate(template *templatev1.Template, vm *kubevirtv1.VirtualMac
 	template.Objects[vmIndex].Raw = raw
 	return template, nil
 }
-
-func deleteDatavolumeTemplateFromVM(vm *kubevirtv1.VirtualMachine) {
-	dvsToDelete := make(map[string]bool)
-	for _, dvTemplate := range vm.Spec.DataVolumeTemplates {
-		dvsToDelete[dvTemplate.Name] = true
-	}
-
-	if vm.Spec.Template != nil {
-		disksToDelete := make(map[string]bool)
-		newVolumes := []kubevirtv1.Volume{}
-		for _, volume := range vm.Spec.Template.Spec.Volumes {
-			if volume.DataVolume != nil {
-				if val, ok := dvsToDelete[volume.DataVolume.Name]; ok && val {
-					disksToDelete[volume.Name] = true
-					continue
-				}
-			}
-			newVolumes = append(newVolumes, volume)
-		}
-		vm.Spec.Template.Spec.Volumes = newVolumes
-
-		newDisks := []kubevirtv1.Disk{}
-		for _, disk := range vm.Spec.Template.Spec.Domain.Devices.Disks {
-			if _, ok := disksToDelete[disk.Name]; !ok {
-				newDisks = append(newDisks, disk)
-			}
-		}
-		vm.Spec.Template.Spec.Domain.Devices.Disks = newDisks
-	}
-
-	vm.Spec.DataVolumeTemplates = []kubevirtv1.DataVolumeTemplateSpec{}
-}
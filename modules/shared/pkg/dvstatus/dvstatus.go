@@ -0,0 +1,29 @@
+package dvstatus
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	cdiv1beta1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+)
+
+const (
+	UnusualRestartCountThreshold = 3
+	ReasonError                  = "Error"
+)
+
+// HasFailedToImport returns true if the importer of the data volume keeps restarting with an error
+func HasFailedToImport(dv *cdiv1beta1.DataVolume) bool {
+	conditions := getConditionMap(dv)
+	return dv.Status.Phase == cdiv1beta1.ImportInProgress &&
+		dv.Status.RestartCount > UnusualRestartCountThreshold &&
+		conditions[cdiv1beta1.DataVolumeBound].Status == corev1.ConditionTrue &&
+		conditions[cdiv1beta1.DataVolumeRunning].Status == corev1.ConditionFalse &&
+		conditions[cdiv1beta1.DataVolumeRunning].Reason == ReasonError
+}
+
+func getConditionMap(dv *cdiv1beta1.DataVolume) map[cdiv1beta1.DataVolumeConditionType]cdiv1beta1.DataVolumeCondition {
+	result := map[cdiv1beta1.DataVolumeConditionType]cdiv1beta1.DataVolumeCondition{}
+	for _, cond := range dv.Status.Conditions {
+		result[cond.Type] = cond
+	}
+	return result
+}
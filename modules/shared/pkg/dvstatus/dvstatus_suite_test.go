@@ -0,0 +1,13 @@
+package dvstatus_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestDvstatus(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Dvstatus Suite")
+}
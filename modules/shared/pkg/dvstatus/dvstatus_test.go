@@ -0,0 +1,35 @@
+package dvstatus_test
+
+import (
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/dvstatus"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	cdiv1beta1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+)
+
+func newDataVolume(phase cdiv1beta1.DataVolumePhase, restartCount int32, bound, running corev1.ConditionStatus, runningReason string) *cdiv1beta1.DataVolume {
+	return &cdiv1beta1.DataVolume{
+		Status: cdiv1beta1.DataVolumeStatus{
+			Phase:        phase,
+			RestartCount: restartCount,
+			Conditions: []cdiv1beta1.DataVolumeCondition{
+				{Type: cdiv1beta1.DataVolumeBound, Status: bound},
+				{Type: cdiv1beta1.DataVolumeRunning, Status: running, Reason: runningReason},
+			},
+		},
+	}
+}
+
+var _ = Describe("DataVolume status", func() {
+	DescribeTable("HasFailedToImport", func(dv *cdiv1beta1.DataVolume, expected bool) {
+		Expect(dvstatus.HasFailedToImport(dv)).To(Equal(expected))
+	},
+		Entry("restarting with error", newDataVolume(cdiv1beta1.ImportInProgress, 4, corev1.ConditionTrue, corev1.ConditionFalse, "Error"), true),
+		Entry("importing", newDataVolume(cdiv1beta1.ImportInProgress, 1, corev1.ConditionTrue, corev1.ConditionTrue, "Pod is running"), false),
+		Entry("few restarts", newDataVolume(cdiv1beta1.ImportInProgress, 3, corev1.ConditionTrue, corev1.ConditionFalse, "Error"), false),
+		Entry("not bound", newDataVolume(cdiv1beta1.ImportInProgress, 4, corev1.ConditionFalse, corev1.ConditionFalse, "Error"), false),
+		Entry("restarting without error", newDataVolume(cdiv1beta1.ImportInProgress, 4, corev1.ConditionTrue, corev1.ConditionFalse, "Completed"), false),
+		Entry("succeeded", newDataVolume(cdiv1beta1.Succeeded, 0, corev1.ConditionTrue, corev1.ConditionFalse, "Completed"), false),
+	)
+})
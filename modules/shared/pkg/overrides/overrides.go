@@ -0,0 +1,194 @@
+package overrides
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+// VMOverrides are values set to a VirtualMachine by tasks which create or modify VMs.
+// Zero values are ignored.
+type VMOverrides struct {
+	Labels      map[string]string
+	Annotations map[string]string
+
+	CPUSockets    uint32
+	CPUCores      uint32
+	CPUThreads    uint32
+	MemoryRequest *resource.Quantity
+	MemoryLimit   *resource.Quantity
+
+	// node selector labels are merged, tolerations replace tolerations of the VM
+	NodeSelector      map[string]string
+	Tolerations       []corev1.Toleration
+	Affinity          *corev1.Affinity
+	EvictionStrategy  *kubevirtv1.EvictionStrategy
+	PriorityClassName string
+
+	// objects with the same name are replaced, others are appended
+	DeleteDisks               bool
+	DeleteVolumes             bool
+	DeleteDataVolumeTemplates bool
+	DataVolumeTemplates       []kubevirtv1.DataVolumeTemplateSpec
+	Disks                     []kubevirtv1.Disk
+	Volumes                   []kubevirtv1.Volume
+}
+
+// SetValuesToVM applies the overrides to the VM
+func (o *VMOverrides) SetValuesToVM(vm *kubevirtv1.VirtualMachine) *kubevirtv1.VirtualMachine {
+	vm.Labels = appendToMap(vm.Labels, o.Labels)
+	vm.Annotations = appendToMap(vm.Annotations, o.Annotations)
+
+	if vm.Spec.Template == nil {
+		vm.Spec.Template = &kubevirtv1.VirtualMachineInstanceTemplateSpec{}
+	}
+	spec := &vm.Spec.Template.Spec
+
+	if o.CPUSockets > 0 || o.CPUCores > 0 || o.CPUThreads > 0 {
+		if spec.Domain.CPU == nil {
+			spec.Domain.CPU = &kubevirtv1.CPU{}
+		}
+
+		if o.CPUSockets > 0 {
+			spec.Domain.CPU.Sockets = o.CPUSockets
+		}
+
+		if o.CPUCores > 0 {
+			spec.Domain.CPU.Cores = o.CPUCores
+		}
+
+		if o.CPUThreads > 0 {
+			spec.Domain.CPU.Threads = o.CPUThreads
+		}
+	}
+
+	if o.MemoryRequest != nil {
+		if spec.Domain.Resources.Requests == nil {
+			spec.Domain.Resources.Requests = corev1.ResourceList{}
+		}
+		spec.Domain.Resources.Requests[corev1.ResourceMemory] = *o.MemoryRequest
+	}
+
+	if o.MemoryLimit != nil {
+		if spec.Domain.Resources.Limits == nil {
+			spec.Domain.Resources.Limits = corev1.ResourceList{}
+		}
+		spec.Domain.Resources.Limits[corev1.ResourceMemory] = *o.MemoryLimit
+	}
+
+	spec.NodeSelector = appendToMap(spec.NodeSelector, o.NodeSelector)
+
+	if len(o.Tolerations) > 0 {
+		spec.Tolerations = o.Tolerations
+	}
+
+	if o.Affinity != nil {
+		spec.Affinity = o.Affinity
+	}
+
+	if o.EvictionStrategy != nil {
+		spec.EvictionStrategy = o.EvictionStrategy
+	}
+
+	if o.PriorityClassName != "" {
+		spec.PriorityClassName = o.PriorityClassName
+	}
+
+	if o.DeleteDisks {
+		spec.Domain.Devices.Disks = []kubevirtv1.Disk{}
+	}
+
+	if o.DeleteVolumes {
+		spec.Volumes = []kubevirtv1.Volume{}
+	}
+
+	if o.DeleteDataVolumeTemplates {
+		deleteDataVolumeTemplates(vm)
+	}
+
+	for _, dataVolumeTemplate := range o.DataVolumeTemplates {
+		replaced := false
+		for i, vmDataVolumeTemplate := range vm.Spec.DataVolumeTemplates {
+			if dataVolumeTemplate.Name == vmDataVolumeTemplate.Name {
+				vm.Spec.DataVolumeTemplates[i] = dataVolumeTemplate
+				replaced = true
+			}
+		}
+		if !replaced {
+			vm.Spec.DataVolumeTemplates = append(vm.Spec.DataVolumeTemplates, dataVolumeTemplate)
+		}
+	}
+
+	for _, disk := range o.Disks {
+		replaced := false
+		for i, vmDisk := range spec.Domain.Devices.Disks {
+			if disk.Name == vmDisk.Name {
+				spec.Domain.Devices.Disks[i] = disk
+				replaced = true
+			}
+		}
+		if !replaced {
+			spec.Domain.Devices.Disks = append(spec.Domain.Devices.Disks, disk)
+		}
+	}
+
+	for _, volume := range o.Volumes {
+		replaced := false
+		for i, vmVolume := range spec.Volumes {
+			if volume.Name == vmVolume.Name {
+				spec.Volumes[i] = volume
+				replaced = true
+			}
+		}
+		if !replaced {
+			spec.Volumes = append(spec.Volumes, volume)
+		}
+	}
+
+	return vm
+}
+
+func appendToMap(a, b map[string]string) map[string]string {
+	lenB := len(b)
+	if a == nil && lenB > 0 {
+		a = make(map[string]string, lenB)
+	}
+
+	for key, value := range b {
+		a[key] = value
+	}
+	return a
+}
+
+// deleteDataVolumeTemplates deletes data volume templates together with volumes and disks which use them
+func deleteDataVolumeTemplates(vm *kubevirtv1.VirtualMachine) {
+	dvsToDelete := make(map[string]bool)
+	for _, dvTemplate := range vm.Spec.DataVolumeTemplates {
+		dvsToDelete[dvTemplate.Name] = true
+	}
+
+	if vm.Spec.Template != nil {
+		disksToDelete := make(map[string]bool)
+		newVolumes := []kubevirtv1.Volume{}
+		for _, volume := range vm.Spec.Template.Spec.Volumes {
+			if volume.DataVolume != nil {
+				if val, ok := dvsToDelete[volume.DataVolume.Name]; ok && val {
+					disksToDelete[volume.Name] = true
+					continue
+				}
+			}
+			newVolumes = append(newVolumes, volume)
+		}
+		vm.Spec.Template.Spec.Volumes = newVolumes
+
+		newDisks := []kubevirtv1.Disk{}
+		for _, disk := range vm.Spec.Template.Spec.Domain.Devices.Disks {
+			if _, ok := disksToDelete[disk.Name]; !ok {
+				newDisks = append(newDisks, disk)
+			}
+		}
+		vm.Spec.Template.Spec.Domain.Devices.Disks = newDisks
+	}
+
+	vm.Spec.DataVolumeTemplates = []kubevirtv1.DataVolumeTemplateSpec{}
+}
@@ -0,0 +1,103 @@
+package overrides_test
+
+import (
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/overrides"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+func newVM() *kubevirtv1.VirtualMachine {
+	return &kubevirtv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "my-vm",
+			Labels: map[string]string{"app": "my-vm"},
+		},
+		Spec: kubevirtv1.VirtualMachineSpec{
+			DataVolumeTemplates: []kubevirtv1.DataVolumeTemplateSpec{
+				{ObjectMeta: metav1.ObjectMeta{Name: "my-vm-rootdisk"}},
+			},
+			Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+				Spec: kubevirtv1.VirtualMachineInstanceSpec{
+					NodeSelector: map[string]string{"kubernetes.io/arch": "amd64"},
+					Tolerations:  []corev1.Toleration{{Key: "old", Operator: corev1.TolerationOpExists}},
+					Domain: kubevirtv1.DomainSpec{
+						Devices: kubevirtv1.Devices{
+							Disks: []kubevirtv1.Disk{{Name: "rootdisk"}, {Name: "cloudinitdisk"}},
+						},
+					},
+					Volumes: []kubevirtv1.Volume{
+						{Name: "rootdisk", VolumeSource: kubevirtv1.VolumeSource{DataVolume: &kubevirtv1.DataVolumeSource{Name: "my-vm-rootdisk"}}},
+						{Name: "cloudinitdisk", VolumeSource: kubevirtv1.VolumeSource{CloudInitNoCloud: &kubevirtv1.CloudInitNoCloudSource{}}},
+					},
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("VMOverrides", func() {
+	It("does not modify the VM without overrides", func() {
+		vm := newVM()
+		Expect((&overrides.VMOverrides{}).SetValuesToVM(vm)).To(Equal(newVM()))
+	})
+
+	It("sets scheduling and resources", func() {
+		memoryRequest := resource.MustParse("2Gi")
+		memoryLimit := resource.MustParse("4Gi")
+		evictionStrategy := kubevirtv1.EvictionStrategyLiveMigrate
+		affinity := &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{}}
+		tolerations := []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "vms", Effect: corev1.TaintEffectNoSchedule}}
+
+		vm := (&overrides.VMOverrides{
+			Labels:            map[string]string{"tier": "test"},
+			CPUSockets:        2,
+			CPUCores:          4,
+			MemoryRequest:     &memoryRequest,
+			MemoryLimit:       &memoryLimit,
+			NodeSelector:      map[string]string{"node-role.kubernetes.io/worker": ""},
+			Tolerations:       tolerations,
+			Affinity:          affinity,
+			EvictionStrategy:  &evictionStrategy,
+			PriorityClassName: "high",
+		}).SetValuesToVM(newVM())
+
+		spec := vm.Spec.Template.Spec
+		Expect(vm.Labels).To(Equal(map[string]string{"app": "my-vm", "tier": "test"}))
+		Expect(spec.Domain.CPU).To(Equal(&kubevirtv1.CPU{Sockets: 2, Cores: 4}))
+		Expect(spec.Domain.Resources.Requests).To(Equal(corev1.ResourceList{corev1.ResourceMemory: memoryRequest}))
+		Expect(spec.Domain.Resources.Limits).To(Equal(corev1.ResourceList{corev1.ResourceMemory: memoryLimit}))
+		Expect(spec.NodeSelector).To(Equal(map[string]string{"kubernetes.io/arch": "amd64", "node-role.kubernetes.io/worker": ""}))
+		Expect(spec.Tolerations).To(Equal(tolerations))
+		Expect(spec.Affinity).To(Equal(affinity))
+		Expect(*spec.EvictionStrategy).To(Equal(kubevirtv1.EvictionStrategyLiveMigrate))
+		Expect(spec.PriorityClassName).To(Equal("high"))
+	})
+
+	It("replaces and appends disks and volumes", func() {
+		vm := (&overrides.VMOverrides{
+			Disks:   []kubevirtv1.Disk{{Name: "cloudinitdisk", DiskDevice: kubevirtv1.DiskDevice{CDRom: &kubevirtv1.CDRomTarget{}}}, {Name: "data"}},
+			Volumes: []kubevirtv1.Volume{{Name: "data"}},
+		}).SetValuesToVM(newVM())
+
+		spec := vm.Spec.Template.Spec
+		Expect(spec.Domain.Devices.Disks).To(HaveLen(3))
+		Expect(spec.Domain.Devices.Disks[1].CDRom).ToNot(BeNil())
+		Expect(spec.Domain.Devices.Disks[2].Name).To(Equal("data"))
+		Expect(spec.Volumes).To(HaveLen(3))
+	})
+
+	It("deletes data volume templates with their volumes and disks", func() {
+		vm := (&overrides.VMOverrides{
+			DeleteDataVolumeTemplates: true,
+		}).SetValuesToVM(newVM())
+
+		Expect(vm.Spec.DataVolumeTemplates).To(BeEmpty())
+		Expect(vm.Spec.Template.Spec.Domain.Devices.Disks).To(Equal([]kubevirtv1.Disk{{Name: "cloudinitdisk"}}))
+		Expect(vm.Spec.Template.Spec.Volumes).To(HaveLen(1))
+		Expect(vm.Spec.Template.Spec.Volumes[0].Name).To(Equal("cloudinitdisk"))
+	})
+})
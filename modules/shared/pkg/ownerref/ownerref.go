@@ -0,0 +1,202 @@
+package ownerref
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/env"
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/zerrors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	TaskRunKind     = "TaskRun"
+	PipelineRunKind = "PipelineRun"
+
+	DefaultTTLAnnotation = "janitor/ttl"
+
+	tektonAPIVersion = "tekton.dev/v1beta1"
+
+	taskRunLabel        = "tekton.dev/taskRun"
+	taskRunUIDLabel     = "tekton.dev/taskRunUID"
+	pipelineRunLabel    = "tekton.dev/pipelineRun"
+	pipelineRunUIDLabel = "tekton.dev/pipelineRunUID"
+
+	// exposed by the downward API
+	taskRunNameEnv     = "TASKRUN_NAME"
+	taskRunUIDEnv      = "TASKRUN_UID"
+	pipelineRunNameEnv = "PIPELINERUN_NAME"
+	pipelineRunUIDEnv  = "PIPELINERUN_UID"
+	podLabelsPath      = "/etc/podinfo/labels"
+
+	ownerKindOptionName     = "owner-kind"
+	ttlOptionName           = "ttl"
+	ttlAnnotationOptionName = "ttl-annotation"
+)
+
+// Options tie objects created by a task to the TaskRun or PipelineRun the task runs in,
+// so they can be garbage collected together with the run or by a TTL based janitor.
+// Options should be embedded in CLIOptions of a task.
+type Options struct {
+	OwnerKind     string `arg:"--owner-kind,env:OWNER_KIND" placeholder:"KIND" help:"Set an owner reference to created objects pointing to the TaskRun or PipelineRun of this task. One of: TaskRun|PipelineRun. Objects have to be created in the namespace of the run."`
+	TTL           string `arg:"--ttl,env:TTL" placeholder:"TTL" help:"Annotate created objects with a time to live. Should be in a 3h2m1s format."`
+	TTLAnnotation string `arg:"--ttl-annotation,env:TTL_ANNOTATION" placeholder:"ANNOTATION" help:"Annotation to store the time to live in (defaults to janitor/ttl)."`
+
+	ownerReference *metav1.OwnerReference `arg:"-"`
+	ownerNamespace string                 `arg:"-"`
+}
+
+func (o *Options) GetOwnerKind() string {
+	return o.OwnerKind
+}
+
+func (o *Options) GetTTL() string {
+	return o.TTL
+}
+
+func (o *Options) GetTTLAnnotation() string {
+	if o.TTLAnnotation == "" {
+		return DefaultTTLAnnotation
+	}
+	return o.TTLAnnotation
+}
+
+// GetOwnerReference returns the resolved owner reference or nil. Init has to be called first.
+func (o *Options) GetOwnerReference() *metav1.OwnerReference {
+	return o.ownerReference
+}
+
+// Init validates the options and looks up the owner in the pod labels
+func (o *Options) Init() error {
+	o.OwnerKind = strings.TrimSpace(o.OwnerKind)
+	o.TTL = strings.TrimSpace(o.TTL)
+	o.TTLAnnotation = strings.TrimSpace(o.TTLAnnotation)
+
+	if o.TTL != "" {
+		if ttl, err := time.ParseDuration(o.TTL); err != nil {
+			return zerrors.NewMissingRequiredError("could not parse %v: %v", ttlOptionName, err.Error())
+		} else if ttl <= 0 {
+			return zerrors.NewMissingRequiredError("%v should be positive", ttlOptionName)
+		}
+	} else if o.TTLAnnotation != "" {
+		return zerrors.NewMissingRequiredError("%v option is applicable only for %v", ttlAnnotationOptionName, ttlOptionName)
+	}
+
+	if errs := validation.IsQualifiedName(o.GetTTLAnnotation()); len(errs) > 0 {
+		return zerrors.NewMissingRequiredError("invalid %v: %v", ttlAnnotationOptionName, strings.Join(errs, ", "))
+	}
+
+	if o.OwnerKind == "" {
+		return nil
+	}
+
+	var nameLabel, uidLabel string
+	switch o.OwnerKind {
+	case TaskRunKind:
+		nameLabel, uidLabel = taskRunLabel, taskRunUIDLabel
+	case PipelineRunKind:
+		nameLabel, uidLabel = pipelineRunLabel, pipelineRunUIDLabel
+	default:
+		return zerrors.NewMissingRequiredError("%v is not a valid %v, only %v|%v is allowed", o.OwnerKind, ownerKindOptionName, TaskRunKind, PipelineRunKind)
+	}
+
+	labels := getPodLabels()
+	name, uid := labels[nameLabel], labels[uidLabel]
+	if name == "" || uid == "" {
+		return zerrors.NewMissingRequiredError("could not find %v owning this pod: %v and %v labels are not available", o.OwnerKind, nameLabel, uidLabel)
+	}
+
+	namespace, err := env.GetActiveNamespace()
+	if err != nil {
+		return zerrors.NewMissingRequiredError("%v: could not resolve namespace of %v %v", err.Error(), o.OwnerKind, name)
+	}
+
+	o.ownerNamespace = namespace
+	o.ownerReference = &metav1.OwnerReference{
+		APIVersion: tektonAPIVersion,
+		Kind:       o.OwnerKind,
+		Name:       name,
+		UID:        types.UID(uid),
+	}
+
+	return nil
+}
+
+// Apply sets the owner reference and the TTL annotation to the object.
+// The object namespace has to be set when an owner reference is requested.
+func (o *Options) Apply(obj metav1.Object) error {
+	if o.ownerReference != nil {
+		// cross namespace owner references are not allowed and would cause deletion of the object
+		if obj.GetNamespace() != o.ownerNamespace {
+			return zerrors.NewMissingRequiredError("could not set %v %v as owner of %v: objects have to be created in the %v namespace",
+				o.ownerReference.Kind, o.ownerReference.Name, obj.GetName(), o.ownerNamespace)
+		}
+
+		ownerReferences := obj.GetOwnerReferences()
+		for _, ownerReference := range ownerReferences {
+			if ownerReference.UID == o.ownerReference.UID {
+				return nil
+			}
+		}
+		obj.SetOwnerReferences(append(ownerReferences, *o.ownerReference))
+	}
+
+	if o.TTL != "" {
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[o.GetTTLAnnotation()] = o.TTL
+		obj.SetAnnotations(annotations)
+	}
+
+	return nil
+}
+
+// getPodLabels reads Tekton labels from environment variables set by the downward API
+// with a fallback to the downward API labels file
+func getPodLabels() map[string]string {
+	labels := readLabelsFile(podLabelsPath)
+
+	for label, envName := range map[string]string{
+		taskRunLabel:        taskRunNameEnv,
+		taskRunUIDLabel:     taskRunUIDEnv,
+		pipelineRunLabel:    pipelineRunNameEnv,
+		pipelineRunUIDLabel: pipelineRunUIDEnv,
+	} {
+		if value := os.Getenv(envName); value != "" {
+			labels[label] = value
+		}
+	}
+
+	return labels
+}
+
+// readLabelsFile parses key="value" lines of the downward API labels file
+func readLabelsFile(path string) map[string]string {
+	labels := map[string]string{}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return labels
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		keyValue := strings.SplitN(scanner.Text(), "=", 2)
+		if len(keyValue) != 2 {
+			continue
+		}
+		if value, err := strconv.Unquote(keyValue[1]); err == nil {
+			labels[keyValue[0]] = value
+		}
+	}
+
+	return labels
+}
@@ -0,0 +1,13 @@
+package ownerref_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestOwnerref(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Ownerref Suite")
+}
@@ -0,0 +1,64 @@
+package ownerref_test
+
+import (
+	"github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/ownerref"
+	. "github.com/kubevirt/kubevirt-tekton-tasks/modules/shared/pkg/utilstest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Ownerref", func() {
+	AfterEach(func() {
+		UnSetEnv("TASKRUN_NAME")
+		UnSetEnv("TASKRUN_UID")
+	})
+
+	DescribeTable("Init fails on invalid options", func(options *ownerref.Options, expectedErrMessage string) {
+		err := options.Init()
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(expectedErrMessage))
+	},
+		Entry("invalid ttl", &ownerref.Options{TTL: "1 day"}, "could not parse ttl"),
+		Entry("negative ttl", &ownerref.Options{TTL: "-1h"}, "ttl should be positive"),
+		Entry("ttl annotation without ttl", &ownerref.Options{TTLAnnotation: "cleanup/ttl"}, "ttl-annotation option is applicable only for ttl"),
+		Entry("invalid ttl annotation", &ownerref.Options{TTL: "1h", TTLAnnotation: "cleanup/ttl/invalid"}, "invalid ttl-annotation"),
+		Entry("invalid owner kind", &ownerref.Options{OwnerKind: "Pod"}, "Pod is not a valid owner-kind, only TaskRun|PipelineRun is allowed"),
+		Entry("missing owner labels", &ownerref.Options{OwnerKind: "PipelineRun"}, "could not find PipelineRun owning this pod"),
+	)
+
+	It("Init finds the owner name but requires the active namespace", func() {
+		SetEnv("TASKRUN_NAME", "my-taskrun")
+		SetEnv("TASKRUN_UID", "2f9e7fa6-4ea2-4a2b-a1f3-1a1a2b3c4d5e")
+		options := &ownerref.Options{OwnerKind: "TaskRun"}
+		err := options.Init()
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("could not resolve namespace of TaskRun my-taskrun"))
+		Expect(options.GetOwnerReference()).To(BeNil())
+	})
+
+	DescribeTable("Apply sets the ttl annotation", func(options *ownerref.Options, expectedAnnotation string) {
+		Expect(options.Init()).To(Succeed())
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name:        "my-secret",
+			Annotations: map[string]string{"existing": "value"},
+		}}
+		Expect(options.Apply(secret)).To(Succeed())
+		Expect(secret.Annotations).To(HaveKeyWithValue(expectedAnnotation, "2h30m"))
+		Expect(secret.Annotations).To(HaveKeyWithValue("existing", "value"))
+		Expect(secret.OwnerReferences).To(BeEmpty())
+	},
+		Entry("default annotation", &ownerref.Options{TTL: "2h30m"}, "janitor/ttl"),
+		Entry("custom annotation", &ownerref.Options{TTL: "2h30m", TTLAnnotation: "cleanup.example.com/ttl"}, "cleanup.example.com/ttl"),
+	)
+
+	It("Apply does nothing without options", func() {
+		options := &ownerref.Options{}
+		Expect(options.Init()).To(Succeed())
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "my-secret"}}
+		Expect(options.Apply(secret)).To(Succeed())
+		Expect(secret.Annotations).To(BeNil())
+		Expect(secret.OwnerReferences).To(BeNil())
+	})
+})
@@ -5,7 +5,7 @@ import (
 )
 
 const (
-	// ConnectionSecretTypeKey supports the following values: ssh
+	// ConnectionSecretTypeKey supports the following values: ssh, serial-console, winrm
 	ConnectionSecretTypeKey = "type"
 )
 
@@ -16,6 +16,7 @@ type sshConnectionSecretKeys struct {
 	HostPublicKey                string
 	DisableStrictHostKeyChecking string
 	AdditionalSSHOptions         string
+	SSHClient                    string
 }
 
 var SSHConnectionSecretKeys = sshConnectionSecretKeys{
@@ -25,4 +26,43 @@ var SSHConnectionSecretKeys = sshConnectionSecretKeys{
 	HostPublicKey:                "host-public-key",
 	DisableStrictHostKeyChecking: "disable-strict-host-key-checking",
 	AdditionalSSHOptions:         "additional-ssh-options",
+	SSHClient:                    "ssh-client",
+}
+
+type serialConsoleConnectionSecretKeys struct {
+	User     string
+	Password string
+	Prompt   string
+}
+
+var SerialConsoleConnectionSecretKeys = serialConsoleConnectionSecretKeys{
+	User:     "user",
+	Password: "password",
+	Prompt:   "prompt",
+}
+
+type winRMConnectionSecretKeys struct {
+	User               string
+	Password           string
+	ClientCert         string
+	ClientKey          string
+	CACert             string
+	Protocol           string
+	Port               string
+	InsecureSkipVerify string
+	TLSServerName      string
+	Shell              string
+}
+
+var WinRMConnectionSecretKeys = winRMConnectionSecretKeys{
+	User:               "user",
+	Password:           "password",
+	ClientCert:         corev1.TLSCertKey,
+	ClientKey:          corev1.TLSPrivateKeyKey,
+	CACert:             "ca.crt",
+	Protocol:           "protocol",
+	Port:               "port",
+	InsecureSkipVerify: "insecure-skip-verify",
+	TLSServerName:      "tls-server-name",
+	Shell:              "shell",
 }